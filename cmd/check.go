@@ -1,19 +1,46 @@
 // check.go implements the 'check' command for verifying Kubernetes
-// component version compatibility against a specified Kubernetes version.
+// component version compatibility against a specified Kubernetes version,
+// a live cluster, or a fleet of clusters.
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"sort"
-	"strings"
+	"time"
 
+	"github.com/pmady/kube-dependency-checker/pkg/checker"
 	"github.com/pmady/kube-dependency-checker/pkg/compatibility"
+	"github.com/pmady/kube-dependency-checker/pkg/componentconfig"
+	"github.com/pmady/kube-dependency-checker/pkg/flags"
+	"github.com/pmady/kube-dependency-checker/pkg/fleet"
+	"github.com/pmady/kube-dependency-checker/pkg/images"
+	"github.com/pmady/kube-dependency-checker/pkg/inventory"
+	"github.com/pmady/kube-dependency-checker/pkg/kubeadm"
+	"github.com/pmady/kube-dependency-checker/pkg/kubernetes"
 	"github.com/pmady/kube-dependency-checker/pkg/output"
+	"github.com/pmady/kube-dependency-checker/pkg/policy"
+	"github.com/pmady/kube-dependency-checker/pkg/waiver"
 	"github.com/spf13/cobra"
 )
 
 var (
 	k8sVersion string
+
+	// Live cluster and fleet flags
+	kubeconfigPath string
+	kubeContext    string
+	kubeContexts   []string
+	allContexts    bool
+	inventoryPaths []string
+	fleetWorkers   int
+	clusterTimeout time.Duration
+	waiverPath     string
+	policyPath     string
+	failOn         string
+	imageMapPath   string
+	kubeadmConfig  string
+	flagSources    []string
+	configSources  []string
 )
 
 var checkCmd = &cobra.Command{
@@ -24,6 +51,54 @@ var checkCmd = &cobra.Command{
 This command shows the required and recommended versions for all
 components that are compatible with the specified Kubernetes version.
 
+With --kubeconfig or --context it inspects a live cluster and compares the
+running component versions with the matrix. With --contexts, --all-contexts
+or --inventory it checks a fleet of clusters concurrently and reports each
+cluster plus a fleet summary; clusters that cannot be reached are listed
+without failing the rest.
+
+Component versions are read from the images of kube-system pods, matched
+on their repository name. --image-map adds registry mirror rewrites and
+image repositories with other names. Inventory clusters may point "pods" at
+a saved "kubectl get pods -n kube-system -o yaml" to read component versions
+from it.
+
+With --kubeadm-config it reads a kubeadm ClusterConfiguration and checks the
+kubernetesVersion and the etcd, CoreDNS, kube-proxy, controller manager and
+scheduler versions it deploys, taking imageRepository and the etcd and DNS
+image tags into account. kubernetesVersion must be an exact version. A file
+with only an InitConfiguration uses kubeadm's defaults, with the version
+given by --k8s-version.
+
+With --feature-gates, the feature gates set on the components are checked
+against each result's release: gates that are removed, or locked to the
+other value, are reported as error findings because components fail to
+start with them.
+
+With --component-flags, the command line flags of kube-apiserver,
+kube-controller-manager, kube-scheduler and the kubelet are read from static
+pod manifests (a file or a directory such as /etc/kubernetes/manifests) or
+kubelet environment files (such as /var/lib/kubelet/kubeadm-flags.env).
+Flags removed in the checked release are reported as error findings and
+deprecated flags as warnings.
+
+With --component-config, KubeletConfiguration and KubeProxyConfiguration
+documents are read from configuration files or from saved ConfigMaps such
+as kube-system/kube-proxy and kube-system/kubelet-config. Their apiVersion
+and fields are checked against the release: API versions it no longer
+serves and removed fields or values are errors, deprecated fields are
+warnings and unset fields whose default changes are reported for
+information. The component configurations of a --kubeadm-config file are
+checked too.
+
+With --waivers, incompatible components accepted by an unexpired waiver are
+reported as waived; once the waiver expires they are incompatible again.
+
+With --policy, the rules of a policy file are evaluated against every result
+and their findings are reported with a severity. --fail-on makes the command
+exit with status 2 when there are incompatible components, unreachable
+clusters or findings at or above the given severity.
+
 Examples:
   # Check compatibility for Kubernetes 1.30
   kube-dependency-checker check --k8s-version 1.30
@@ -32,79 +107,345 @@ Examples:
   kube-dependency-checker check --k8s-version 1.30 -o json
 
   # Output as YAML
-  kube-dependency-checker check --k8s-version 1.30 -o yaml`,
+  kube-dependency-checker check --k8s-version 1.30 -o yaml
+
+  # Check the cluster of the current kubeconfig context
+  kube-dependency-checker check --context prod-east
+
+  # Check every kubeconfig context, 10 at a time
+  kube-dependency-checker check --all-contexts --workers 10 --timeout 20s
+
+  # Check the clusters described in an inventory file
+  kube-dependency-checker check --inventory fleet.yaml
+
+  # Recognise images pulled through an internal registry mirror
+  kube-dependency-checker check --context prod-east --image-map images.yaml
+
+  # Check the versions a kubeadm configuration deploys before running kubeadm init
+  kube-dependency-checker check --kubeadm-config kubeadm.yaml
+
+  # Check a kubeadm configuration without a ClusterConfiguration
+  kube-dependency-checker check --kubeadm-config init.yaml --k8s-version 1.31.4
+
+  # Check the feature gates set on the control plane
+  kube-dependency-checker check --k8s-version 1.31 --feature-gates PodSecurity=true,NodeSwap=true
+
+  # Find flags that stop working in 1.31 before upgrading a control plane node
+  kube-dependency-checker check --k8s-version 1.31 \
+    --component-flags /etc/kubernetes/manifests \
+    --component-flags /var/lib/kubelet/kubeadm-flags.env
+
+  # Check the kubelet configuration and the kube-proxy ConfigMap for 1.31
+  kube-dependency-checker check --k8s-version 1.31 \
+    --component-config /var/lib/kubelet/config.yaml \
+    --component-config kube-proxy-configmap.yaml
+
+  # Accept known exceptions until they expire
+  kube-dependency-checker check --inventory fleet.yaml --waivers waivers.yaml
+
+  # Enforce organisation rules and fail CI on warnings
+  kube-dependency-checker check --inventory fleet.yaml --policy policy.yaml --fail-on warning`,
 	RunE: runCheck,
 }
 
 func init() {
 	rootCmd.AddCommand(checkCmd)
 	checkCmd.Flags().StringVar(&k8sVersion, "k8s-version", "", "Kubernetes version to check (e.g., 1.30)")
-	_ = checkCmd.MarkFlagRequired("k8s-version")
+	checkCmd.Flags().StringVar(&kubeconfigPath, "kubeconfig", "", "Path to the kubeconfig file (defaults to $KUBECONFIG or ~/.kube/config)")
+	checkCmd.Flags().StringVar(&kubeContext, "context", "", "Kubeconfig context of the cluster to check")
+	checkCmd.Flags().StringSliceVar(&kubeContexts, "contexts", nil, "Comma-separated kubeconfig contexts to check")
+	checkCmd.Flags().BoolVar(&allContexts, "all-contexts", false, "Check every kubeconfig context")
+	checkCmd.Flags().StringArrayVar(&inventoryPaths, "inventory", nil, "Inventory file or directory of clusters to check (repeatable)")
+	checkCmd.Flags().IntVar(&fleetWorkers, "workers", fleet.DefaultWorkers, "Number of clusters to check in parallel")
+	checkCmd.Flags().DurationVar(&clusterTimeout, "timeout", fleet.DefaultTimeout, "Time allowed for checking each cluster")
+	checkCmd.Flags().StringVar(&waiverPath, "waivers", "", "Waiver file of accepted incompatible component versions")
+	checkCmd.Flags().StringVar(&policyPath, "policy", "", "Policy file of rules to evaluate against the results")
+	checkCmd.Flags().StringVar(&failOn, "fail-on", "never", "Exit with status 2 on problems at or above this severity (never, error, warning, info)")
+	checkCmd.Flags().StringVar(&imageMapPath, "image-map", "", "Image mapping file of registry mirrors and image repositories to components")
+	checkCmd.Flags().StringVar(&kubeadmConfig, "kubeadm-config", "", "kubeadm configuration file to check")
+	checkCmd.Flags().StringArrayVar(&flagSources, "component-flags", nil, "Static pod manifest, manifest directory or kubelet environment file to read component flags from (repeatable)")
+	checkCmd.Flags().StringArrayVar(&configSources, "component-config", nil, "KubeletConfiguration or KubeProxyConfiguration file, or ConfigMap holding one, to check (repeatable)")
+	checkCmd.Flags().StringVar(&featureGates, "feature-gates", "", "Feature gates set on the components, e.g. NodeSwap=true,SidecarContainers=false")
 }
 
 func runCheck(cmd *cobra.Command, args []string) error {
-	// Normalize version (remove 'v' prefix if present)
-	k8sVersion = strings.TrimPrefix(k8sVersion, "v")
+	formatter, err := newFormatter()
+	if err != nil {
+		return err
+	}
+
+	// The kubeconfig may come from the configuration too, but its default
+	// alone does not select a cluster
+	live := kubeContext != "" || kubeconfigPath != ""
+	fleetMode := len(kubeContexts) > 0 || allContexts || len(inventoryPaths) > 0
 
-	// Get compatibility matrix for the specified version
-	matrix, ok := compatibility.GetMatrix(k8sVersion)
-	if !ok {
-		supportedVersions := compatibility.GetSupportedVersions()
-		sort.Sort(sort.Reverse(sort.StringSlice(supportedVersions)))
-		return fmt.Errorf("unsupported Kubernetes version: %s\nSupported versions: %s",
-			k8sVersion, strings.Join(supportedVersions, ", "))
+	// A Kubernetes version from the configuration is only a default for
+	// when no cluster is selected
+	if !onCommandLine("k8s-version") && (live || fleetMode || kubeadmConfig != "") {
+		k8sVersion = ""
 	}
 
-	// Build the result
-	result := &output.CheckResult{
-		K8sVersion: k8sVersion,
-		Components: make([]output.ComponentResult, 0),
+	if failOn != "never" && policy.SeverityRank(failOn) < 0 {
+		return fmt.Errorf("invalid --fail-on %q (want never, error, warning or info)", failOn)
 	}
 
-	// Define component order for consistent output
-	componentOrder := []string{
-		"etcd",
-		"coredns",
-		"containerd",
-		"kubelet",
-		"kube-proxy",
-		"kube-controller-manager",
-		"kube-scheduler",
-		"kubectl",
+	var waivers []waiver.Waiver
+	if waiverPath != "" {
+		waivers, err = waiver.Load(waiverPath)
+		if err != nil {
+			return err
+		}
+	}
+	var rules *policy.Policy
+	if policyPath != "" {
+		rules, err = policy.Load(policyPath)
+		if err != nil {
+			return err
+		}
 	}
 
-	for _, compName := range componentOrder {
-		info, exists := matrix.Components[compName]
-		if !exists {
-			continue
+	gates, err := checker.ParseFeatureGates(featureGates)
+	if err != nil {
+		return err
+	}
+
+	usages := make([]flags.Usage, 0)
+	for _, path := range flagSources {
+		u, err := flags.Load(path)
+		if err != nil {
+			return err
 		}
+		usages = append(usages, u...)
+	}
 
-		compResult := output.ComponentResult{
-			Name:        info.Name,
-			Required:    info.Version,
-			Recommended: info.Recommended,
-			Status:      "compatible", // Default to compatible when showing requirements
-			SkewPolicy:  info.SkewPolicy,
-			Notes:       info.Notes,
+	configs := make([]componentconfig.Document, 0)
+	for _, path := range configSources {
+		docs, err := componentconfig.Load(path)
+		if err != nil {
+			return err
 		}
+		configs = append(configs, docs...)
+	}
 
-		// For skew policy components, show the policy instead of version
-		if info.SkewPolicy != "" {
-			compResult.Required = ""
+	// review applies the feature gates, component flags and configurations,
+	// waivers and policy rules to a result
+	review := func(result *output.CheckResult) error {
+		if matrix, ok := compatibility.GetMatrix(result.K8sVersion); ok {
+			result.Findings = append(result.Findings, checker.CheckFeatureGates(matrix, gates)...)
+			result.Findings = append(result.Findings, checker.CheckFlags(matrix, usages)...)
+			result.Findings = append(result.Findings, checker.CheckComponentConfigs(matrix, configs)...)
+		}
+		waiver.Apply(result, waivers, time.Now())
+		if rules == nil {
+			return nil
 		}
+		_, err := rules.Evaluate(result)
+		return err
+	}
 
-		result.Components = append(result.Components, compResult)
+	mapper := images.Default()
+	if imageMapPath != "" {
+		mapper, err = images.LoadMapper(imageMapPath)
+		if err != nil {
+			return err
+		}
 	}
 
-	// Calculate summary
-	result.Summary = output.Summary{
-		TotalComponents:      len(result.Components),
-		CompatibleComponents: len(result.Components),
-		IncompatibleCount:    0,
-		UnknownCount:         0,
+	switch {
+	case k8sVersion != "" && (live || fleetMode):
+		return fmt.Errorf("--k8s-version cannot be combined with cluster or fleet flags")
+	case kubeadmConfig != "" && (live || fleetMode):
+		return fmt.Errorf("--kubeadm-config cannot be combined with cluster or fleet flags")
+	case kubeContext != "" && fleetMode:
+		return fmt.Errorf("--context cannot be combined with --contexts, --all-contexts or --inventory")
+	case fleetMode:
+		targets, err := fleetTargets(mapper)
+		if err != nil {
+			return err
+		}
+		result := fleet.Check(cmd.Context(), targets, fleet.Options{Workers: fleetWorkers, Timeout: clusterTimeout})
+		for i := range result.Clusters {
+			if err := review(&result.Clusters[i]); err != nil {
+				return err
+			}
+		}
+		result.Summary = fleet.Summarize(result)
+		if err := formatter.FormatFleet(result); err != nil {
+			return err
+		}
+		return checkFailOn(cmd, failOn, result.Clusters, len(result.Failures))
+	case kubeadmConfig != "":
+		cfg, err := loadKubeadmConfig(kubeadmConfig, k8sVersion)
+		if err != nil {
+			return err
+		}
+		cluster, err := cfg.Cluster.Cluster(kubeadmConfig)
+		if err != nil {
+			return fmt.Errorf("%s: %w", kubeadmConfig, err)
+		}
+		cluster.Source = kubeadmConfig
+		configs = append(configs, componentconfig.FromKubeadm(cfg, kubeadmConfig)...)
+		result, err := checker.Evaluate(cluster)
+		if err != nil {
+			return err
+		}
+		if err := review(result); err != nil {
+			return err
+		}
+		if err := formatter.Format(result); err != nil {
+			return err
+		}
+		return checkFailOn(cmd, failOn, []output.CheckResult{*result}, 0)
+	case live:
+		cfg, err := loadKubeconfig(kubeconfigPath)
+		if err != nil {
+			return err
+		}
+		name := kubeContext
+		if name == "" {
+			name = cfg.CurrentContext
+		}
+		ctx, cancel := context.WithTimeout(cmd.Context(), clusterTimeout)
+		defer cancel()
+		cluster, err := inspectContext(ctx, cfg, name, mapper)
+		if err != nil {
+			return err
+		}
+		result, err := checker.Evaluate(cluster)
+		if err != nil {
+			return err
+		}
+		if err := review(result); err != nil {
+			return err
+		}
+		if err := formatter.Format(result); err != nil {
+			return err
+		}
+		return checkFailOn(cmd, failOn, []output.CheckResult{*result}, 0)
+	case k8sVersion == "":
+		return fmt.Errorf("--k8s-version is required unless checking a cluster or fleet")
+	}
+
+	result, err := checker.Check(k8sVersion)
+	if err != nil {
+		return err
+	}
+
+	if err := review(result); err != nil {
+		return err
 	}
 
 	// Output the result
-	formatter := output.NewFormatter(outputFormat)
-	return formatter.Format(result)
+	if err := formatter.Format(result); err != nil {
+		return err
+	}
+	return checkFailOn(cmd, failOn, []output.CheckResult{*result}, 0)
+}
+
+// checkFailOn returns an ExitError when the results have incompatible
+// components, unreachable clusters or policy findings at or above severity,
+// the value of a --fail-on flag
+func checkFailOn(cmd *cobra.Command, severity string, results []output.CheckResult, unreachable int) error {
+	if severity == "never" {
+		return nil
+	}
+	threshold := policy.SeverityRank(severity)
+
+	problems := unreachable
+	for _, r := range results {
+		problems += r.Summary.IncompatibleCount
+		for _, f := range r.Findings {
+			if policy.SeverityRank(f.Severity) >= threshold {
+				problems++
+			}
+		}
+	}
+	if problems == 0 {
+		return nil
+	}
+
+	// The result has been printed; only report why the command failed
+	cmd.SilenceUsage = true
+	return &ExitError{
+		Code: ExitCheckFailed,
+		Err:  fmt.Errorf("%d problems at or above severity %s", problems, severity),
+	}
+}
+
+// fleetTargets builds the fleet from inventory files and kubeconfig contexts
+func fleetTargets(mapper *images.Mapper) ([]fleet.Target, error) {
+	targets := make([]fleet.Target, 0)
+
+	for _, path := range inventoryPaths {
+		inv, err := inventory.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range inv.Clusters {
+			if err := c.ReadPods(mapper); err != nil {
+				return nil, err
+			}
+			targets = append(targets, fleet.StaticTarget(c))
+		}
+	}
+
+	if len(kubeContexts) == 0 && !allContexts {
+		return targets, nil
+	}
+
+	cfg, err := loadKubeconfig(kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+	names := kubeContexts
+	if allContexts {
+		names = cfg.ContextNames()
+	}
+	for _, name := range names {
+		name := name
+		targets = append(targets, fleet.Target{
+			Name: name,
+			Inspect: func(ctx context.Context) (*inventory.Cluster, error) {
+				return inspectContext(ctx, cfg, name, mapper)
+			},
+		})
+	}
+
+	return targets, nil
+}
+
+// loadKubeadmConfig reads a kubeadm configuration file. k8sVersion, if
+// set, is used for a file that leaves kubernetesVersion out, such as one
+// with only an InitConfiguration.
+func loadKubeadmConfig(path, k8sVersion string) (*kubeadm.Config, error) {
+	cfg, err := kubeadm.LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	if k8sVersion != "" {
+		if err := cfg.Cluster.SetVersion(k8sVersion); err != nil {
+			return nil, fmt.Errorf("%s: %w, remove --k8s-version", path, err)
+		}
+	}
+	if cfg.Cluster.KubernetesVersion == "" {
+		return nil, fmt.Errorf("%s: kubernetesVersion is not set, add a ClusterConfiguration or use --k8s-version", path)
+	}
+	return cfg, nil
+}
+
+// loadKubeconfig reads a kubeconfig file, or the default ones when path is
+// empty
+func loadKubeconfig(path string) (*kubernetes.Config, error) {
+	if path != "" {
+		return kubernetes.LoadConfig(path)
+	}
+	return kubernetes.LoadConfig()
+}
+
+func inspectContext(ctx context.Context, cfg *kubernetes.Config, name string, mapper *images.Mapper) (*inventory.Cluster, error) {
+	client, err := kubernetes.NewClient(ctx, cfg, name)
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes.Inspect(ctx, client, name, kubernetes.InspectOptions{Images: mapper})
 }
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pmady/kube-dependency-checker/pkg/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration",
+}
+
+var configViewCmd = &cobra.Command{
+	Use:   "view",
+	Short: "Show effective flag values and where they come from",
+	Long: `Show the effective value of every flag and its source: a command-line
+flag, a KDC_* environment variable, a configuration file or the default.
+
+Configuration files are read from ~/.config/kube-dependency-checker/config.yaml
+(or --config / $KDC_CONFIG) and from .kube-dependency-checker.yaml in the
+current directory or its parents up to the repository root; the repo-local
+file takes precedence.
+
+Examples:
+  # Show the effective configuration
+  kube-dependency-checker config view
+
+  # As YAML
+  kube-dependency-checker config view -o yaml`,
+	Args: cobra.NoArgs,
+	RunE: runConfigView,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configViewCmd)
+}
+
+// configView is the effective configuration of every command
+type configView struct {
+	Files    []string        `json:"files" yaml:"files"`
+	Commands []commandConfig `json:"commands" yaml:"commands"`
+}
+
+type commandConfig struct {
+	Command  string           `json:"command" yaml:"command"`
+	Settings []config.Setting `json:"settings" yaml:"settings"`
+}
+
+func runConfigView(cmd *cobra.Command, args []string) error {
+	view := configView{Files: make([]string, 0)}
+	for _, f := range appConfig.Files {
+		view.Files = append(view.Files, f.Path)
+	}
+
+	global, err := appConfig.Resolve(nil, rootCmd.PersistentFlags())
+	if err != nil {
+		return err
+	}
+	view.Commands = append(view.Commands, commandConfig{Command: "global", Settings: global})
+
+	var walk func(c *cobra.Command) error
+	walk = func(c *cobra.Command) error {
+		for _, sub := range c.Commands() {
+			// Skip cobra's generated completion commands
+			if !sub.IsAvailableCommand() || sub.Name() == "completion" {
+				continue
+			}
+			flags := pflag.NewFlagSet(sub.Name(), pflag.ContinueOnError)
+			sub.LocalNonPersistentFlags().VisitAll(func(f *pflag.Flag) {
+				if f.Name != "help" {
+					flags.AddFlag(f)
+				}
+			})
+			if flags.HasFlags() {
+				settings, err := appConfig.Resolve(commandSection(sub), flags)
+				if err != nil {
+					return err
+				}
+				view.Commands = append(view.Commands, commandConfig{
+					Command:  strings.Join(commandSection(sub), " "),
+					Settings: settings,
+				})
+			}
+			if err := walk(sub); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(rootCmd); err != nil {
+		return err
+	}
+
+	return writeConfigView(os.Stdout, view)
+}
+
+func writeConfigView(w io.Writer, view configView) error {
+	switch strings.ToLower(outputFormat) {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(view)
+	case "yaml":
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		return encoder.Encode(view)
+	case "table", "":
+	default:
+		return fmt.Errorf("config view supports table, json and yaml output")
+	}
+
+	_, _ = fmt.Fprintln(w, "Configuration files:")
+	if len(view.Files) == 0 {
+		_, _ = fmt.Fprintln(w, "  (none)")
+	}
+	for _, f := range view.Files {
+		_, _ = fmt.Fprintf(w, "  - %s\n", f)
+	}
+
+	nameWidth, valueWidth := 0, 0
+	for _, c := range view.Commands {
+		for _, s := range c.Settings {
+			nameWidth = max(nameWidth, len(s.Name))
+			valueWidth = max(valueWidth, len(s.Value))
+		}
+	}
+	for _, c := range view.Commands {
+		_, _ = fmt.Fprintf(w, "\n%s:\n", c.Command)
+		for _, s := range c.Settings {
+			_, _ = fmt.Fprintf(w, "  %-*s  %-*s  %s\n", nameWidth, s.Name, valueWidth, s.Value, s.Source)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,532 @@
+package cmd
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pmady/kube-dependency-checker/pkg/bundle"
+	"github.com/pmady/kube-dependency-checker/pkg/compatibility"
+	"github.com/pmady/kube-dependency-checker/pkg/generate"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	dataURL     string
+	signKeyPath string
+	keygenOut   string
+
+	generateSources generate.Sources
+	generateFile    string
+	generateDryRun  bool
+	generateYes     bool
+)
+
+var dataCmd = &cobra.Command{
+	Use:   "data",
+	Short: "Manage the compatibility data",
+	Long: `Manage the compatibility data used by the other commands.
+
+The data compiled into the binary can be replaced by a newer bundle fetched
+with 'data update'. Downloaded bundles are kept in the user cache directory
+(or --cache-dir) and the newest valid one is used whenever it is newer than
+the compiled-in data. Without network access the last downloaded bundle, or
+the compiled-in data, is used.
+
+Bundles carry a detached ed25519 signature (the bundle URL or path with
+".sig" appended) and are only used if it verifies against a key pinned in the
+binary or given with --data-key. Unsigned or tampered bundles are refused
+unless --insecure-data is given.`,
+}
+
+var dataUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Download the latest compatibility data",
+	Long: `Download the latest compatibility data bundle into the local cache.
+
+The server's ETag is remembered, so an unchanged bundle is not downloaded
+again. Bundles that fail validation are rejected and the cache is left as it
+was.
+
+Examples:
+  # Update from the default location
+  kube-dependency-checker data update
+
+  # Update from an internal mirror
+  kube-dependency-checker data update --url https://mirror.example.com/kdc/matrix.yaml`,
+	Args: cobra.NoArgs,
+	RunE: runDataUpdate,
+}
+
+var dataStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show which compatibility data is in use",
+	Long: `Show the compatibility data in use and the bundles in the local cache.
+
+Examples:
+  kube-dependency-checker data status
+  kube-dependency-checker data status -o json`,
+	Args: cobra.NoArgs,
+	RunE: runDataStatus,
+}
+
+var dataVerifyCmd = &cobra.Command{
+	Use:   "verify [BUNDLE...]",
+	Short: "Verify the signatures of compatibility data bundles",
+	Long: `Verify bundle files against their detached signatures (BUNDLE.sig) and the
+trusted keys. Without arguments every bundle in the cache is verified.
+--insecure-data has no effect: the command fails if any bundle does not
+verify.
+
+Examples:
+  # Verify the downloaded bundles
+  kube-dependency-checker data verify
+
+  # Verify a bundle before publishing it
+  kube-dependency-checker data verify matrix.yaml --data-key kdc-data.pub`,
+	RunE: runDataVerify,
+}
+
+var dataSignCmd = &cobra.Command{
+	Use:   "sign BUNDLE...",
+	Short: "Sign compatibility data bundles",
+	Long: `Write a detached signature (BUNDLE.sig) for each bundle with an ed25519
+private key created by 'data keygen'.
+
+Examples:
+  kube-dependency-checker data sign --key kdc-data.key pkg/compatibility/data/matrix.yaml`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runDataSign,
+}
+
+var dataLintCmd = &cobra.Command{
+	Use:   "lint [BUNDLE...]",
+	Short: "Check compatibility data for inconsistencies",
+	Long: `Check compatibility data bundles for internal inconsistencies and report
+every issue found. Without arguments the data in use is checked.
+
+Checks:
+- k8sVersion matches the version the entry is listed under
+- every version parses ("x" segments, as in 3.5.x, are wildcards)
+- minVersion is not above maxVersion and recommended lies between them
+- there are no gaps between consecutive Kubernetes minor versions
+- a component's minVersion never goes backwards in a later release
+
+Examples:
+  # Lint the data in use
+  kube-dependency-checker data lint
+
+  # Lint a bundle before publishing it
+  kube-dependency-checker data lint pkg/compatibility/data/matrix.yaml`,
+	RunE: runDataLint,
+}
+
+var dataGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Update the data file from upstream Kubernetes sources",
+	Long: `Derive etcd, CoreDNS and pause versions from local copies of upstream files
+and update a compatibility data file with them. Kubernetes releases missing
+from the file are added by copying the previous release.
+
+Sources, from least to most authoritative:
+- Kubernetes CHANGELOG-1.xx.md files
+- CoreDNS's kubernetes/CoreDNS-k8s_version.md
+- kubeadm's cmd/kubeadm/app/constants/constants.go (one per release)
+
+The changes are shown as a diff and written after confirmation. Re-sign the
+file with 'data sign' before publishing it.
+
+Examples:
+  # Preview the changes from kubeadm 1.32 and 1.33
+  kube-dependency-checker data generate --dry-run \
+    --kubeadm-constants k8s-1.32/cmd/kubeadm/app/constants/constants.go \
+    --kubeadm-constants k8s-1.33/cmd/kubeadm/app/constants/constants.go \
+    --coredns-versions deployment/kubernetes/CoreDNS-k8s_version.md
+
+  # Write the changes without asking
+  kube-dependency-checker data generate --yes --changelog CHANGELOG/CHANGELOG-1.33.md`,
+	Args: cobra.NoArgs,
+	RunE: runDataGenerate,
+}
+
+var dataKeygenCmd = &cobra.Command{
+	Use:   "keygen",
+	Short: "Create a key pair for signing compatibility data bundles",
+	Long: `Create an ed25519 key pair: PREFIX.key holds the private key used by
+'data sign' and PREFIX.pub the public key to pin with --data-key or at build
+time (make build DATA_KEYS=<public key>).
+
+Examples:
+  kube-dependency-checker data keygen --out kdc-data`,
+	Args: cobra.NoArgs,
+	RunE: runDataKeygen,
+}
+
+func init() {
+	rootCmd.AddCommand(dataCmd)
+	dataCmd.AddCommand(dataUpdateCmd)
+	dataCmd.AddCommand(dataStatusCmd)
+	dataCmd.AddCommand(dataVerifyCmd)
+	dataCmd.AddCommand(dataLintCmd)
+	dataCmd.AddCommand(dataGenerateCmd)
+	dataCmd.AddCommand(dataSignCmd)
+	dataCmd.AddCommand(dataKeygenCmd)
+	dataUpdateCmd.Flags().StringVar(&dataURL, "url", bundle.DefaultURL, "URL of the compatibility data bundle")
+	dataSignCmd.Flags().StringVar(&signKeyPath, "key", "", "Private key file")
+	_ = dataSignCmd.MarkFlagRequired("key")
+	dataGenerateCmd.Flags().StringArrayVar(&generateSources.KubeadmConstants, "kubeadm-constants", nil, "kubeadm constants.go file (repeatable)")
+	dataGenerateCmd.Flags().StringVar(&generateSources.CoreDNSVersions, "coredns-versions", "", "CoreDNS-k8s_version.md file")
+	dataGenerateCmd.Flags().StringArrayVar(&generateSources.Changelogs, "changelog", nil, "Kubernetes CHANGELOG-1.xx.md file (repeatable)")
+	dataGenerateCmd.Flags().StringVar(&generateFile, "file", "pkg/compatibility/data/matrix.yaml", "Data file to update")
+	dataGenerateCmd.Flags().BoolVar(&generateDryRun, "dry-run", false, "Show the changes without writing them")
+	dataGenerateCmd.Flags().BoolVarP(&generateYes, "yes", "y", false, "Write the changes without asking for confirmation")
+	dataKeygenCmd.Flags().StringVar(&keygenOut, "out", "kdc-data", "Path prefix of the key files")
+}
+
+func runDataUpdate(cmd *cobra.Command, args []string) error {
+	cache, err := dataCache()
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+	updater := &bundle.Updater{Cache: cache}
+	result, err := updater.Update(cmd.Context(), dataURL)
+	if err != nil {
+		return err
+	}
+
+	return writeData(os.Stdout, result, func(w io.Writer) {
+		if !result.Updated {
+			_, _ = fmt.Fprintf(w, "Compatibility data is up to date (generated %s)\n", formatGenerated(result.Generated))
+			return
+		}
+		_, _ = fmt.Fprintf(w, "Downloaded compatibility data for %d Kubernetes versions (generated %s)\n",
+			result.Versions, formatGenerated(result.Generated))
+		_, _ = fmt.Fprintf(w, "Saved to %s\n", result.Path)
+		if result.KeyID == "" {
+			_, _ = fmt.Fprintln(w, "Warning: the bundle was accepted without a valid signature (--insecure-data)")
+		} else {
+			_, _ = fmt.Fprintf(w, "Signed by key %s\n", result.KeyID)
+		}
+		if !result.Generated.After(compatibility.Embedded().Generated) {
+			_, _ = fmt.Fprintln(w, "The compiled-in data is as new or newer and will continue to be used")
+		}
+	})
+}
+
+// dataStatus describes the compatibility data in use
+type dataStatus struct {
+	Source      string        `json:"source" yaml:"source"`
+	Generated   time.Time     `json:"generated" yaml:"generated"`
+	K8sVersions []string      `json:"k8sVersions" yaml:"k8sVersions"`
+	Cached      []cachedEntry `json:"cached" yaml:"cached"`
+	Errors      []string      `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+type cachedEntry struct {
+	URL       string    `json:"url" yaml:"url"`
+	Path      string    `json:"path" yaml:"path"`
+	Generated time.Time `json:"generated" yaml:"generated"`
+	Fetched   time.Time `json:"fetched" yaml:"fetched"`
+	KeyID     string    `json:"keyId,omitempty" yaml:"keyId,omitempty"`
+}
+
+func runDataStatus(cmd *cobra.Command, args []string) error {
+	active := compatibility.Active()
+	status := dataStatus{
+		Source:      active.Source,
+		Generated:   active.Generated,
+		K8sVersions: make([]string, 0),
+		Cached:      make([]cachedEntry, 0),
+	}
+	for _, v := range compatibility.GetSupportedVersions() {
+		status.K8sVersions = append(status.K8sVersions, v.ShortString())
+	}
+
+	if cache, err := dataCache(); err == nil {
+		bundles, errs := cache.List()
+		for _, c := range bundles {
+			status.Cached = append(status.Cached, cachedEntry{
+				URL:       c.Metadata.URL,
+				Path:      c.Path,
+				Generated: c.Bundle.Generated,
+				Fetched:   c.Metadata.Fetched,
+				KeyID:     c.KeyID,
+			})
+		}
+		for _, err := range errs {
+			status.Errors = append(status.Errors, err.Error())
+		}
+	}
+
+	return writeData(os.Stdout, status, func(w io.Writer) {
+		_, _ = fmt.Fprintf(w, "Using:       %s\n", status.Source)
+		_, _ = fmt.Fprintf(w, "Generated:   %s\n", formatGenerated(status.Generated))
+		_, _ = fmt.Fprintf(w, "Kubernetes:  %s\n", strings.Join(status.K8sVersions, ", "))
+		_, _ = fmt.Fprintln(w, "\nCached bundles:")
+		if len(status.Cached) == 0 {
+			_, _ = fmt.Fprintln(w, "  (none)")
+		}
+		for _, c := range status.Cached {
+			signer := "unverified"
+			if c.KeyID != "" {
+				signer = "signed by " + c.KeyID
+			}
+			_, _ = fmt.Fprintf(w, "  - %s\n    generated %s, fetched %s, %s\n    %s\n",
+				c.URL, formatGenerated(c.Generated), formatGenerated(c.Fetched), signer, c.Path)
+		}
+		for _, e := range status.Errors {
+			_, _ = fmt.Fprintf(w, "  ! %s\n", e)
+		}
+	})
+}
+
+// verifyResult is the outcome of verifying one bundle
+type verifyResult struct {
+	Path      string     `json:"path" yaml:"path"`
+	Verified  bool       `json:"verified" yaml:"verified"`
+	KeyID     string     `json:"keyId,omitempty" yaml:"keyId,omitempty"`
+	Generated *time.Time `json:"generated,omitempty" yaml:"generated,omitempty"`
+	Error     string     `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+func runDataVerify(cmd *cobra.Command, args []string) error {
+	verifier, err := dataVerifier()
+	if err != nil {
+		return err
+	}
+	paths := args
+	if len(paths) == 0 {
+		cache, err := dataCache()
+		if err != nil {
+			return err
+		}
+		if paths, err = filepath.Glob(filepath.Join(cache.Dir, "*.yaml")); err != nil {
+			return err
+		}
+	}
+
+	results := make([]verifyResult, 0, len(paths))
+	failed := 0
+	for _, path := range paths {
+		r := verifyResult{Path: path}
+		b, keyID, err := verifier.VerifyFile(path)
+		if err != nil {
+			r.Error = err.Error()
+			failed++
+		} else {
+			r.Verified = true
+			r.KeyID = keyID
+			r.Generated = &b.Generated
+		}
+		results = append(results, r)
+	}
+
+	err = writeData(os.Stdout, results, func(w io.Writer) {
+		if len(results) == 0 {
+			_, _ = fmt.Fprintln(w, "No bundles to verify")
+		}
+		for _, r := range results {
+			if r.Verified {
+				_, _ = fmt.Fprintf(w, "verified  %s (key %s, generated %s)\n", r.Path, r.KeyID, formatGenerated(*r.Generated))
+			} else {
+				_, _ = fmt.Fprintf(w, "FAILED    %s: %s\n", r.Path, r.Error)
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d of %d bundles failed verification", failed, len(results))
+	}
+	return nil
+}
+
+// lintResult lists the issues found in one bundle
+type lintResult struct {
+	Source string                `json:"source" yaml:"source"`
+	Issues []compatibility.Issue `json:"issues" yaml:"issues"`
+}
+
+func runDataLint(cmd *cobra.Command, args []string) error {
+	bundles := make([]*compatibility.Bundle, 0, len(args))
+	for _, path := range args {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		b, err := compatibility.ParseBundle(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		b.Source = path
+		bundles = append(bundles, b)
+	}
+	if len(bundles) == 0 {
+		bundles = append(bundles, compatibility.Active())
+	}
+
+	results := make([]lintResult, 0, len(bundles))
+	total := 0
+	for _, b := range bundles {
+		issues := compatibility.Lint(b.Matrices)
+		total += len(issues)
+		results = append(results, lintResult{Source: b.Source, Issues: issues})
+	}
+
+	err := writeData(os.Stdout, results, func(w io.Writer) {
+		for _, r := range results {
+			if len(r.Issues) == 0 {
+				_, _ = fmt.Fprintf(w, "%s: no issues\n", r.Source)
+				continue
+			}
+			_, _ = fmt.Fprintf(w, "%s:\n", r.Source)
+			for _, issue := range r.Issues {
+				_, _ = fmt.Fprintf(w, "  %s\n", issue)
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if total > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("found %d issues", total)
+	}
+	return nil
+}
+
+func runDataGenerate(cmd *cobra.Command, args []string) error {
+	original, err := os.ReadFile(generateFile)
+	if err != nil {
+		return err
+	}
+	b, err := compatibility.ParseBundle(original)
+	if err != nil {
+		return fmt.Errorf("%s: %w", generateFile, err)
+	}
+	versions, err := generate.Derive(generateSources)
+	if err != nil {
+		return err
+	}
+	changes, err := generate.Apply(b, versions, time.Now())
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if len(changes) == 0 {
+		_, _ = fmt.Fprintf(out, "%s is up to date\n", generateFile)
+		return nil
+	}
+	updated, err := b.Marshal(compatibility.Header(original))
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprint(out, generate.Diff(generateFile, generateFile, string(original), string(updated)))
+	_, _ = fmt.Fprintf(out, "\n%d changes:\n", len(changes))
+	for _, c := range changes {
+		_, _ = fmt.Fprintf(out, "  %s\n", c)
+	}
+	for _, issue := range compatibility.Lint(b.Matrices) {
+		_, _ = fmt.Fprintf(out, "Warning: %s\n", issue)
+	}
+
+	if generateDryRun {
+		return nil
+	}
+	if !generateYes {
+		_, _ = fmt.Fprintf(out, "\nWrite %s? [y/N] ", generateFile)
+		answer, _ := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			_, _ = fmt.Fprintln(out, "Not written")
+			return nil
+		}
+	}
+	if err := os.WriteFile(generateFile, updated, 0o644); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(out, "Wrote %s\n", generateFile)
+	if _, err := os.Stat(generateFile + bundle.SignatureExt); err == nil {
+		_, _ = fmt.Fprintf(out, "The signature %s%s is now stale; re-sign with 'data sign'\n", generateFile, bundle.SignatureExt)
+	}
+	return nil
+}
+
+func runDataSign(cmd *cobra.Command, args []string) error {
+	keyData, err := os.ReadFile(signKeyPath)
+	if err != nil {
+		return err
+	}
+	key, err := bundle.ParsePrivateKey(string(keyData))
+	if err != nil {
+		return fmt.Errorf("%s: %w", signKeyPath, err)
+	}
+
+	for _, path := range args {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		// Refuse to sign something that would be rejected when loaded
+		if _, err := compatibility.ParseBundle(data); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if err := os.WriteFile(path+bundle.SignatureExt, bundle.Sign(data, key), 0o644); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(os.Stdout, "Signed %s with key %s\n", path, bundle.KeyID(key.Public().(ed25519.PublicKey)))
+	}
+	return nil
+}
+
+func runDataKeygen(cmd *cobra.Command, args []string) error {
+	public, private, err := bundle.GenerateKey()
+	if err != nil {
+		return err
+	}
+	for _, path := range []string{keygenOut + ".key", keygenOut + ".pub"} {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%s already exists", path)
+		}
+	}
+	if err := os.WriteFile(keygenOut+".key", []byte(private+"\n"), 0o600); err != nil {
+		return err
+	}
+	if err := os.WriteFile(keygenOut+".pub", []byte(public+"\n"), 0o644); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(os.Stdout, "Private key: %s.key (keep it secret)\nPublic key:  %s.pub\n\n%s\n", keygenOut, keygenOut, public)
+	return nil
+}
+
+func formatGenerated(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// writeData writes v as JSON or YAML, or calls table for table output
+func writeData(w io.Writer, v interface{}, table func(io.Writer)) error {
+	switch strings.ToLower(outputFormat) {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case "yaml":
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		return encoder.Encode(v)
+	case "table", "":
+		table(w)
+		return nil
+	}
+	return fmt.Errorf("data commands support table, json and yaml output")
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pmady/kube-dependency-checker/pkg/explore"
+	"github.com/spf13/cobra"
+)
+
+var exploreCmd = &cobra.Command{
+	Use:   "explore",
+	Short: "Browse the compatibility data interactively",
+	Long: `Open a terminal UI to browse the compatibility data.
+
+The explorer lists the Kubernetes releases in the data. Open a release to
+see its components and open a component to see its version range, skew
+policy and notes, and how it changed across releases. Press c on a release
+and enter on another to compare their component versions and feature gates
+side by side, or u and enter to build the upgrade plan between them; the
+target of a plan can then be moved with the left and right arrows.
+
+Keys: arrows or h/j/k/l to move, enter to open, esc or backspace to go back
+and q to quit.
+
+Examples:
+  # Explore the embedded or last downloaded data
+  kube-dependency-checker explore`,
+	Args: cobra.NoArgs,
+	RunE: runExplore,
+}
+
+func init() {
+	rootCmd.AddCommand(exploreCmd)
+}
+
+func runExplore(cmd *cobra.Command, args []string) error {
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return fmt.Errorf("explore needs an interactive terminal")
+	}
+	restore, err := explore.MakeRaw(os.Stdin)
+	if err != nil {
+		return fmt.Errorf("cannot switch the terminal to raw mode: %w", err)
+	}
+	defer restore()
+
+	return explore.Run(explore.New(), os.Stdin, cmd.OutOrStdout())
+}
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/pmady/kube-dependency-checker/pkg/exporter"
+	"github.com/pmady/kube-dependency-checker/pkg/fleet"
+	"github.com/pmady/kube-dependency-checker/pkg/images"
+	"github.com/pmady/kube-dependency-checker/pkg/inventory"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exporterAddr        string
+	exporterInterval    time.Duration
+	exporterInventory   []string
+	exporterSnapshots   []string
+	exporterKubeconfig  string
+	exporterContext     []string
+	exporterContexts    []string
+	exporterAllContexts bool
+	exporterWorkers     int
+	exporterTimeout     time.Duration
+	exporterImageMap    string
+)
+
+var exporterCmd = &cobra.Command{
+	Use:   "exporter",
+	Short: "Export fleet compatibility as Prometheus metrics",
+	Long: `Periodically evaluate a fleet of clusters and expose the results as
+Prometheus metrics on /metrics.
+
+Clusters are read from one or more sources, re-read on every refresh:
+
+--context, --contexts or --all-contexts inspect live clusters of the
+kubeconfig, like check does; clusters that cannot be reached are reported
+with kdc_cluster_check_success 0 without failing the others.
+
+--snapshots reads a directory of saved kube-system pod lists ("kubectl get
+pods -n kube-system -o yaml"), one file per cluster named after it, such as
+prod-east.yaml. The Kubernetes version is that of the kube-apiserver image.
+Pod lists have no kubelet versions, so snapshot clusters export no
+kdc_kubelet_skew_minors; list them in an inventory with nodePools for that.
+
+--inventory reads inventory files, or directories of inventory files,
+describing each cluster's Kubernetes version, component versions and node
+pool kubelet versions; "pods" may point at a pod list snapshot, whose
+components are added to those listed:
+
+  clusters:
+    - name: prod-east
+      k8sVersion: 1.30.4
+      components:
+        etcd: 3.5.12
+        coredns: 1.11.1
+        containerd: 1.7.16
+      nodePools:
+        - name: default
+          kubeletVersion: 1.29.6
+
+Exported metrics:
+  kdc_component_compatible{cluster,component}
+  kdc_k8s_days_until_eol{cluster,k8s_version}
+  kdc_kubelet_skew_minors{cluster,nodepool}
+  kdc_cluster_check_success{cluster}
+  kdc_last_refresh_timestamp_seconds
+
+Examples:
+  # Evaluate an inventory file every 5 minutes
+  kube-dependency-checker exporter --inventory fleet.yaml
+
+  # Evaluate a directory of inventory files every minute
+  kube-dependency-checker exporter --inventory ./clusters --interval 1m --addr :9090
+
+  # Inspect every kubeconfig context, 10 at a time
+  kube-dependency-checker exporter --all-contexts --workers 10
+
+  # Evaluate pod list snapshots collected by a CronJob
+  kube-dependency-checker exporter --snapshots /var/lib/kdc/snapshots`,
+	RunE: runExporter,
+}
+
+func init() {
+	rootCmd.AddCommand(exporterCmd)
+	exporterCmd.Flags().StringVar(&exporterAddr, "addr", ":9090", "Address to serve metrics on")
+	exporterCmd.Flags().DurationVar(&exporterInterval, "interval", 5*time.Minute, "How often to re-evaluate clusters")
+	exporterCmd.Flags().StringArrayVar(&exporterInventory, "inventory", nil, "Inventory file or directory (repeatable)")
+	exporterCmd.Flags().StringArrayVar(&exporterSnapshots, "snapshots", nil, "Directory of kube-system pod list snapshots, one per cluster (repeatable)")
+	exporterCmd.Flags().StringVar(&exporterKubeconfig, "kubeconfig", "", "Path to the kubeconfig file (defaults to $KUBECONFIG or ~/.kube/config)")
+	exporterCmd.Flags().StringArrayVar(&exporterContext, "context", nil, "Kubeconfig context of a cluster to inspect (repeatable)")
+	exporterCmd.Flags().StringSliceVar(&exporterContexts, "contexts", nil, "Comma-separated kubeconfig contexts to inspect")
+	exporterCmd.Flags().BoolVar(&exporterAllContexts, "all-contexts", false, "Inspect every kubeconfig context")
+	exporterCmd.Flags().IntVar(&exporterWorkers, "workers", fleet.DefaultWorkers, "Number of clusters to inspect in parallel")
+	exporterCmd.Flags().DurationVar(&exporterTimeout, "timeout", fleet.DefaultTimeout, "Time allowed for inspecting each cluster")
+	exporterCmd.Flags().StringVar(&exporterImageMap, "image-map", "", "Image mapping file of registry mirrors and image repositories to components")
+}
+
+func runExporter(cmd *cobra.Command, args []string) error {
+	if len(exporterInventory) == 0 && len(exporterSnapshots) == 0 && len(exporterContext) == 0 && len(exporterContexts) == 0 && !exporterAllContexts {
+		return fmt.Errorf("at least one --inventory, --snapshots, --context or --all-contexts is required")
+	}
+	if exporterInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	sources, err := exporterSources()
+	if err != nil {
+		return err
+	}
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+	exp := exporter.New(logger, sources...)
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", exp)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok\n"))
+	})
+
+	httpServer := &http.Server{
+		Addr:              exporterAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go exp.Run(ctx, exporterInterval)
+
+	errCh := make(chan error, 1)
+	go func() {
+		logger.Printf("serving metrics on %s", exporterAddr)
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	logger.Printf("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return httpServer.Shutdown(shutdownCtx)
+}
+
+// exporterSources builds the sources of the exporter from its flags
+func exporterSources() ([]exporter.Source, error) {
+	mapper := images.Default()
+	if exporterImageMap != "" {
+		var err error
+		if mapper, err = images.LoadMapper(exporterImageMap); err != nil {
+			return nil, err
+		}
+	}
+
+	sources := make([]exporter.Source, 0)
+	for _, path := range exporterInventory {
+		sources = append(sources, &exporter.FileSource{Path: path, Images: mapper})
+	}
+	for _, dir := range exporterSnapshots {
+		sources = append(sources, &exporter.SnapshotSource{Dir: dir, Images: mapper})
+	}
+
+	names := append(append([]string{}, exporterContext...), exporterContexts...)
+	if len(names) == 0 && !exporterAllContexts {
+		return sources, nil
+	}
+	cfg, err := loadKubeconfig(exporterKubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	if exporterAllContexts {
+		names = cfg.ContextNames()
+	}
+	targets := make([]fleet.Target, 0, len(names))
+	for _, name := range names {
+		name := name
+		targets = append(targets, fleet.Target{
+			Name: name,
+			Inspect: func(ctx context.Context) (*inventory.Cluster, error) {
+				return inspectContext(ctx, cfg, name, mapper)
+			},
+		})
+	}
+	sources = append(sources, &exporter.TargetSource{
+		Label:   "kubeconfig",
+		Targets: targets,
+		Options: fleet.Options{Workers: exporterWorkers, Timeout: exporterTimeout},
+	})
+	return sources, nil
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/pmady/kube-dependency-checker/pkg/checker"
+	"github.com/spf13/cobra"
+)
+
+var featureGateName string
+
+var featureGatesCmd = &cobra.Command{
+	Use:   "featuregates",
+	Short: "Show the state of feature gates per Kubernetes version",
+	Long: `Show the stage (alpha, beta, ga, deprecated or removed), default and
+lock of notable feature gates.
+
+With --k8s-version the gates of one release are listed; with --gate the
+lifecycle of one gate across every release in the data. Gates that are
+locked to their default fail component startup when set to the other
+value, and removed gates fail it when set at all.
+
+Examples:
+  # Feature gates of Kubernetes 1.31
+  kube-dependency-checker featuregates --k8s-version 1.31
+
+  # When was NodeSwap enabled by default?
+  kube-dependency-checker featuregates --gate NodeSwap
+
+  # Output as JSON
+  kube-dependency-checker featuregates --gate SidecarContainers -o json`,
+	Args: cobra.NoArgs,
+	RunE: runFeatureGates,
+}
+
+func init() {
+	rootCmd.AddCommand(featureGatesCmd)
+	featureGatesCmd.Flags().StringVar(&k8sVersion, "k8s-version", "", "Kubernetes version to list the feature gates of")
+	featureGatesCmd.Flags().StringVar(&featureGateName, "gate", "", "Feature gate to show across releases")
+}
+
+func runFeatureGates(cmd *cobra.Command, args []string) error {
+	if k8sVersion == "" && featureGateName == "" {
+		return fmt.Errorf("--k8s-version or --gate is required")
+	}
+	states, err := checker.ListFeatureGates(k8sVersion, featureGateName)
+	if err != nil {
+		return err
+	}
+
+	return writeData(cmd.OutOrStdout(), states, func(w io.Writer) {
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "KUBERNETES\tFEATURE GATE\tSTAGE\tDEFAULT\tLOCKED")
+		for _, s := range states {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%t\n", s.K8sVersion, s.Name, s.Stage, s.Default, s.LockedToDefault)
+		}
+		_ = tw.Flush()
+	})
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pmady/kube-dependency-checker/pkg/compatibility"
+	"github.com/pmady/kube-dependency-checker/pkg/kubeadm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	imagesRepository    string
+	imagesKubeadmConfig string
+)
+
+var imagesCmd = &cobra.Command{
+	Use:   "images",
+	Short: "List the images kubeadm deploys for a Kubernetes version",
+	Long: `List the images kubeadm pulls for a Kubernetes version, for pre-pulling
+into an air-gapped registry.
+
+Control plane images are tagged with the Kubernetes version; CoreDNS, pause
+and etcd use the recommended versions of the compatibility data. Give a
+patch version such as 1.31.4 to get the exact control plane tags; for a
+minor version the .0 release is listed. With --kubeadm-config the
+repository and tags of a kubeadm ClusterConfiguration are used instead;
+--k8s-version then only applies to a file without a kubernetesVersion.
+
+Table output prints one image per line.
+
+Examples:
+  # List the images of Kubernetes 1.31.4
+  kube-dependency-checker images --k8s-version 1.31.4
+
+  # Pull them all
+  kube-dependency-checker images --k8s-version 1.31.4 | xargs -n1 docker pull
+
+  # List the images as a mirror holds them
+  kube-dependency-checker images --k8s-version 1.31.4 --image-repository harbor.example.com/k8s
+
+  # List the images a kubeadm configuration deploys
+  kube-dependency-checker images --kubeadm-config kubeadm.yaml -o json`,
+	Args: cobra.NoArgs,
+	RunE: runImages,
+}
+
+func init() {
+	rootCmd.AddCommand(imagesCmd)
+	imagesCmd.Flags().StringVar(&k8sVersion, "k8s-version", "", "Kubernetes version to list images for (e.g., 1.31.4)")
+	imagesCmd.Flags().StringVar(&imagesRepository, "image-repository", kubeadm.DefaultImageRepository, "Registry and path the images are pulled from")
+	imagesCmd.Flags().StringVar(&imagesKubeadmConfig, "kubeadm-config", "", "kubeadm configuration file to list images for")
+}
+
+func runImages(cmd *cobra.Command, args []string) error {
+	var cfg *kubeadm.ClusterConfiguration
+	switch {
+	case imagesKubeadmConfig != "":
+		version := ""
+		if onCommandLine("k8s-version") {
+			version = k8sVersion
+		}
+		loaded, err := loadKubeadmConfig(imagesKubeadmConfig, version)
+		if err != nil {
+			return err
+		}
+		cfg = loaded.Cluster
+		if onCommandLine("image-repository") {
+			cfg.ImageRepository = imagesRepository
+		}
+	case k8sVersion == "":
+		return fmt.Errorf("--k8s-version or --kubeadm-config is required")
+	default:
+		cfg = &kubeadm.ClusterConfiguration{KubernetesVersion: k8sVersion, ImageRepository: imagesRepository}
+	}
+
+	v, err := cfg.Version()
+	if err != nil {
+		return err
+	}
+	list, err := kubeadm.Images(cfg)
+	if err != nil {
+		return err
+	}
+
+	// Warnings go to stderr so that the list can be piped
+	stderr := cmd.ErrOrStderr()
+	if v.Raw == v.ShortString() {
+		_, _ = fmt.Fprintf(stderr, "Warning: no patch version given, listing Kubernetes %s; pass the exact release to match your cluster\n", v)
+	}
+	if matrix, ok := compatibility.GetMatrix(v.Raw); ok {
+		if warning := matrix.SupportWarning(); warning != "" {
+			_, _ = fmt.Fprintf(stderr, "Warning: %s\n", warning)
+		}
+	}
+
+	return writeData(cmd.OutOrStdout(), list, func(w io.Writer) {
+		for _, image := range list {
+			_, _ = fmt.Fprintln(w, image.Image)
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pmady/kube-dependency-checker/pkg/checker"
+	"github.com/pmady/kube-dependency-checker/pkg/node"
+	"github.com/pmady/kube-dependency-checker/pkg/output"
+	"github.com/pmady/kube-dependency-checker/pkg/policy"
+	"github.com/pmady/kube-dependency-checker/pkg/version"
+	"github.com/spf13/cobra"
+)
+
+var (
+	nodeK8sVersion string
+	nodeSources    node.Sources
+	nodeFactsPath  string
+	nodeFailOn     string
+)
+
+var nodeCmd = &cobra.Command{
+	Use:   "node",
+	Short: "Check the components of the local node",
+	Long: `Check the kubelet, containerd and runc of the local host against a
+Kubernetes version, e.g. while building a node image.
+
+The binaries are not run: their versions are read from files holding the
+output of "kubelet --version", "containerd --version" and "runc --version".
+The containerd configuration and the cgroup version are read from the host.
+Without --k8s-version the node is checked against the kubelet's release.
+
+The host is also checked against the node prerequisites of the release:
+cgroup v1 support, the minimum kernel, swap and containerd's systemd cgroup
+driver. Unmet prerequisites are reported as findings. The kernel, OS and
+swap are read from /proc and /etc/os-release.
+
+--facts supplies facts from a YAML file instead, e.g. when checking an image
+that is not running. The host is then not read at all: only the facts in the
+file, the version files given and an explicit --containerd-config or
+--cgroup-root are used. An unknown cgroup version, kernel or swap is
+reported as an info finding, and a key the file should not have is an error.
+
+Examples:
+  # Capture the versions and check the node against Kubernetes 1.31
+  kubelet --version > /tmp/kubelet.txt
+  containerd --version > /tmp/containerd.txt
+  runc --version > /tmp/runc.txt
+  kube-dependency-checker node --k8s-version 1.31 \
+    --kubelet-version-file /tmp/kubelet.txt \
+    --containerd-version-file /tmp/containerd.txt \
+    --runc-version-file /tmp/runc.txt
+
+  # Fail an image build when a component is incompatible
+  kube-dependency-checker node --kubelet-version-file /tmp/kubelet.txt --fail-on error
+
+  # Check facts recorded elsewhere against Kubernetes 1.32
+  kube-dependency-checker node --k8s-version 1.32 --facts node-facts.yaml`,
+	Args: cobra.NoArgs,
+	RunE: runNode,
+}
+
+func init() {
+	rootCmd.AddCommand(nodeCmd)
+	nodeCmd.Flags().StringVar(&nodeK8sVersion, "k8s-version", "", "Kubernetes version the node joins (defaults to the kubelet's version)")
+	nodeCmd.Flags().StringVar(&nodeSources.KubeletVersion, "kubelet-version-file", "", "File with the output of \"kubelet --version\"")
+	nodeCmd.Flags().StringVar(&nodeSources.ContainerdVersion, "containerd-version-file", "", "File with the output of \"containerd --version\"")
+	nodeCmd.Flags().StringVar(&nodeSources.RuncVersion, "runc-version-file", "", "File with the output of \"runc --version\"")
+	nodeCmd.Flags().StringVar(&nodeSources.ContainerdConfig, "containerd-config", node.DefaultContainerdConfig, "containerd configuration file")
+	nodeCmd.Flags().StringVar(&nodeSources.CgroupRoot, "cgroup-root", node.DefaultCgroupRoot, "Mount point of the cgroup filesystem")
+	nodeCmd.Flags().StringVar(&nodeFactsPath, "facts", "", "YAML file of node facts, read instead of the host")
+	nodeCmd.Flags().StringVar(&nodeFailOn, "fail-on", "never", "Exit with status 2 on problems at or above this severity (never, error, warning, info)")
+
+	nodeSources.KernelRelease = node.DefaultKernelRelease
+	nodeSources.OSRelease = node.DefaultOSRelease
+	nodeSources.Swaps = node.DefaultSwaps
+}
+
+func runNode(cmd *cobra.Command, args []string) error {
+	formatter, err := newFormatter()
+	if err != nil {
+		return err
+	}
+	if nodeFailOn != "never" && policy.SeverityRank(nodeFailOn) < 0 {
+		return fmt.Errorf("invalid --fail-on %q (want never, error, warning or info)", nodeFailOn)
+	}
+
+	src := nodeSources
+	if nodeFactsPath != "" {
+		// Facts left out of the file must not come from the machine running
+		// the check, which is not the node described
+		src.KernelRelease, src.OSRelease, src.Swaps = "", "", ""
+		if !onCommandLine("containerd-config") {
+			src.ContainerdConfig = ""
+		}
+		if !onCommandLine("cgroup-root") {
+			src.CgroupRoot = ""
+		}
+	}
+	facts, err := node.Gather(src)
+	if err != nil {
+		return err
+	}
+	if nodeFactsPath == "" {
+		facts.Hostname, _ = os.Hostname()
+	} else {
+		supplied, err := node.LoadFacts(nodeFactsPath)
+		if err != nil {
+			return err
+		}
+		facts.Merge(supplied)
+	}
+
+	target := nodeK8sVersion
+	if target == "" {
+		if facts.Kubelet == "" {
+			return fmt.Errorf("--k8s-version is required unless --kubelet-version-file is given")
+		}
+		v, err := version.Parse(facts.Kubelet)
+		if err != nil {
+			return err
+		}
+		target = v.ShortString()
+	}
+
+	result, err := checker.CheckNode(target, facts)
+	if err != nil {
+		return err
+	}
+	if err := formatter.Format(result); err != nil {
+		return err
+	}
+	return checkFailOn(cmd, nodeFailOn, []output.CheckResult{*result}, 0)
+}
@@ -4,6 +4,14 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pmady/kube-dependency-checker/pkg/bundle"
+	"github.com/pmady/kube-dependency-checker/pkg/compatibility"
+	"github.com/pmady/kube-dependency-checker/pkg/config"
+	"github.com/pmady/kube-dependency-checker/pkg/output"
 	"github.com/spf13/cobra"
 )
 
@@ -15,6 +23,17 @@ var (
 
 	// Global flags
 	outputFormat string
+	noEmoji      bool
+	wideOutput   bool
+	configPath   string
+	cacheDir     string
+	insecureData bool
+	dataKeys     []string
+
+	// appConfig is the configuration loaded before every command runs
+	appConfig *config.Config
+	// appSettings are the flag values appConfig resolved for the command
+	appSettings []config.Setting
 )
 
 var rootCmd = &cobra.Command{
@@ -36,7 +55,26 @@ Examples:
   kube-dependency-checker upgrade --from 1.28 --to 1.30
 
   # List compatible etcd versions for Kubernetes 1.30
-  kube-dependency-checker versions --component etcd --k8s-version 1.30`,
+  kube-dependency-checker versions --component etcd --k8s-version 1.30
+
+Flags can also be set in ~/.config/kube-dependency-checker/config.yaml, in a
+repo-local .kube-dependency-checker.yaml, or with KDC_* environment variables
+(e.g. KDC_OUTPUT=json). Run 'config view' to see the effective values.`,
+	PersistentPreRunE: loadConfig,
+}
+
+// ExitCheckFailed is the exit status of a check that failed --fail-on
+const ExitCheckFailed = 2
+
+// ExitError is returned by commands that completed but must exit with a
+// specific status, such as a check failing its --fail-on threshold
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
 }
 
 func Execute() error {
@@ -44,6 +82,143 @@ func Execute() error {
 }
 
 func init() {
-	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "table", "Output format (table, json, yaml)")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "table", "Output format (table, json, yaml, markdown, html, sarif, junit, go-template=..., go-template-file=..., jsonpath=...)")
+	rootCmd.PersistentFlags().BoolVar(&noEmoji, "no-emoji", false, "Use plain-text status markers instead of emoji in table output")
+	rootCmd.PersistentFlags().BoolVar(&wideOutput, "wide", false, "Show all columns without wrapping in table output")
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Configuration file (defaults to $KDC_CONFIG or ~/.config/kube-dependency-checker/config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cacheDir, "cache-dir", "", "Directory of downloaded compatibility data (defaults to the user cache directory)")
+	rootCmd.PersistentFlags().StringSliceVar(&dataKeys, "data-key", nil, "Additional trusted public key for compatibility data bundles, as base64 or a key file (repeatable)")
+	rootCmd.PersistentFlags().BoolVar(&insecureData, "insecure-data", false, "Accept unsigned or unverified compatibility data bundles")
+}
+
+// loadConfig reads the configuration files and applies them, together with
+// KDC_* environment variables, to the flags not set on the command line
+func loadConfig(cmd *cobra.Command, args []string) error {
+	userPath := configPath
+	if userPath == "" {
+		userPath = os.Getenv("KDC_CONFIG")
+	}
+	explicit := userPath != ""
+	if !explicit {
+		// Without a home directory there is simply no user configuration
+		userPath, _ = config.UserConfigPath()
+	} else if _, err := os.Stat(userPath); err != nil {
+		return err
+	}
+
+	workDir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(userPath, workDir)
+	if err != nil {
+		return err
+	}
+	// A configuration file, in particular one in a cloned repository, must
+	// not be able to replace the data or turn off its verification
+	cfg.FlagOnly = map[string]bool{"cache-dir": true, "data-key": true, "insecure-data": true}
+	// Nor may a cloned repository pick the kubeconfig, whose exec plugins
+	// run commands on this machine
+	cfg.UserOnly = map[string]bool{"kubeconfig": true, "context": true, "contexts": true, "all-contexts": true}
+	settings, err := cfg.Apply(commandSection(cmd), cmd.Flags())
+	if err != nil {
+		return err
+	}
+	appConfig, appSettings = cfg, settings
+	useCachedData()
+	return nil
 }
 
+// dataCache returns the cache of downloaded compatibility data
+func dataCache() (*bundle.Cache, error) {
+	verifier, err := dataVerifier()
+	if err != nil {
+		return nil, err
+	}
+	dir := cacheDir
+	if dir == "" {
+		if dir, err = bundle.DefaultCacheDir(); err != nil {
+			return nil, err
+		}
+	}
+	return &bundle.Cache{Dir: dir, Verifier: verifier}, nil
+}
+
+// dataVerifier trusts the keys pinned in the binary and those given with
+// --data-key
+func dataVerifier() (*bundle.Verifier, error) {
+	keys, err := bundle.PinnedKeys()
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range dataKeys {
+		// A key is either inline base64 or the path of a key file
+		if data, err := os.ReadFile(k); err == nil {
+			k = string(data)
+		}
+		key, err := bundle.ParsePublicKey(k)
+		if err != nil {
+			return nil, fmt.Errorf("--data-key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return &bundle.Verifier{Keys: keys, Insecure: insecureData}, nil
+}
+
+// useCachedData switches to the newest valid downloaded compatibility data
+// when it is newer than the embedded data. Unusable cache entries are
+// reported and skipped, so the embedded data is always a fallback. Data
+// that was accepted without a verified signature is warned about.
+func useCachedData() {
+	cache, err := dataCache()
+	if err != nil {
+		// Without a cache directory or trusted keys there is nothing but the
+		// embedded data; invalid --data-key values are reported by the data
+		// commands
+		return
+	}
+	cached, errs := cache.NewestCached()
+	for _, err := range errs {
+		_, _ = fmt.Fprintf(os.Stderr, "Warning: ignoring cached compatibility data: %v\n", err)
+	}
+	if cached == nil {
+		compatibility.Use(compatibility.Embedded())
+		return
+	}
+	if cached.KeyID == "" {
+		_, _ = fmt.Fprintf(os.Stderr, "Warning: using unverified compatibility data from %s (--insecure-data)\n", cached.Path)
+	}
+	compatibility.Use(cached.Bundle)
+}
+
+// commandSection returns the configuration section of a command: the names
+// of the command and its parents below the root, e.g. ["config", "view"]
+func commandSection(cmd *cobra.Command) []string {
+	path := strings.Fields(cmd.CommandPath())
+	return path[1:]
+}
+
+// onCommandLine reports whether a flag was given on the command line rather
+// than set from the environment or a configuration file
+func onCommandLine(name string) bool {
+	for _, s := range appSettings {
+		if s.Name == name {
+			return s.Source == config.SourceFlag
+		}
+	}
+	return false
+}
+
+// newFormatter creates the formatter selected with --output and applies
+// the table display flags
+func newFormatter() (output.Formatter, error) {
+	formatter, err := output.NewFormatter(outputFormat)
+	if err != nil {
+		return nil, err
+	}
+	if table, ok := formatter.(*output.TableFormatter); ok {
+		table.NoEmoji = noEmoji
+		table.Wide = wideOutput
+	}
+	return formatter, nil
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/pmady/kube-dependency-checker/pkg/server"
+	"github.com/spf13/cobra"
+)
+
+var serveAddr string
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve compatibility checks over an HTTP API",
+	Long: `Run kube-dependency-checker as an HTTP service.
+
+The server exposes the check, upgrade and versions commands as REST
+endpoints returning the same JSON as '-o json':
+
+  GET /api/v1/check?k8sVersion=1.30
+  GET /api/v1/upgrade?from=1.28&to=1.30
+  GET /api/v1/versions[?component=etcd[&k8sVersion=1.30]]
+
+It also serves /healthz, /readyz and an OpenAPI description at /openapi.json.
+
+Examples:
+  # Serve on port 8080
+  kube-dependency-checker serve --addr :8080`,
+	RunE: runServe,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringVar(&serveAddr, "addr", ":8080", "Address to listen on")
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+	srv := server.New(logger)
+
+	httpServer := &http.Server{
+		Addr:              serveAddr,
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		logger.Printf("listening on %s", serveAddr)
+		errCh <- httpServer.ListenAndServe()
+	}()
+	srv.SetReady(true)
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	// Stop receiving traffic before draining in-flight requests
+	srv.SetReady(false)
+	logger.Printf("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return httpServer.Shutdown(shutdownCtx)
+}
@@ -2,9 +2,8 @@ package cmd
 
 import (
 	"fmt"
-	"strconv"
-	"strings"
 
+	"github.com/pmady/kube-dependency-checker/pkg/checker"
 	"github.com/pmady/kube-dependency-checker/pkg/compatibility"
 	"github.com/spf13/cobra"
 )
@@ -12,6 +11,9 @@ import (
 var (
 	fromVersion string
 	toVersion   string
+
+	// featureGates is shared by check and upgrade
+	featureGates string
 )
 
 var upgradeCmd = &cobra.Command{
@@ -23,118 +25,63 @@ This command helps you plan upgrades by showing:
 - The recommended upgrade path (one minor version at a time)
 - Component version changes at each step
 - Any breaking changes or deprecations
+- Feature gates given with --feature-gates that are removed, or locked to
+  the other value, at a step of the path
 
 Examples:
   # Show upgrade path from 1.28 to 1.30
   kube-dependency-checker upgrade --from 1.28 --to 1.30
 
   # Show upgrade path from 1.29 to 1.32
-  kube-dependency-checker upgrade --from 1.29 --to 1.32`,
+  kube-dependency-checker upgrade --from 1.29 --to 1.32
+
+  # Show upgrade path from 1.29 to the latest supported version
+  kube-dependency-checker upgrade --from 1.29
+
+  # Plan an upgrade from an archived release; out-of-support releases on
+  # the path are reported as warnings
+  kube-dependency-checker upgrade --from 1.25 --to 1.28
+
+  # Warn about configured feature gates that are removed or locked on the way
+  kube-dependency-checker upgrade --from 1.27 --to 1.31 --feature-gates CronJobTimeZone=true,NodeSwap=true
+
+  # Output the upgrade plan as JSON
+  kube-dependency-checker upgrade --from 1.28 --to 1.30 -o json`,
 	RunE: runUpgrade,
 }
 
 func init() {
 	rootCmd.AddCommand(upgradeCmd)
 	upgradeCmd.Flags().StringVar(&fromVersion, "from", "", "Starting Kubernetes version")
-	upgradeCmd.Flags().StringVar(&toVersion, "to", "", "Target Kubernetes version")
+	upgradeCmd.Flags().StringVar(&toVersion, "to", "", "Target Kubernetes version (default: the latest supported version)")
+	upgradeCmd.Flags().StringVar(&featureGates, "feature-gates", "", "Feature gates set on the components, e.g. NodeSwap=true,SidecarContainers=false")
 	_ = upgradeCmd.MarkFlagRequired("from")
-	_ = upgradeCmd.MarkFlagRequired("to")
 }
 
 func runUpgrade(cmd *cobra.Command, args []string) error {
-	fromVersion = strings.TrimPrefix(fromVersion, "v")
-	toVersion = strings.TrimPrefix(toVersion, "v")
-
-	// Validate versions
-	fromMatrix, ok := compatibility.GetMatrix(fromVersion)
-	if !ok {
-		return fmt.Errorf("unsupported source version: %s", fromVersion)
+	to := toVersion
+	if to == "" {
+		latest := compatibility.Latest()
+		if latest == nil {
+			return fmt.Errorf("no supported Kubernetes versions in the compatibility data")
+		}
+		to = latest.ShortString()
 	}
 
-	toMatrix, ok := compatibility.GetMatrix(toVersion)
-	if !ok {
-		return fmt.Errorf("unsupported target version: %s", toVersion)
+	gates, err := checker.ParseFeatureGates(featureGates)
+	if err != nil {
+		return err
 	}
 
-	// Parse minor versions
-	fromMinor := parseMinorVersion(fromVersion)
-	toMinor := parseMinorVersion(toVersion)
-
-	if fromMinor >= toMinor {
-		return fmt.Errorf("target version must be newer than source version")
+	result, err := checker.PlanUpgrade(fromVersion, to)
+	if err != nil {
+		return err
 	}
+	checker.UpgradeFeatureGateWarnings(result, gates)
 
-	// Generate upgrade path
-	fmt.Printf("\n")
-	fmt.Printf("Upgrade Path: %s → %s\n", fromVersion, toVersion)
-	fmt.Printf("%s\n\n", strings.Repeat("=", 60))
-
-	// Show step-by-step upgrade path
-	fmt.Println("📋 Recommended Upgrade Steps:")
-	fmt.Println(strings.Repeat("-", 60))
-
-	steps := toMinor - fromMinor
-	for i := 0; i < steps; i++ {
-		stepFrom := fmt.Sprintf("1.%d", fromMinor+i)
-		stepTo := fmt.Sprintf("1.%d", fromMinor+i+1)
-		fmt.Printf("  Step %d: %s → %s\n", i+1, stepFrom, stepTo)
+	formatter, err := newFormatter()
+	if err != nil {
+		return err
 	}
-
-	fmt.Println()
-	fmt.Println("⚠️  Note: Kubernetes supports upgrading one minor version at a time.")
-	fmt.Println()
-
-	// Show component changes
-	fmt.Println("📦 Component Version Changes:")
-	fmt.Println(strings.Repeat("-", 60))
-	fmt.Printf("%-25s %-15s %-15s\n", "COMPONENT", fromVersion, toVersion)
-	fmt.Println(strings.Repeat("-", 60))
-
-	// Components to compare
-	components := []string{"etcd", "coredns", "containerd"}
-
-	for _, comp := range components {
-		fromInfo := fromMatrix.Components[comp]
-		toInfo := toMatrix.Components[comp]
-
-		fromVer := fromInfo.Recommended
-		if fromVer == "" {
-			fromVer = fromInfo.Version
-		}
-
-		toVer := toInfo.Recommended
-		if toVer == "" {
-			toVer = toInfo.Version
-		}
-
-		change := ""
-		if fromVer != toVer {
-			change = " ⬆️"
-		}
-
-		fmt.Printf("%-25s %-15s %-15s%s\n", fromInfo.Name, fromVer, toVer, change)
-	}
-
-	fmt.Println()
-
-	// Show skew policy reminders
-	fmt.Println("📌 Version Skew Policy Reminders:")
-	fmt.Println(strings.Repeat("-", 60))
-	fmt.Println("  • Upgrade kube-apiserver first")
-	fmt.Println("  • Then upgrade kube-controller-manager, kube-scheduler")
-	fmt.Println("  • Finally upgrade kubelet on all nodes")
-	fmt.Println("  • kubelet can be up to 3 minor versions older than kube-apiserver")
-	fmt.Println()
-
-	return nil
+	return formatter.FormatUpgrade(result)
 }
-
-func parseMinorVersion(version string) int {
-	parts := strings.Split(version, ".")
-	if len(parts) >= 2 {
-		minor, _ := strconv.Atoi(parts[1])
-		return minor
-	}
-	return 0
-}
-
@@ -2,10 +2,8 @@ package cmd
 
 import (
 	"fmt"
-	"sort"
-	"strings"
 
-	"github.com/pmady/kube-dependency-checker/pkg/compatibility"
+	"github.com/pmady/kube-dependency-checker/pkg/checker"
 	"github.com/spf13/cobra"
 )
 
@@ -27,12 +25,25 @@ Examples:
   # List CoreDNS versions across all supported Kubernetes versions
   kube-dependency-checker versions --component coredns --all
 
+  # Include patch releases that changed the recommended etcd version
+  kube-dependency-checker versions --component etcd --all --patches
+
+  # etcd for a specific patch release
+  kube-dependency-checker versions --component etcd --k8s-version 1.30.2
+
   # List all supported Kubernetes versions
-  kube-dependency-checker versions --list-k8s`,
+  kube-dependency-checker versions --list-k8s
+
+  # Include releases that are out of support, e.g. for upgrade planning
+  kube-dependency-checker versions --component coredns --all --include-archived`,
 	RunE: runVersions,
 }
 
-var listK8s bool
+var (
+	listK8s         bool
+	includePatches  bool
+	includeArchived bool
+)
 
 func init() {
 	rootCmd.AddCommand(versionsCmd)
@@ -40,18 +51,20 @@ func init() {
 	versionsCmd.Flags().StringVar(&versionsK8sVer, "k8s-version", "", "Kubernetes version")
 	versionsCmd.Flags().BoolVar(&showAllVersions, "all", false, "Show versions for all supported Kubernetes versions")
 	versionsCmd.Flags().BoolVar(&listK8s, "list-k8s", false, "List all supported Kubernetes versions")
+	versionsCmd.Flags().BoolVar(&includePatches, "patches", false, "With --all, also show patch releases that change the component")
+	versionsCmd.Flags().BoolVar(&includeArchived, "include-archived", false, "With --all or --list-k8s, also show archived releases that are out of support")
 }
 
 func runVersions(cmd *cobra.Command, args []string) error {
+	formatter, err := newFormatter()
+	if err != nil {
+		return err
+	}
+	opts := checker.VersionsOptions{Patches: includePatches, Archived: includeArchived}
+
 	// List supported K8s versions
 	if listK8s {
-		versions := compatibility.GetSupportedVersions()
-		sort.Sort(sort.Reverse(sort.StringSlice(versions)))
-		fmt.Println("Supported Kubernetes versions:")
-		for _, v := range versions {
-			fmt.Printf("  - %s\n", v)
-		}
-		return nil
+		return formatter.FormatVersions(checker.ListK8sVersions(opts))
 	}
 
 	// Validate component flag
@@ -59,11 +72,13 @@ func runVersions(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("--component flag is required")
 	}
 
-	componentName = strings.ToLower(componentName)
-
 	// Show versions for all K8s versions
 	if showAllVersions {
-		return showAllK8sVersions(componentName)
+		result, err := checker.ComponentVersions(componentName, opts)
+		if err != nil {
+			return err
+		}
+		return formatter.FormatVersions(result)
 	}
 
 	// Show version for specific K8s version
@@ -71,67 +86,9 @@ func runVersions(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("either --k8s-version or --all flag is required")
 	}
 
-	versionsK8sVer = strings.TrimPrefix(versionsK8sVer, "v")
-
-	info, ok := compatibility.GetComponentInfo(versionsK8sVer, componentName)
-	if !ok {
-		return fmt.Errorf("component '%s' not found for Kubernetes %s", componentName, versionsK8sVer)
-	}
-
-	fmt.Printf("\n%s compatibility for Kubernetes %s:\n", info.Name, versionsK8sVer)
-	fmt.Println(strings.Repeat("-", 50))
-
-	if info.Version != "" {
-		fmt.Printf("  Version:     %s\n", info.Version)
-	}
-	if info.Recommended != "" {
-		fmt.Printf("  Recommended: %s\n", info.Recommended)
-	}
-	if info.MinVersion != "" {
-		fmt.Printf("  Min Version: %s\n", info.MinVersion)
-	}
-	if info.MaxVersion != "" {
-		fmt.Printf("  Max Version: %s\n", info.MaxVersion)
-	}
-	if info.SkewPolicy != "" {
-		fmt.Printf("  Skew Policy: %s\n", info.SkewPolicy)
-	}
-	if info.Notes != "" {
-		fmt.Printf("  Notes:       %s\n", info.Notes)
-	}
-	fmt.Println()
-
-	return nil
-}
-
-func showAllK8sVersions(component string) error {
-	versions := compatibility.GetSupportedVersions()
-	sort.Sort(sort.Reverse(sort.StringSlice(versions)))
-
-	fmt.Printf("\n%s versions across Kubernetes releases:\n", strings.ToUpper(component[:1])+component[1:])
-	fmt.Println(strings.Repeat("-", 60))
-	fmt.Printf("%-15s %-15s %-15s\n", "K8S VERSION", "VERSION", "RECOMMENDED")
-	fmt.Println(strings.Repeat("-", 60))
-
-	for _, k8sVer := range versions {
-		info, ok := compatibility.GetComponentInfo(k8sVer, component)
-		if !ok {
-			continue
-		}
-
-		version := info.Version
-		if version == "" && info.SkewPolicy != "" {
-			version = "(skew policy)"
-		}
-
-		recommended := info.Recommended
-		if recommended == "" {
-			recommended = "-"
-		}
-
-		fmt.Printf("%-15s %-15s %-15s\n", k8sVer, version, recommended)
+	result, err := checker.ComponentVersion(componentName, versionsK8sVer)
+	if err != nil {
+		return err
 	}
-	fmt.Println()
-
-	return nil
+	return formatter.FormatVersions(result)
 }
@@ -0,0 +1,372 @@
+// Package bundle downloads compatibility data bundles and keeps them in a
+// local cache, so that newer data can be used without a new release and
+// the last downloaded data remains available offline.
+//
+// Each source URL has one cache entry: the bundle file, its detached
+// signature and a metadata file holding the ETag used for conditional
+// requests. Bundles must be signed by a trusted key, both when they are
+// downloaded and every time they are loaded from the cache.
+package bundle
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pmady/kube-dependency-checker/pkg/compatibility"
+)
+
+// DefaultURL is where "data update" fetches bundles from by default
+const DefaultURL = "https://raw.githubusercontent.com/pmady/kube-dependency-checker/main/pkg/compatibility/data/matrix.yaml"
+
+// MaxSize is the largest bundle that will be downloaded
+const MaxSize = 16 << 20
+
+const (
+	bundleExt   = ".yaml"
+	metadataExt = ".json"
+)
+
+// DefaultCacheDir returns the per-user cache directory for bundles
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "kube-dependency-checker", "bundles"), nil
+}
+
+// Metadata describes a cached bundle
+type Metadata struct {
+	URL       string    `json:"url"`
+	ETag      string    `json:"etag,omitempty"`
+	Fetched   time.Time `json:"fetched"`
+	Generated time.Time `json:"generated"`
+	SHA256    string    `json:"sha256"`
+}
+
+// Cached is a bundle in the cache
+type Cached struct {
+	Path     string
+	Metadata Metadata
+	Bundle   *compatibility.Bundle
+	// KeyID identifies the key that signed the bundle; it is empty for a
+	// bundle accepted without verification in insecure mode
+	KeyID string
+}
+
+// Cache is a directory of downloaded bundles
+type Cache struct {
+	Dir string
+	// Verifier checks bundle signatures; nil trusts no keys
+	Verifier *Verifier
+}
+
+func (c *Cache) verifier() *Verifier {
+	if c.Verifier == nil {
+		return &Verifier{}
+	}
+	return c.Verifier
+}
+
+// key names the cache entry of a URL
+func key(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(sum[:8])
+}
+
+func (c *Cache) paths(url string) (bundlePath, metadataPath string) {
+	base := filepath.Join(c.Dir, key(url))
+	return base + bundleExt, base + metadataExt
+}
+
+// readSignature reads the detached signature of a file, returning nil if
+// there is none
+func readSignature(path string) ([]byte, error) {
+	sig, err := os.ReadFile(path + SignatureExt)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return sig, err
+}
+
+// Get returns the cached bundle for a URL, or an error if there is none or
+// it is no longer valid
+func (c *Cache) Get(url string) (*Cached, error) {
+	bundlePath, metadataPath := c.paths(url)
+	return c.load(bundlePath, metadataPath)
+}
+
+func (c *Cache) load(bundlePath, metadataPath string) (*Cached, error) {
+	var meta Metadata
+	data, err := os.ReadFile(metadataPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("%s: %w", metadataPath, err)
+	}
+
+	data, err = os.ReadFile(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) != meta.SHA256 {
+		return nil, fmt.Errorf("%s: checksum does not match metadata", bundlePath)
+	}
+	sig, err := readSignature(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	keyID, err := c.verifier().check(data, sig)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", bundlePath, err)
+	}
+	b, err := compatibility.ParseBundle(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", bundlePath, err)
+	}
+	b.Source = bundlePath
+	return &Cached{Path: bundlePath, Metadata: meta, Bundle: b, KeyID: keyID}, nil
+}
+
+// List returns the valid cached bundles, newest first, and the errors of
+// entries that could not be loaded. A missing cache directory is empty.
+func (c *Cache) List() ([]*Cached, []error) {
+	entries, err := os.ReadDir(c.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	var (
+		bundles []*Cached
+		errs    []error
+	)
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, bundleExt) {
+			continue
+		}
+		base := filepath.Join(c.Dir, strings.TrimSuffix(name, bundleExt))
+		cached, err := c.load(base+bundleExt, base+metadataExt)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		bundles = append(bundles, cached)
+	}
+	sort.SliceStable(bundles, func(i, j int) bool {
+		return bundles[i].Bundle.NewerThan(bundles[j].Bundle)
+	})
+	return bundles, errs
+}
+
+// Newest returns the newest valid cached bundle if it is newer than the
+// embedded data, and the embedded bundle otherwise. Errors describe cache
+// entries that were skipped.
+func (c *Cache) Newest() (*compatibility.Bundle, []error) {
+	cached, errs := c.NewestCached()
+	if cached == nil {
+		return compatibility.Embedded(), errs
+	}
+	return cached.Bundle, errs
+}
+
+// NewestCached returns the newest valid cached bundle if it is newer than
+// the embedded data, and nil otherwise. Errors describe cache entries that
+// were skipped.
+func (c *Cache) NewestCached() (*Cached, []error) {
+	bundles, errs := c.List()
+	if len(bundles) > 0 && bundles[0].Bundle.NewerThan(compatibility.Embedded()) {
+		return bundles[0], errs
+	}
+	return nil, errs
+}
+
+// put writes a bundle, its signature and its metadata, replacing any
+// previous entry
+func (c *Cache) put(data, sig []byte, meta Metadata) (string, error) {
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return "", err
+	}
+	bundlePath, metadataPath := c.paths(meta.URL)
+	metaData, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	// The bundle is written first: a bundle without matching metadata fails
+	// its checksum and is ignored rather than half-used
+	if err := writeFile(bundlePath, data); err != nil {
+		return "", err
+	}
+	if sig != nil {
+		err = writeFile(bundlePath+SignatureExt, sig)
+	} else {
+		err = os.Remove(bundlePath + SignatureExt)
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	if err := writeFile(metadataPath, metaData); err != nil {
+		return "", err
+	}
+	return bundlePath, nil
+}
+
+// writeFile replaces a file atomically
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// UpdateResult describes the outcome of an update
+type UpdateResult struct {
+	URL string `json:"url" yaml:"url"`
+	// Path is the cached bundle file
+	Path string `json:"path" yaml:"path"`
+	// Updated is false when the server reported the cached bundle is current
+	Updated   bool      `json:"updated" yaml:"updated"`
+	Generated time.Time `json:"generated" yaml:"generated"`
+	Versions  int       `json:"versions" yaml:"versions"`
+	// KeyID identifies the key that signed the bundle
+	KeyID string `json:"keyId,omitempty" yaml:"keyId,omitempty"`
+}
+
+// Updater fetches bundles into a cache
+type Updater struct {
+	Cache  *Cache
+	Client *http.Client
+	// Now returns the current time; defaults to time.Now
+	Now func() time.Time
+}
+
+// Update fetches the bundle at url and its signature at url + ".sig",
+// sending the cached ETag so an unchanged bundle is not downloaded again.
+// Invalid, unsigned or tampered bundles are rejected and leave the cache
+// untouched.
+func (u *Updater) Update(ctx context.Context, url string) (*UpdateResult, error) {
+	client := u.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	now := time.Now
+	if u.Now != nil {
+		now = u.Now
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	cached, _ := u.Cache.Get(url)
+	if cached != nil && cached.Metadata.ETag != "" {
+		req.Header.Set("If-None-Match", cached.Metadata.ETag)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		return &UpdateResult{
+			URL:       url,
+			Path:      cached.Path,
+			Generated: cached.Bundle.Generated,
+			Versions:  len(cached.Bundle.Matrices),
+			KeyID:     cached.KeyID,
+		}, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("fetching %s: %s", url, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", url, err)
+	}
+	if len(data) > MaxSize {
+		return nil, fmt.Errorf("fetching %s: bundle is larger than %d bytes", url, MaxSize)
+	}
+	sig, err := u.fetchSignature(ctx, client, url+SignatureExt)
+	if err != nil {
+		return nil, err
+	}
+	keyID, err := u.Cache.verifier().check(data, sig)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", url, err)
+	}
+	b, err := compatibility.ParseBundle(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", url, err)
+	}
+
+	sum := sha256.Sum256(data)
+	path, err := u.Cache.put(data, sig, Metadata{
+		URL:       url,
+		ETag:      resp.Header.Get("ETag"),
+		Fetched:   now().UTC(),
+		Generated: b.Generated,
+		SHA256:    hex.EncodeToString(sum[:]),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &UpdateResult{
+		URL:       url,
+		Path:      path,
+		Updated:   true,
+		Generated: b.Generated,
+		Versions:  len(b.Matrices),
+		KeyID:     keyID,
+	}, nil
+}
+
+// fetchSignature downloads a detached signature, returning nil if the
+// server has none
+func (u *Updater) fetchSignature(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("fetching %s: %s", url, resp.Status)
+	}
+	// Signatures are a single line of base64
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+}
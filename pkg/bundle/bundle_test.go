@@ -0,0 +1,262 @@
+package bundle
+
+import (
+	"context"
+	"crypto/ed25519"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pmady/kube-dependency-checker/pkg/compatibility"
+)
+
+const testBundle = `
+generated: 2099-01-01T00:00:00Z
+matrices:
+  "1.99":
+    k8sVersion: "1.99"
+    components:
+      etcd:
+        name: etcd
+        minVersion: 3.6.0
+        maxVersion: 3.6.99
+        recommended: 3.6.4
+`
+
+// testKey signs the bundles served by testServer
+var testKey = ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+
+func testVerifier() *Verifier {
+	return &Verifier{Keys: []ed25519.PublicKey{testKey.Public().(ed25519.PublicKey)}}
+}
+
+// testServer serves body signed with testKey and an ETag, answers
+// conditional requests and counts full downloads
+func testServer(t *testing.T, body string) (*httptest.Server, *int) {
+	t.Helper()
+	return testSignedServer(t, body, Sign([]byte(body), testKey))
+}
+
+// testSignedServer serves body with the given signature, or none if nil
+func testSignedServer(t *testing.T, body string, sig []byte) (*httptest.Server, *int) {
+	t.Helper()
+	downloads := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, SignatureExt) {
+			if sig == nil {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write(sig)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads++
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &downloads
+}
+
+func TestUpdate(t *testing.T) {
+	srv, downloads := testServer(t, testBundle)
+	cache := &Cache{Dir: t.TempDir(), Verifier: testVerifier()}
+	u := &Updater{Cache: cache, Client: srv.Client()}
+
+	result, err := u.Update(context.Background(), srv.URL+"/matrix.yaml")
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if !result.Updated || result.Versions != 1 || result.KeyID == "" {
+		t.Errorf("Update() = %+v, want an updated bundle with 1 version", result)
+	}
+
+	// The second update sends the ETag and keeps the cached bundle
+	result, err = u.Update(context.Background(), srv.URL+"/matrix.yaml")
+	if err != nil {
+		t.Fatalf("second Update() error = %v", err)
+	}
+	if result.Updated {
+		t.Error("second Update() reported an update, want not modified")
+	}
+	if *downloads != 1 {
+		t.Errorf("downloads = %d, want 1", *downloads)
+	}
+
+	cached, err := cache.Get(srv.URL + "/matrix.yaml")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if cached.Metadata.ETag != `"v1"` {
+		t.Errorf("cached ETag = %q", cached.Metadata.ETag)
+	}
+}
+
+func TestUpdateRejectsInvalidBundle(t *testing.T) {
+	srv, _ := testServer(t, "matrices: {}\n")
+	cache := &Cache{Dir: t.TempDir(), Verifier: testVerifier()}
+	u := &Updater{Cache: cache, Client: srv.Client()}
+
+	if _, err := u.Update(context.Background(), srv.URL+"/matrix.yaml"); err == nil {
+		t.Fatal("Update() expected error for a bundle without data")
+	}
+	if bundles, _ := cache.List(); len(bundles) != 0 {
+		t.Errorf("cache has %d bundles after a rejected update, want 0", len(bundles))
+	}
+}
+
+func TestUpdateServerError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	u := &Updater{Cache: &Cache{Dir: t.TempDir()}, Client: srv.Client()}
+
+	if _, err := u.Update(context.Background(), srv.URL+"/matrix.yaml"); err == nil {
+		t.Fatal("Update() expected error for 404")
+	}
+}
+
+func TestNewest(t *testing.T) {
+	// An empty or missing cache falls back to the embedded data
+	cache := &Cache{Dir: t.TempDir() + "/missing"}
+	if b, errs := cache.Newest(); b != compatibility.Embedded() || len(errs) != 0 {
+		t.Errorf("Newest() on empty cache = %v, %v; want embedded bundle", b.Source, errs)
+	}
+
+	srv, _ := testServer(t, testBundle)
+	cache = &Cache{Dir: t.TempDir(), Verifier: testVerifier()}
+	u := &Updater{Cache: cache, Client: srv.Client(), Now: func() time.Time { return time.Unix(0, 0) }}
+	result, err := u.Update(context.Background(), srv.URL+"/matrix.yaml")
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	b, _ := cache.Newest()
+	if b.Source != result.Path {
+		t.Errorf("Newest() source = %s, want %s", b.Source, result.Path)
+	}
+	if _, ok := b.Matrices["1.99"]; !ok {
+		t.Error("Newest() did not return the downloaded bundle")
+	}
+
+	// A tampered bundle fails its checksum and signature and is skipped
+	if err := os.WriteFile(result.Path, []byte(testBundle+"\n# edited\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	b, errs := cache.Newest()
+	if b != compatibility.Embedded() || len(errs) != 1 {
+		t.Errorf("Newest() with a tampered bundle = %s, %v; want embedded and 1 error", b.Source, errs)
+	}
+}
+
+func TestNewestPrefersEmbeddedWhenCacheIsOlder(t *testing.T) {
+	old := "generated: 2000-01-01T00:00:00Z\nmatrices:\n  \"1.20\":\n    k8sVersion: \"1.20\"\n    components:\n      etcd: {name: etcd}\n"
+	srv, _ := testServer(t, old)
+	cache := &Cache{Dir: t.TempDir(), Verifier: testVerifier()}
+	u := &Updater{Cache: cache, Client: srv.Client()}
+	if _, err := u.Update(context.Background(), srv.URL+"/matrix.yaml"); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	if b, _ := cache.Newest(); b != compatibility.Embedded() {
+		t.Errorf("Newest() = %s, want embedded", b.Source)
+	}
+}
+
+func TestUpdateRequiresSignature(t *testing.T) {
+	otherKey := ed25519.NewKeyFromSeed([]byte("0123456789abcdef0123456789abcdef"))
+	tests := []struct {
+		name string
+		sig  []byte
+	}{
+		{"unsigned", nil},
+		{"untrusted key", Sign([]byte(testBundle), otherKey)},
+		{"tampered", Sign([]byte(testBundle+"# edited\n"), testKey)},
+		{"malformed", []byte("not a signature\n")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, _ := testSignedServer(t, testBundle, tt.sig)
+			cache := &Cache{Dir: t.TempDir(), Verifier: testVerifier()}
+			u := &Updater{Cache: cache, Client: srv.Client()}
+			if _, err := u.Update(context.Background(), srv.URL+"/matrix.yaml"); err == nil {
+				t.Fatal("Update() expected error")
+			}
+			if bundles, _ := cache.List(); len(bundles) != 0 {
+				t.Errorf("cache has %d bundles, want 0", len(bundles))
+			}
+
+			// --insecure-data accepts the bundle anyway
+			cache.Verifier.Insecure = true
+			if _, err := u.Update(context.Background(), srv.URL+"/matrix.yaml"); err != nil {
+				t.Fatalf("insecure Update() error = %v", err)
+			}
+			if b, _ := cache.Newest(); b.Source == compatibility.SourceEmbedded {
+				t.Error("insecure Newest() did not use the downloaded bundle")
+			}
+
+			// It is refused again once verification is back on
+			cache.Verifier.Insecure = false
+			if b, errs := cache.Newest(); b != compatibility.Embedded() || len(errs) != 1 {
+				t.Errorf("Newest() = %s, %v; want embedded and 1 error", b.Source, errs)
+			}
+		})
+	}
+}
+
+func TestVerifyFile(t *testing.T) {
+	path := t.TempDir() + "/matrix.yaml"
+	if err := os.WriteFile(path, []byte(testBundle), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	v := testVerifier()
+	if _, _, err := v.VerifyFile(path); err != ErrUnsigned {
+		t.Errorf("VerifyFile() unsigned error = %v, want ErrUnsigned", err)
+	}
+
+	if err := os.WriteFile(path+SignatureExt, Sign([]byte(testBundle), testKey), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	b, keyID, err := v.VerifyFile(path)
+	if err != nil {
+		t.Fatalf("VerifyFile() error = %v", err)
+	}
+	if keyID != KeyID(v.Keys[0]) || len(b.Matrices) != 1 {
+		t.Errorf("VerifyFile() = %d matrices, key %s", len(b.Matrices), keyID)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	public, private, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := ParsePublicKey(public)
+	if err != nil {
+		t.Fatalf("ParsePublicKey() error = %v", err)
+	}
+	priv, err := ParsePrivateKey(private)
+	if err != nil {
+		t.Fatalf("ParsePrivateKey() error = %v", err)
+	}
+	v := &Verifier{Keys: []ed25519.PublicKey{pub}}
+	if _, err := v.Verify([]byte("data"), Sign([]byte("data"), priv)); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+
+	TrustedKeys = public + ", " + public
+	defer func() { TrustedKeys = "" }()
+	keys, err := PinnedKeys()
+	if err != nil || len(keys) != 2 {
+		t.Errorf("PinnedKeys() = %d keys, %v", len(keys), err)
+	}
+	if _, err := ParsePublicKey("c2hvcnQ="); err == nil {
+		t.Error("ParsePublicKey() accepted a short key")
+	}
+}
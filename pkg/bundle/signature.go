@@ -0,0 +1,152 @@
+package bundle
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pmady/kube-dependency-checker/pkg/compatibility"
+)
+
+// TrustedKeys is a comma-separated list of base64 ed25519 public keys that
+// bundles must be signed with. It is pinned at build time:
+//
+//	-ldflags "-X github.com/pmady/kube-dependency-checker/pkg/bundle.TrustedKeys=<key>"
+var TrustedKeys = ""
+
+// SignatureExt is appended to a bundle's path or URL to find its detached
+// signature
+const SignatureExt = ".sig"
+
+// ErrUnsigned is returned when a bundle has no signature
+var ErrUnsigned = errors.New("bundle is not signed")
+
+// Verifier checks bundle signatures against trusted keys
+type Verifier struct {
+	Keys []ed25519.PublicKey
+	// Insecure accepts unsigned bundles and bundles whose signature does not
+	// verify
+	Insecure bool
+}
+
+// PinnedKeys parses the keys pinned in TrustedKeys
+func PinnedKeys() ([]ed25519.PublicKey, error) {
+	keys := make([]ed25519.PublicKey, 0)
+	for _, s := range strings.Split(TrustedKeys, ",") {
+		if strings.TrimSpace(s) == "" {
+			continue
+		}
+		key, err := ParsePublicKey(s)
+		if err != nil {
+			return nil, fmt.Errorf("pinned key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// ParsePublicKey parses a base64 ed25519 public key
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(data) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid ed25519 public key %q", strings.TrimSpace(s))
+	}
+	return ed25519.PublicKey(data), nil
+}
+
+// ParsePrivateKey parses a base64 ed25519 private key or seed
+func ParsePrivateKey(s string) (ed25519.PrivateKey, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ed25519 private key")
+	}
+	switch len(data) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(data), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(data), nil
+	}
+	return nil, fmt.Errorf("invalid ed25519 private key")
+}
+
+// GenerateKey creates a key pair, returned in the base64 text form read by
+// ParsePublicKey and ParsePrivateKey
+func GenerateKey() (public, private string, err error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.StdEncoding.EncodeToString(pub), base64.StdEncoding.EncodeToString(priv.Seed()), nil
+}
+
+// KeyID is a short fingerprint of a public key
+func KeyID(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:4])
+}
+
+// Sign returns the detached signature of a bundle
+func Sign(data []byte, key ed25519.PrivateKey) []byte {
+	sig := ed25519.Sign(key, data)
+	return []byte(base64.StdEncoding.EncodeToString(sig) + "\n")
+}
+
+// Verify checks a detached signature and returns the ID of the key that
+// signed the bundle. A nil signature means the bundle is unsigned.
+func (v *Verifier) Verify(data, signature []byte) (string, error) {
+	if signature == nil {
+		return "", ErrUnsigned
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return "", fmt.Errorf("malformed signature")
+	}
+	if len(v.Keys) == 0 {
+		return "", fmt.Errorf("no trusted keys to verify the signature with")
+	}
+	for _, key := range v.Keys {
+		if ed25519.Verify(key, data, sig) {
+			return KeyID(key), nil
+		}
+	}
+	return "", fmt.Errorf("signature does not match any trusted key")
+}
+
+// check verifies a bundle, accepting it anyway in insecure mode
+func (v *Verifier) check(data, signature []byte) (string, error) {
+	keyID, err := v.Verify(data, signature)
+	if err != nil && v.Insecure {
+		return "", nil
+	}
+	return keyID, err
+}
+
+// VerifyFile verifies a bundle file against its detached signature at
+// path + ".sig" and checks that it is a valid bundle. Insecure mode is
+// ignored: the result always reflects the signature.
+func (v *Verifier) VerifyFile(path string) (*compatibility.Bundle, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	sig, err := readSignature(path)
+	if err != nil {
+		return nil, "", err
+	}
+	keyID, err := v.Verify(data, sig)
+	if err != nil {
+		return nil, "", err
+	}
+	b, err := compatibility.ParseBundle(data)
+	if err != nil {
+		return nil, "", err
+	}
+	b.Source = path
+	return b, keyID, nil
+}
@@ -0,0 +1,323 @@
+// Package checker builds compatibility reports from the compatibility matrix.
+// It is shared by the CLI commands and the HTTP API so both return the same
+// results.
+package checker
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pmady/kube-dependency-checker/pkg/compatibility"
+	"github.com/pmady/kube-dependency-checker/pkg/output"
+	"github.com/pmady/kube-dependency-checker/pkg/version"
+)
+
+// ErrUnsupportedVersion is returned when a Kubernetes version is not in the matrix
+var ErrUnsupportedVersion = errors.New("unsupported Kubernetes version")
+
+// ComponentOrder defines the component order for consistent output
+var ComponentOrder = []string{
+	"etcd",
+	"coredns",
+	"containerd",
+	"kubelet",
+	"kube-proxy",
+	"kube-controller-manager",
+	"kube-scheduler",
+	"kubectl",
+}
+
+// UpgradeComponents are the components compared in an upgrade plan
+var UpgradeComponents = []string{"etcd", "coredns", "containerd"}
+
+// Check builds the compatibility result for a Kubernetes version
+func Check(k8sVersion string) (*output.CheckResult, error) {
+	k8sVersion = cleanK8sVersion(k8sVersion)
+
+	matrix, ok := compatibility.GetMatrix(k8sVersion)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s\nSupported versions: %s",
+			ErrUnsupportedVersion, k8sVersion, strings.Join(supportedVersions(), ", "))
+	}
+
+	result := &output.CheckResult{
+		K8sVersion: k8sVersion,
+		Components: make([]output.ComponentResult, 0),
+		Warnings:   supportWarnings(matrix),
+	}
+
+	for _, compName := range ComponentOrder {
+		info, exists := matrix.Components[compName]
+		if !exists {
+			continue
+		}
+		result.Components = append(result.Components, newComponentResult(info))
+	}
+
+	result.Summary = output.Summary{
+		TotalComponents:      len(result.Components),
+		CompatibleComponents: len(result.Components),
+		IncompatibleCount:    0,
+		UnknownCount:         0,
+	}
+
+	return result, nil
+}
+
+// cleanK8sVersion strips the "v" prefix and any vendor suffix from a
+// Kubernetes version, e.g. v1.30.2-eks-1 becomes 1.30.2
+func cleanK8sVersion(k8sVersion string) string {
+	if clean, ok := version.Clean(k8sVersion); ok {
+		return clean
+	}
+	return strings.TrimPrefix(k8sVersion, "v")
+}
+
+func newComponentResult(info compatibility.ComponentInfo) output.ComponentResult {
+	compResult := output.ComponentResult{
+		Name:        info.Name,
+		Required:    info.Version,
+		Recommended: info.Recommended,
+		Status:      StatusCompatible, // Default to compatible when showing requirements
+		SkewPolicy:  info.SkewPolicy,
+		Notes:       info.Notes,
+	}
+
+	// For skew policy components, show the policy instead of version
+	if info.SkewPolicy != "" {
+		compResult.Required = ""
+	}
+
+	return compResult
+}
+
+// PlanUpgrade builds the upgrade plan between two Kubernetes versions
+func PlanUpgrade(from, to string) (*output.UpgradeResult, error) {
+	from = cleanK8sVersion(from)
+	to = cleanK8sVersion(to)
+
+	fromMatrix, ok := compatibility.GetMatrix(from)
+	if !ok {
+		return nil, fmt.Errorf("unsupported source version: %s", from)
+	}
+
+	toMatrix, ok := compatibility.GetMatrix(to)
+	if !ok {
+		return nil, fmt.Errorf("unsupported target version: %s", to)
+	}
+
+	fromVersion, err := version.Parse(from)
+	if err != nil {
+		return nil, err
+	}
+	toVersion, err := version.Parse(to)
+	if err != nil {
+		return nil, err
+	}
+	if fromVersion.Major > toVersion.Major ||
+		fromVersion.Major == toVersion.Major && fromVersion.Minor >= toVersion.Minor {
+		return nil, fmt.Errorf("target version must be newer than source version")
+	}
+
+	result := &output.UpgradeResult{
+		From:       from,
+		To:         to,
+		Steps:      make([]output.UpgradeStep, 0),
+		Components: make([]output.ComponentChange, 0),
+	}
+
+	// The path is built from the releases in the data, one minor version at
+	// a time. Releases on the path that are out of support are warned about,
+	// but do not stop the plan.
+	result.Warnings = supportWarnings(fromMatrix)
+	path := compatibility.Range(fromVersion, toVersion)
+	for i := 1; i < len(path); i++ {
+		prev, next := path[i-1], path[i]
+		if !isNextMinor(prev, next) {
+			return nil, fmt.Errorf("%w: no compatibility data between Kubernetes %s and %s",
+				ErrUnsupportedVersion, prev.ShortString(), next.ShortString())
+		}
+		result.Steps = append(result.Steps, output.UpgradeStep{
+			Step: i,
+			From: prev.ShortString(),
+			To:   next.ShortString(),
+		})
+		if m, ok := compatibility.GetMatrix(next.ShortString()); ok {
+			result.Warnings = append(result.Warnings, supportWarnings(m)...)
+		}
+	}
+
+	for _, comp := range UpgradeComponents {
+		fromInfo := fromMatrix.Components[comp]
+		toInfo := toMatrix.Components[comp]
+
+		fromVer := fromInfo.Recommended
+		if fromVer == "" {
+			fromVer = fromInfo.Version
+		}
+
+		toVer := toInfo.Recommended
+		if toVer == "" {
+			toVer = toInfo.Version
+		}
+
+		result.Components = append(result.Components, output.ComponentChange{
+			Name:    fromInfo.Name,
+			From:    fromVer,
+			To:      toVer,
+			Changed: fromVer != toVer,
+		})
+	}
+
+	return result, nil
+}
+
+// ListK8sVersions returns the supported Kubernetes versions, newest first.
+// With opts.Archived the releases that are out of support are listed apart
+// in ArchivedVersions.
+func ListK8sVersions(opts VersionsOptions) *output.VersionsResult {
+	result := &output.VersionsResult{K8sVersions: supportedVersions()}
+	if opts.Archived {
+		result.ArchivedVersions = newestFirst(compatibility.GetArchivedVersions())
+	}
+	for _, v := range result.K8sVersions {
+		if m, ok := compatibility.GetMatrix(v); ok {
+			result.Warnings = append(result.Warnings, supportWarnings(m)...)
+		}
+	}
+	return result
+}
+
+// ComponentVersion returns a component's version data for one Kubernetes version
+func ComponentVersion(component, k8sVersion string) (*output.VersionsResult, error) {
+	component = strings.ToLower(component)
+	k8sVersion = strings.TrimPrefix(k8sVersion, "v")
+
+	info, ok := compatibility.GetComponentInfo(k8sVersion, component)
+	if !ok {
+		return nil, fmt.Errorf("component '%s' not found for Kubernetes %s", component, k8sVersion)
+	}
+
+	result := &output.VersionsResult{
+		Component:  component,
+		K8sVersion: k8sVersion,
+		Entries:    []output.VersionEntry{newVersionEntry(k8sVersion, info)},
+	}
+	if m, ok := compatibility.GetMatrix(k8sVersion); ok {
+		result.Warnings = supportWarnings(m)
+	}
+	return result, nil
+}
+
+// VersionsOptions select the entries returned by ComponentVersions
+type VersionsOptions struct {
+	// Patches adds an entry for every patch release that changes the
+	// component, before the entry of its minor version
+	Patches bool
+	// Archived includes releases that are out of support
+	Archived bool
+}
+
+// ComponentVersions returns a component's version data across all supported
+// Kubernetes versions, and with opts.Archived the archived ones, newest first
+func ComponentVersions(component string, opts VersionsOptions) (*output.VersionsResult, error) {
+	component = strings.ToLower(component)
+	if component == "" {
+		return nil, fmt.Errorf("component name is required")
+	}
+
+	result := &output.VersionsResult{
+		Component: component,
+		Entries:   make([]output.VersionEntry, 0),
+	}
+
+	for _, k8sVer := range k8sVersions(opts) {
+		if opts.Patches {
+			result.Entries = append(result.Entries, patchEntries(k8sVer, component)...)
+		}
+		info, ok := compatibility.GetComponentInfo(k8sVer, component)
+		if !ok {
+			continue
+		}
+		result.Entries = append(result.Entries, newVersionEntry(k8sVer, info))
+		if m, ok := compatibility.GetMatrix(k8sVer); ok {
+			result.Warnings = append(result.Warnings, supportWarnings(m)...)
+		}
+	}
+
+	return result, nil
+}
+
+// patchEntries returns the component as of each patch release of a minor
+// version that changes it, newest first
+func patchEntries(minor, component string) []output.VersionEntry {
+	matrix, ok := compatibility.GetMatrix(minor)
+	if !ok {
+		return nil
+	}
+	patches := matrix.PatchVersions()
+	entries := make([]output.VersionEntry, 0)
+	for i := len(patches) - 1; i >= 0; i-- {
+		if _, changed := matrix.Patches[patches[i]].Components[component]; !changed {
+			continue
+		}
+		if info, ok := compatibility.GetComponentInfo(patches[i], component); ok {
+			entries = append(entries, newVersionEntry(patches[i], info))
+		}
+	}
+	return entries
+}
+
+func newVersionEntry(k8sVersion string, info *compatibility.ComponentInfo) output.VersionEntry {
+	return output.VersionEntry{
+		K8sVersion:  k8sVersion,
+		Name:        info.Name,
+		Version:     info.Version,
+		Recommended: info.Recommended,
+		MinVersion:  info.MinVersion,
+		MaxVersion:  info.MaxVersion,
+		SkewPolicy:  info.SkewPolicy,
+		Notes:       info.Notes,
+	}
+}
+
+// supportedVersions returns the supported versions, newest first
+func supportedVersions() []string {
+	return newestFirst(compatibility.GetSupportedVersions())
+}
+
+// k8sVersions returns the supported versions and, with opts.Archived, the
+// archived ones after them, newest first
+func k8sVersions(opts VersionsOptions) []string {
+	versions := supportedVersions()
+	if opts.Archived {
+		versions = append(versions, newestFirst(compatibility.GetArchivedVersions())...)
+	}
+	return versions
+}
+
+func newestFirst(versions []*version.Version) []string {
+	names := make([]string, len(versions))
+	for i, v := range versions {
+		names[len(versions)-1-i] = v.ShortString()
+	}
+	return names
+}
+
+// isNextMinor reports whether next is the release that follows prev: the
+// next minor version, or the first minor of the next major version
+func isNextMinor(prev, next *version.Version) bool {
+	if next.Major == prev.Major {
+		return next.Minor == prev.Minor+1
+	}
+	return next.Major == prev.Major+1 && next.Minor == 0
+}
+
+// supportWarnings returns the out-of-support warning of a release, if any
+func supportWarnings(matrix *compatibility.K8sVersionMatrix) []string {
+	if w := matrix.SupportWarning(); w != "" {
+		return []string{w}
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package checker
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pmady/kube-dependency-checker/pkg/compatibility"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		k8sVersion string
+		wantErr    bool
+	}{
+		{"valid version", "1.30", false},
+		{"version with v prefix", "v1.30", false},
+		{"patch version", "1.30.2", false},
+		{"unsupported version", "1.20", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Check(tt.k8sVersion)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnsupportedVersion) {
+					t.Errorf("Check() error = %v, want ErrUnsupportedVersion", err)
+				}
+				return
+			}
+			if len(result.Components) != len(ComponentOrder) {
+				t.Errorf("Check() returned %d components, want %d", len(result.Components), len(ComponentOrder))
+			}
+			if result.Components[0].Name != "etcd" {
+				t.Errorf("first component = %s, want etcd", result.Components[0].Name)
+			}
+		})
+	}
+}
+
+func TestPlanUpgrade(t *testing.T) {
+	tests := []struct {
+		name      string
+		from      string
+		to        string
+		wantSteps int
+		wantErr   bool
+	}{
+		{"one step", "1.29", "1.30", 1, false},
+		{"multiple steps", "1.28", "1.31", 3, false},
+		{"same version", "1.30", "1.30", 0, true},
+		{"downgrade", "1.31", "1.30", 0, true},
+		{"archived source", "1.25", "1.28", 3, false},
+		{"vendor builds", "v1.29.3-eks-1", "1.30.2-gke.1", 1, false},
+		{"unsupported source", "1.20", "1.30", 0, true},
+		{"unsupported target", "1.30", "1.40", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := PlanUpgrade(tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PlanUpgrade() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(result.Steps) != tt.wantSteps {
+				t.Errorf("PlanUpgrade() steps = %d, want %d", len(result.Steps), tt.wantSteps)
+			}
+			if len(result.Components) != len(UpgradeComponents) {
+				t.Errorf("PlanUpgrade() components = %d, want %d", len(result.Components), len(UpgradeComponents))
+			}
+		})
+	}
+}
+
+func TestPlanUpgradeMissingMinor(t *testing.T) {
+	embedded := compatibility.Embedded()
+	b := &compatibility.Bundle{Generated: embedded.Generated, Matrices: make(map[string]compatibility.K8sVersionMatrix)}
+	for k, m := range embedded.Matrices {
+		if k != "1.30" {
+			b.Matrices[k] = m
+		}
+	}
+	compatibility.Use(b)
+	t.Cleanup(func() { compatibility.Use(nil) })
+
+	_, err := PlanUpgrade("1.28", "1.31")
+	if !errors.Is(err, ErrUnsupportedVersion) || !strings.Contains(err.Error(), "between Kubernetes 1.29 and 1.31") {
+		t.Errorf("PlanUpgrade() error = %v, want the missing 1.30 reported", err)
+	}
+	if _, err := PlanUpgrade("1.31", "1.33"); err != nil {
+		t.Errorf("PlanUpgrade() after the gap error = %v", err)
+	}
+}
+
+func TestComponentVersions(t *testing.T) {
+	result, err := ComponentVersions("ETCD", VersionsOptions{})
+	if err != nil {
+		t.Fatalf("ComponentVersions() error = %v", err)
+	}
+	if result.Component != "etcd" {
+		t.Errorf("Component = %s, want etcd", result.Component)
+	}
+	if len(result.Entries) != len(ListK8sVersions(VersionsOptions{}).K8sVersions) {
+		t.Errorf("ComponentVersions() returned %d entries, want one per supported version", len(result.Entries))
+	}
+
+	if _, err := ComponentVersion("invalid", "1.30"); err == nil {
+		t.Error("ComponentVersion() expected error for unknown component")
+	}
+}
+
+func TestArchivedVersions(t *testing.T) {
+	check, err := Check("1.25")
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(check.Warnings) != 1 || !strings.Contains(check.Warnings[0], "end of life on 2023-10-28") {
+		t.Errorf("Check() warnings = %q, want the 1.25 end of life", check.Warnings)
+	}
+	if check, _ := Check("1.30"); len(check.Warnings) != 0 {
+		t.Errorf("Check(1.30) warnings = %q, want none", check.Warnings)
+	}
+
+	plan, err := PlanUpgrade("1.25", "1.30")
+	if err != nil {
+		t.Fatalf("PlanUpgrade() error = %v", err)
+	}
+	// 1.25, 1.26 and 1.27 are archived; 1.28 onwards are supported
+	if len(plan.Warnings) != 3 {
+		t.Errorf("PlanUpgrade() warnings = %q, want one per archived release", plan.Warnings)
+	}
+
+	supported := ListK8sVersions(VersionsOptions{})
+	all := ListK8sVersions(VersionsOptions{Archived: true})
+	if got := strings.Join(all.ArchivedVersions, ","); got != "1.27,1.26,1.25" {
+		t.Errorf("ArchivedVersions = %s, want 1.27,1.26,1.25", got)
+	}
+	if strings.Join(all.K8sVersions, ",") != strings.Join(supported.K8sVersions, ",") || len(supported.ArchivedVersions) != 0 {
+		t.Errorf("K8sVersions = %v, want only the supported releases", all.K8sVersions)
+	}
+
+	result, err := ComponentVersions("coredns", VersionsOptions{Archived: true})
+	if err != nil {
+		t.Fatalf("ComponentVersions() error = %v", err)
+	}
+	last := result.Entries[len(result.Entries)-1]
+	if last.K8sVersion != "1.25" || last.Recommended != "1.9.3" {
+		t.Errorf("last entry = %s %s, want 1.25 1.9.3", last.K8sVersion, last.Recommended)
+	}
+	if len(result.Warnings) != 3 {
+		t.Errorf("ComponentVersions() warnings = %q, want one per archived release", result.Warnings)
+	}
+}
+
+// usePatchData activates the embedded data with etcd bumps in 1.30.2 and
+// 1.30.5 until the test ends
+func usePatchData(t *testing.T) {
+	t.Helper()
+	embedded := compatibility.Embedded()
+	b := &compatibility.Bundle{Generated: embedded.Generated, Matrices: make(map[string]compatibility.K8sVersionMatrix)}
+	for k, m := range embedded.Matrices {
+		b.Matrices[k] = m
+	}
+	m := b.Matrices["1.30"]
+	m.Patches = map[string]compatibility.PatchMatrix{
+		"1.30.2": {Components: map[string]compatibility.ComponentInfo{"etcd": {Recommended: "3.5.13"}}},
+		"1.30.5": {Components: map[string]compatibility.ComponentInfo{"etcd": {Recommended: "3.5.15", Notes: "etcd 3.5.15 fixes a watch regression"}}},
+	}
+	b.Matrices["1.30"] = m
+	compatibility.Use(b)
+	t.Cleanup(func() { compatibility.Use(nil) })
+}
+
+func TestCheckPatchVersion(t *testing.T) {
+	usePatchData(t)
+
+	for _, k8sVersion := range []string{"1.30.3", "v1.30.3-eks-1", "1.30.3-gke.1"} {
+		result, err := Check(k8sVersion)
+		if err != nil {
+			t.Fatalf("Check(%s) error = %v", k8sVersion, err)
+		}
+		if result.K8sVersion != "1.30.3" {
+			t.Errorf("Check(%s) K8sVersion = %s, want 1.30.3", k8sVersion, result.K8sVersion)
+		}
+		for _, c := range result.Components {
+			if c.Name == "etcd" && c.Recommended != "3.5.13" {
+				t.Errorf("Check(%s) etcd recommended = %s, want 3.5.13 from the 1.30.2 entry", k8sVersion, c.Recommended)
+			}
+		}
+	}
+}
+
+func TestComponentVersionsPatches(t *testing.T) {
+	usePatchData(t)
+
+	result, err := ComponentVersions("etcd", VersionsOptions{Patches: true})
+	if err != nil {
+		t.Fatalf("ComponentVersions() error = %v", err)
+	}
+	got := make([]string, 0)
+	for _, e := range result.Entries {
+		if e.K8sVersion == "1.30" || e.K8sVersion == "1.30.2" || e.K8sVersion == "1.30.5" {
+			got = append(got, e.K8sVersion+"="+e.Recommended)
+		}
+	}
+	want := "1.30.5=3.5.15 1.30.2=3.5.13 1.30=3.5.12"
+	if s := strings.Join(got, " "); s != want {
+		t.Errorf("patch history = %s, want %s", s, want)
+	}
+
+	// kube-proxy has no patch entries
+	result, _ = ComponentVersions("kube-proxy", VersionsOptions{Patches: true})
+	if len(result.Entries) != len(ListK8sVersions(VersionsOptions{}).K8sVersions) {
+		t.Errorf("kube-proxy entries = %d, want one per minor", len(result.Entries))
+	}
+}
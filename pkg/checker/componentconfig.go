@@ -0,0 +1,73 @@
+package checker
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pmady/kube-dependency-checker/pkg/compatibility"
+	"github.com/pmady/kube-dependency-checker/pkg/componentconfig"
+	"github.com/pmady/kube-dependency-checker/pkg/output"
+)
+
+// RuleComponentConfig is the rule of component configuration findings
+const RuleComponentConfig = "component-config"
+
+// CheckComponentConfigs checks KubeletConfiguration and
+// KubeProxyConfiguration documents against a release. An API version the
+// release does not read and removed fields or values are errors,
+// deprecated fields are warnings and fields left unset whose default
+// changes are reported for information.
+func CheckComponentConfigs(matrix *compatibility.K8sVersionMatrix, docs []componentconfig.Document) []output.Finding {
+	findings := make([]output.Finding, 0)
+	for _, doc := range docs {
+		kind, ok := matrix.Configs[doc.Kind]
+		if !ok {
+			continue
+		}
+		component, _ := componentconfig.Component(doc.Kind)
+		add := func(subject, severity, message, notes string) {
+			if notes != "" {
+				message += ". " + notes
+			}
+			findings = append(findings, output.Finding{
+				Rule:      RuleComponentConfig,
+				Severity:  severity,
+				Component: component,
+				Subject:   subject,
+				Source:    doc.Source,
+				Message:   doc.Source + ": " + message,
+			})
+		}
+
+		if !slices.Contains(kind.APIVersions, doc.APIVersion) {
+			add("apiVersion", "error", fmt.Sprintf("%s apiVersion %q is not served by Kubernetes %s", doc.Kind, doc.APIVersion, matrix.K8sVersion),
+				"Use "+strings.Join(kind.APIVersions, " or "))
+		}
+
+		keys := make([]string, 0, len(kind.Fields))
+		for key := range kind.Fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			field := kind.Fields[key]
+			path, value, hasValue := strings.Cut(key, "=")
+			node, set := doc.Lookup(path)
+			if hasValue {
+				set = set && node.Value == value
+			}
+
+			switch {
+			case field.Status == compatibility.FieldDefaultChanged && !set:
+				add(path, "info", fmt.Sprintf("%s is not set and its default changes in Kubernetes %s", path, matrix.K8sVersion), field.Notes)
+			case field.Status == compatibility.FieldRemoved && set:
+				add(key, "error", fmt.Sprintf("%s is removed in Kubernetes %s", key, matrix.K8sVersion), field.Notes)
+			case field.Status == compatibility.FieldDeprecated && set:
+				add(key, "warning", fmt.Sprintf("%s is deprecated in Kubernetes %s", key, matrix.K8sVersion), field.Notes)
+			}
+		}
+	}
+	return findings
+}
@@ -0,0 +1,44 @@
+package checker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pmady/kube-dependency-checker/pkg/compatibility"
+	"github.com/pmady/kube-dependency-checker/pkg/componentconfig"
+)
+
+func TestCheckComponentConfigs(t *testing.T) {
+	data := `apiVersion: kubeproxy.config.k8s.io/v1alpha1
+kind: KubeProxyConfiguration
+mode: iptables
+portRange: 30000-32767
+---
+apiVersion: kubelet.config.k8s.io/v1alpha1
+kind: KubeletConfiguration
+cgroupDriver: systemd
+memorySwap:
+  swapBehavior: UnlimitedSwap
+`
+	docs, err := componentconfig.Parse("configs.yaml", []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	matrix, ok := compatibility.GetMatrix("1.31")
+	if !ok {
+		t.Fatal("no data for 1.31")
+	}
+
+	got := make([]string, 0)
+	for _, f := range CheckComponentConfigs(matrix, docs) {
+		got = append(got, f.Severity+" "+f.Component+" "+strings.SplitN(f.Message, ". ", 2)[0])
+	}
+	want := []string{
+		"warning kube-proxy configs.yaml: portRange is deprecated in Kubernetes 1.31",
+		"error kubelet configs.yaml: KubeletConfiguration apiVersion \"kubelet.config.k8s.io/v1alpha1\" is not served by Kubernetes 1.31",
+		"error kubelet configs.yaml: memorySwap.swapBehavior=UnlimitedSwap is removed in Kubernetes 1.31",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("CheckComponentConfigs() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
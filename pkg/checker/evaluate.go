@@ -0,0 +1,190 @@
+package checker
+
+import (
+	"fmt"
+
+	"github.com/pmady/kube-dependency-checker/pkg/compatibility"
+	"github.com/pmady/kube-dependency-checker/pkg/inventory"
+	"github.com/pmady/kube-dependency-checker/pkg/output"
+	"github.com/pmady/kube-dependency-checker/pkg/version"
+)
+
+// Component status values
+const (
+	StatusCompatible   = "compatible"
+	StatusIncompatible = "incompatible"
+	StatusUnknown      = "unknown"
+	// StatusWaived is an incompatible component accepted by a waiver
+	StatusWaived = "waived"
+)
+
+// Evaluate checks the observed component versions of a cluster against the
+// compatibility matrix for its Kubernetes version. Components without an
+// observed version are reported as unknown.
+func Evaluate(cluster *inventory.Cluster) (*output.CheckResult, error) {
+	apiServer, err := version.Parse(cleanK8sVersion(cluster.K8sVersion))
+	if err != nil {
+		return nil, fmt.Errorf("cluster %s: %w", cluster.Name, err)
+	}
+
+	// Results are reported per minor version, evaluated against the data
+	// for the cluster's patch release
+	k8sVersion := apiServer.ShortString()
+	matrix, ok := compatibility.GetMatrix(apiServer.Raw)
+	if !ok {
+		return nil, fmt.Errorf("cluster %s: %w: %s", cluster.Name, ErrUnsupportedVersion, k8sVersion)
+	}
+
+	result := &output.CheckResult{
+		Cluster:    cluster.Name,
+		Source:     cluster.Source,
+		Labels:     cluster.Labels,
+		K8sVersion: k8sVersion,
+		Components: make([]output.ComponentResult, 0),
+		Warnings:   supportWarnings(matrix),
+	}
+
+	for _, compName := range ComponentOrder {
+		info, exists := matrix.Components[compName]
+		if !exists {
+			continue
+		}
+
+		compResult := newComponentResult(info)
+		current := cluster.Components[compName]
+		if current == "" && compName == "kubelet" && len(cluster.NodePools) > 0 {
+			compResult.Current, compResult.Status = evaluateNodePools(info, cluster.NodePools, apiServer)
+		} else {
+			compResult.Current = current
+			compResult.Status = evaluateComponent(info, current, apiServer)
+		}
+
+		result.Components = append(result.Components, compResult)
+	}
+
+	result.Summary = Summarize(result.Components)
+	return result, nil
+}
+
+// Summarize counts component results by status
+func Summarize(components []output.ComponentResult) output.Summary {
+	summary := output.Summary{TotalComponents: len(components)}
+	for _, c := range components {
+		switch c.Status {
+		case StatusCompatible:
+			summary.CompatibleComponents++
+		case StatusIncompatible:
+			summary.IncompatibleCount++
+		case StatusWaived:
+			summary.WaivedCount++
+		default:
+			summary.UnknownCount++
+		}
+	}
+	return summary
+}
+
+// SkewMinors returns how many minor versions a component lags behind the
+// API server. It is negative when the component is newer.
+func SkewMinors(apiServerVersion, componentVersion string) (int, error) {
+	apiServer, err := version.Parse(apiServerVersion)
+	if err != nil {
+		return 0, err
+	}
+	component, err := version.Parse(componentVersion)
+	if err != nil {
+		return 0, err
+	}
+	if apiServer.Major != component.Major {
+		return 0, fmt.Errorf("major version mismatch: %s and %s", apiServerVersion, componentVersion)
+	}
+	return apiServer.Minor - component.Minor, nil
+}
+
+func evaluateComponent(info compatibility.ComponentInfo, current string, apiServer *version.Version) string {
+	if current == "" {
+		return StatusUnknown
+	}
+	v, err := version.Parse(current)
+	if err != nil {
+		return StatusUnknown
+	}
+
+	if info.SkewPolicy != "" {
+		return evaluateSkew(info, v, apiServer)
+	}
+	return evaluateRange(info, v)
+}
+
+// evaluateSkew applies the version skew policy, which is defined on minor
+// versions only
+func evaluateSkew(info compatibility.ComponentInfo, v, apiServer *version.Version) string {
+	if v.Major != apiServer.Major {
+		return StatusIncompatible
+	}
+	skew := apiServer.Minor - v.Minor
+	if skew < 0 && !info.CanBeNewer {
+		return StatusIncompatible
+	}
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > info.MaxMinorSkew {
+		return StatusIncompatible
+	}
+	return StatusCompatible
+}
+
+func evaluateRange(info compatibility.ComponentInfo, v *version.Version) string {
+	if info.MinVersion == "" && info.MaxVersion == "" {
+		return StatusUnknown
+	}
+	if info.MinVersion != "" {
+		min, err := version.Parse(info.MinVersion)
+		if err != nil {
+			return StatusUnknown
+		}
+		if v.IsOlderThan(min) {
+			return StatusIncompatible
+		}
+	}
+	if info.MaxVersion != "" {
+		max, err := version.Parse(info.MaxVersion)
+		if err != nil {
+			return StatusUnknown
+		}
+		if v.IsNewerThan(max) {
+			return StatusIncompatible
+		}
+	}
+	return StatusCompatible
+}
+
+// evaluateNodePools checks every node pool's kubelet and reports the oldest
+// kubelet version with the worst status across pools
+func evaluateNodePools(info compatibility.ComponentInfo, pools []inventory.NodePool, apiServer *version.Version) (string, string) {
+	status := StatusCompatible
+	var oldest *version.Version
+	current := ""
+
+	for _, pool := range pools {
+		poolStatus := evaluateComponent(info, pool.KubeletVersion, apiServer)
+		switch {
+		case poolStatus == StatusIncompatible:
+			status = StatusIncompatible
+		case poolStatus == StatusUnknown && status == StatusCompatible:
+			status = StatusUnknown
+		}
+
+		v, err := version.Parse(pool.KubeletVersion)
+		if err != nil {
+			continue
+		}
+		if oldest == nil || v.IsOlderThan(oldest) {
+			oldest = v
+			current = pool.KubeletVersion
+		}
+	}
+
+	return current, status
+}
@@ -0,0 +1,94 @@
+package checker
+
+import (
+	"testing"
+
+	"github.com/pmady/kube-dependency-checker/pkg/inventory"
+)
+
+func TestEvaluate(t *testing.T) {
+	cluster := &inventory.Cluster{
+		Name:       "prod",
+		K8sVersion: "v1.30.4",
+		Components: map[string]string{
+			"etcd":                    "3.5.12",
+			"coredns":                 "1.9.3",
+			"containerd":              "not-a-version",
+			"kube-controller-manager": "1.28.0",
+			"kube-scheduler":          "1.30.1",
+			"kubectl":                 "1.31.0",
+		},
+		NodePools: []inventory.NodePool{
+			{Name: "default", KubeletVersion: "1.30.2"},
+			{Name: "legacy", KubeletVersion: "1.27.9"},
+		},
+	}
+
+	result, err := Evaluate(cluster)
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	if result.Cluster != "prod" || result.K8sVersion != "1.30" {
+		t.Errorf("Evaluate() cluster = %s, k8sVersion = %s", result.Cluster, result.K8sVersion)
+	}
+
+	want := map[string]struct {
+		current string
+		status  string
+	}{
+		"etcd":                    {"3.5.12", StatusCompatible},
+		"CoreDNS":                 {"1.9.3", StatusIncompatible},
+		"containerd":              {"not-a-version", StatusUnknown},
+		"kubelet":                 {"1.27.9", StatusCompatible},
+		"kube-proxy":              {"", StatusUnknown},
+		"kube-controller-manager": {"1.28.0", StatusIncompatible},
+		"kube-scheduler":          {"1.30.1", StatusCompatible},
+		"kubectl":                 {"1.31.0", StatusCompatible},
+	}
+	for _, c := range result.Components {
+		w, ok := want[c.Name]
+		if !ok {
+			t.Errorf("unexpected component %s", c.Name)
+			continue
+		}
+		if c.Current != w.current || c.Status != w.status {
+			t.Errorf("%s = (%s, %s), want (%s, %s)", c.Name, c.Current, c.Status, w.current, w.status)
+		}
+	}
+
+	if result.Summary.CompatibleComponents != 4 || result.Summary.IncompatibleCount != 2 || result.Summary.UnknownCount != 2 {
+		t.Errorf("Evaluate() summary = %+v", result.Summary)
+	}
+}
+
+func TestEvaluateUnsupportedVersion(t *testing.T) {
+	if _, err := Evaluate(&inventory.Cluster{Name: "old", K8sVersion: "1.20.0"}); err == nil {
+		t.Error("Evaluate() expected error for unsupported version")
+	}
+}
+
+func TestSkewMinors(t *testing.T) {
+	tests := []struct {
+		apiServer string
+		component string
+		want      int
+		wantErr   bool
+	}{
+		{"1.30.4", "1.30.1", 0, false},
+		{"1.30.4", "1.27.9", 3, false},
+		{"1.30.4", "1.31.0", -1, false},
+		{"1.30.4", "2.0.0", 0, true},
+		{"1.30.4", "invalid", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := SkewMinors(tt.apiServer, tt.component)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("SkewMinors(%s, %s) error = %v, wantErr %v", tt.apiServer, tt.component, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("SkewMinors(%s, %s) = %d, want %d", tt.apiServer, tt.component, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package checker
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pmady/kube-dependency-checker/pkg/compatibility"
+	"github.com/pmady/kube-dependency-checker/pkg/output"
+	"github.com/pmady/kube-dependency-checker/pkg/version"
+)
+
+// RuleFeatureGate is the rule of feature gate findings
+const RuleFeatureGate = "feature-gate"
+
+// FeatureGateState is the state of a feature gate in a Kubernetes release
+type FeatureGateState struct {
+	Name            string `json:"name" yaml:"name"`
+	K8sVersion      string `json:"k8sVersion" yaml:"k8sVersion"`
+	Stage           string `json:"stage" yaml:"stage"`
+	Default         bool   `json:"default" yaml:"default"`
+	LockedToDefault bool   `json:"lockedToDefault" yaml:"lockedToDefault"`
+}
+
+// ParseFeatureGates parses feature gates in the form of the components'
+// --feature-gates flag, e.g. "NodeSwap=true,SidecarContainers=false"
+func ParseFeatureGates(s string) (map[string]bool, error) {
+	gates := make(map[string]bool)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid feature gate %q (want Name=true or Name=false)", pair)
+		}
+		enabled, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for feature gate %s", value, name)
+		}
+		gates[strings.TrimSpace(name)] = enabled
+	}
+	return gates, nil
+}
+
+// ListFeatureGates returns the feature gates of a release, or the lifecycle
+// of one gate across releases when gate is set. Releases are listed oldest
+// first.
+func ListFeatureGates(k8sVersion, gate string) ([]FeatureGateState, error) {
+	var releases []*version.Version
+	if k8sVersion != "" {
+		v, err := version.Parse(cleanK8sVersion(k8sVersion))
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := compatibility.GetMatrix(v.ShortString()); !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedVersion, v.ShortString())
+		}
+		releases = []*version.Version{v}
+	} else {
+		releases = append(compatibility.GetArchivedVersions(), compatibility.GetSupportedVersions()...)
+	}
+
+	states := make([]FeatureGateState, 0)
+	for _, v := range releases {
+		matrix, _ := compatibility.GetMatrix(v.ShortString())
+		names := make([]string, 0, len(matrix.FeatureGates))
+		for name := range matrix.FeatureGates {
+			if gate == "" || name == gate {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			g := matrix.FeatureGates[name]
+			states = append(states, FeatureGateState{Name: name, K8sVersion: matrix.K8sVersion, Stage: g.Stage, Default: g.Default, LockedToDefault: g.LockedToDefault})
+		}
+	}
+	if gate != "" && len(states) == 0 {
+		return nil, fmt.Errorf("no data for feature gate %s", gate)
+	}
+	return states, nil
+}
+
+// CheckFeatureGates reports configured feature gates that stop components
+// starting in a release, because the gate is removed or locked to the other
+// value, as errors, and settings that will stop working later as warnings.
+// Gates without data are not reported.
+func CheckFeatureGates(matrix *compatibility.K8sVersionMatrix, gates map[string]bool) []output.Finding {
+	findings := make([]output.Finding, 0)
+	for _, name := range sortedGates(gates) {
+		gate, ok := matrix.FeatureGates[name]
+		if !ok {
+			continue
+		}
+		severity, message := featureGateProblem(name, gates[name], gate, matrix.K8sVersion)
+		if message != "" {
+			findings = append(findings, output.Finding{Rule: RuleFeatureGate, Severity: severity, Subject: name, Message: message})
+		}
+	}
+	return findings
+}
+
+func featureGateProblem(name string, enabled bool, gate compatibility.FeatureGate, k8sVersion string) (string, string) {
+	switch {
+	case gate.Stage == compatibility.StageRemoved:
+		return "error", fmt.Sprintf("feature gate %s is removed in Kubernetes %s; components fail to start while it is set", name, k8sVersion)
+	case gate.LockedToDefault && enabled != gate.Default:
+		return "error", fmt.Sprintf("feature gate %s is locked to %t in Kubernetes %s; setting it to %t fails component startup", name, gate.Default, k8sVersion, enabled)
+	case gate.LockedToDefault:
+		return "warning", fmt.Sprintf("feature gate %s is locked to %t in Kubernetes %s; remove the setting before the gate is removed", name, gate.Default, k8sVersion)
+	case gate.Stage == compatibility.StageDeprecated:
+		return "warning", fmt.Sprintf("feature gate %s is deprecated in Kubernetes %s", name, k8sVersion)
+	}
+	return "", ""
+}
+
+// UpgradeFeatureGateWarnings adds a warning to an upgrade plan for every
+// configured feature gate that stops components starting at a step of the
+// plan, at the first step it does
+func UpgradeFeatureGateWarnings(result *output.UpgradeResult, gates map[string]bool) {
+	reported := make(map[string]bool)
+	for _, step := range result.Steps {
+		matrix, ok := compatibility.GetMatrix(step.To)
+		if !ok {
+			continue
+		}
+		for _, name := range sortedGates(gates) {
+			gate, ok := matrix.FeatureGates[name]
+			if !ok || reported[name] {
+				continue
+			}
+			if severity, message := featureGateProblem(name, gates[name], gate, matrix.K8sVersion); severity == "error" {
+				reported[name] = true
+				result.Warnings = append(result.Warnings, fmt.Sprintf("upgrade to %s: %s", step.To, message))
+			}
+		}
+	}
+}
+
+func sortedGates(gates map[string]bool) []string {
+	names := make([]string, 0, len(gates))
+	for name := range gates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
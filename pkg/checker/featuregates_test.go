@@ -0,0 +1,98 @@
+package checker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pmady/kube-dependency-checker/pkg/compatibility"
+)
+
+func TestParseFeatureGates(t *testing.T) {
+	gates, err := ParseFeatureGates(" NodeSwap=true, SidecarContainers=false,,")
+	if err != nil {
+		t.Fatalf("ParseFeatureGates() error = %v", err)
+	}
+	if len(gates) != 2 || !gates["NodeSwap"] || gates["SidecarContainers"] {
+		t.Errorf("ParseFeatureGates() = %v", gates)
+	}
+	for _, s := range []string{"NodeSwap", "=true", "NodeSwap=yes please"} {
+		if _, err := ParseFeatureGates(s); err == nil {
+			t.Errorf("ParseFeatureGates(%q) expected error", s)
+		}
+	}
+}
+
+func TestCheckFeatureGates(t *testing.T) {
+	matrix := &compatibility.K8sVersionMatrix{
+		K8sVersion: "1.31",
+		FeatureGates: map[string]compatibility.FeatureGate{
+			"PodSecurity":             {Stage: compatibility.StageRemoved},
+			"StatefulSetStartOrdinal": {Stage: compatibility.StageGA, Default: true, LockedToDefault: true},
+			"NodeSwap":                {Stage: compatibility.StageBeta, Default: true},
+			"Legacy":                  {Stage: compatibility.StageDeprecated},
+		},
+	}
+	gates := map[string]bool{
+		"PodSecurity":             true,
+		"StatefulSetStartOrdinal": false,
+		"NodeSwap":                false,
+		"Legacy":                  true,
+		"NotInTheData":            true,
+	}
+	got := make([]string, 0)
+	for _, f := range CheckFeatureGates(matrix, gates) {
+		got = append(got, f.Severity+": "+f.Message)
+	}
+	want := []string{
+		"warning: feature gate Legacy is deprecated in Kubernetes 1.31",
+		"error: feature gate PodSecurity is removed in Kubernetes 1.31; components fail to start while it is set",
+		"error: feature gate StatefulSetStartOrdinal is locked to true in Kubernetes 1.31; setting it to false fails component startup",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("CheckFeatureGates() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestUpgradeFeatureGateWarnings(t *testing.T) {
+	result, err := PlanUpgrade("1.27", "1.31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result.Warnings = nil
+	UpgradeFeatureGateWarnings(result, map[string]bool{"CronJobTimeZone": true, "NodeSwap": true})
+
+	// Reported once, at the first step without the gate
+	want := []string{"upgrade to 1.29: feature gate CronJobTimeZone is removed in Kubernetes 1.29; components fail to start while it is set"}
+	if strings.Join(result.Warnings, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Warnings = %v, want %v", result.Warnings, want)
+	}
+}
+
+func TestListFeatureGates(t *testing.T) {
+	states, err := ListFeatureGates("", "SidecarContainers")
+	if err != nil {
+		t.Fatalf("ListFeatureGates() error = %v", err)
+	}
+	if first := states[0]; first.K8sVersion != "1.28" || first.Stage != compatibility.StageAlpha {
+		t.Errorf("first state = %+v, want alpha in 1.28", first)
+	}
+	if last := states[len(states)-1]; last.K8sVersion != "1.33" || last.Stage != compatibility.StageGA || !last.LockedToDefault {
+		t.Errorf("last state = %+v, want locked GA in 1.33", last)
+	}
+
+	states, err = ListFeatureGates("v1.30.2", "")
+	if err != nil {
+		t.Fatalf("ListFeatureGates() error = %v", err)
+	}
+	for _, s := range states {
+		if s.K8sVersion != "1.30" {
+			t.Errorf("state %+v is not for 1.30", s)
+		}
+	}
+	if _, err := ListFeatureGates("", "NoSuchGate"); err == nil {
+		t.Error("ListFeatureGates() expected error for an unknown gate")
+	}
+	if _, err := ListFeatureGates("1.10", ""); err == nil {
+		t.Error("ListFeatureGates() expected error for a release without data")
+	}
+}
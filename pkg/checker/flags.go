@@ -0,0 +1,45 @@
+package checker
+
+import (
+	"fmt"
+
+	"github.com/pmady/kube-dependency-checker/pkg/compatibility"
+	"github.com/pmady/kube-dependency-checker/pkg/flags"
+	"github.com/pmady/kube-dependency-checker/pkg/output"
+)
+
+// RuleFlag is the rule of component flag findings
+const RuleFlag = "flag"
+
+// CheckFlags reports component flags that are removed in a release, which
+// stop the component starting, as errors and deprecated flags as warnings
+func CheckFlags(matrix *compatibility.K8sVersionMatrix, usages []flags.Usage) []output.Finding {
+	findings := make([]output.Finding, 0)
+	seen := make(map[flags.Usage]bool)
+	for _, u := range usages {
+		flag, ok := matrix.Flags[u.Component][u.Flag]
+		if !ok || seen[u] {
+			continue
+		}
+		seen[u] = true
+
+		severity := "warning"
+		message := fmt.Sprintf("%s: flag --%s is deprecated in Kubernetes %s", u.Source, u.Flag, matrix.K8sVersion)
+		if flag.Status == compatibility.FlagRemoved {
+			severity = "error"
+			message = fmt.Sprintf("%s: flag --%s is removed in Kubernetes %s and the component fails to start with it", u.Source, u.Flag, matrix.K8sVersion)
+		}
+		if flag.Notes != "" {
+			message += ". " + flag.Notes
+		}
+		findings = append(findings, output.Finding{
+			Rule:      RuleFlag,
+			Severity:  severity,
+			Component: u.Component,
+			Subject:   "--" + u.Flag,
+			Source:    u.Source,
+			Message:   message,
+		})
+	}
+	return findings
+}
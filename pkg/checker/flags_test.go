@@ -0,0 +1,35 @@
+package checker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pmady/kube-dependency-checker/pkg/compatibility"
+	"github.com/pmady/kube-dependency-checker/pkg/flags"
+)
+
+func TestCheckFlags(t *testing.T) {
+	matrix, ok := compatibility.GetMatrix("1.31")
+	if !ok {
+		t.Fatal("no data for 1.31")
+	}
+	usages := []flags.Usage{
+		{Component: "kube-controller-manager", Flag: "pod-eviction-timeout", Source: "kcm.yaml"},
+		{Component: "kube-controller-manager", Flag: "pod-eviction-timeout", Source: "kcm.yaml"},
+		{Component: "kube-controller-manager", Flag: "leader-elect", Source: "kcm.yaml"},
+		{Component: "kubelet", Flag: "pod-infra-container-image", Source: "kubeadm-flags.env"},
+		// Removed from the kubelet, not from kube-scheduler
+		{Component: "kube-scheduler", Flag: "container-runtime", Source: "scheduler.yaml"},
+	}
+	got := make([]string, 0)
+	for _, f := range CheckFlags(matrix, usages) {
+		got = append(got, f.Severity+" "+f.Component+" "+strings.SplitN(f.Message, " and ", 2)[0])
+	}
+	want := []string{
+		"error kube-controller-manager kcm.yaml: flag --pod-eviction-timeout is removed in Kubernetes 1.31",
+		"warning kubelet kubeadm-flags.env: flag --pod-infra-container-image is deprecated in Kubernetes 1.31. The sandbox image is set in the container runtime",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("CheckFlags() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
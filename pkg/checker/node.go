@@ -0,0 +1,142 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pmady/kube-dependency-checker/pkg/compatibility"
+	"github.com/pmady/kube-dependency-checker/pkg/images"
+	"github.com/pmady/kube-dependency-checker/pkg/node"
+	"github.com/pmady/kube-dependency-checker/pkg/output"
+	"github.com/pmady/kube-dependency-checker/pkg/version"
+)
+
+// NodeComponents are the components checked on a node
+var NodeComponents = []string{"kubelet", "containerd", "runc"}
+
+// Rules of node prerequisite findings
+const (
+	RuleNodeCgroup        = "node-cgroup"
+	RuleNodeKernel        = "node-kernel"
+	RuleNodeSwap          = "node-swap"
+	RuleNodeSystemdCgroup = "node-systemd-cgroup"
+)
+
+// CheckNode checks the versions found on a node against the matrix of the
+// Kubernetes version the node joins. The kubelet is held to the skew
+// policy against that version. Unmet host prerequisites of the release are
+// reported as findings.
+func CheckNode(k8sVersion string, facts *node.Facts) (*output.CheckResult, error) {
+	k8sVersion = cleanK8sVersion(k8sVersion)
+	apiServer, err := version.Parse(k8sVersion)
+	if err != nil {
+		return nil, err
+	}
+	matrix, ok := compatibility.GetMatrix(k8sVersion)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s\nSupported versions: %s",
+			ErrUnsupportedVersion, apiServer.ShortString(), strings.Join(supportedVersions(), ", "))
+	}
+
+	result := &output.CheckResult{
+		Cluster:    facts.Hostname,
+		Source:     "node",
+		K8sVersion: apiServer.ShortString(),
+		Components: make([]output.ComponentResult, 0, len(NodeComponents)),
+		Warnings:   supportWarnings(matrix),
+	}
+
+	current := map[string]string{
+		"kubelet":    facts.Kubelet,
+		"containerd": facts.Containerd,
+		"runc":       facts.Runc,
+	}
+	for _, compName := range NodeComponents {
+		info, exists := matrix.Components[compName]
+		if !exists {
+			continue
+		}
+		compResult := newComponentResult(info)
+		compResult.Current = current[compName]
+		compResult.Status = evaluateComponent(info, compResult.Current, apiServer)
+		result.Components = append(result.Components, compResult)
+	}
+
+	if w := sandboxWarning(matrix, facts.ContainerdConfig); w != "" {
+		result.Warnings = append(result.Warnings, w)
+	}
+
+	result.Findings = NodePrerequisites(matrix, facts)
+	result.Summary = Summarize(result.Components)
+	return result, nil
+}
+
+// NodePrerequisites checks the node facts against the host prerequisites of
+// a release. Unknown cgroup, kernel and swap facts are reported for
+// information, since they could not be checked.
+func NodePrerequisites(matrix *compatibility.K8sVersionMatrix, facts *node.Facts) []output.Finding {
+	req := matrix.Node
+	if req == nil {
+		return nil
+	}
+	findings := make([]output.Finding, 0)
+	add := func(rule, severity, format string, args ...interface{}) {
+		findings = append(findings, output.Finding{Rule: rule, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+	k8s := matrix.K8sVersion
+
+	switch {
+	case facts.CgroupVersion == 0:
+		add(RuleNodeCgroup, "info", "cgroup version is unknown and was not checked")
+	case facts.CgroupVersion == 1:
+		switch req.CgroupV1 {
+		case compatibility.CgroupV1Maintenance:
+			add(RuleNodeCgroup, "warning", "cgroup v1 is in maintenance mode in Kubernetes %s; migrate the node to cgroup v2", k8s)
+		case compatibility.CgroupV1Unsupported:
+			add(RuleNodeCgroup, "error", "Kubernetes %s does not support cgroup v1; the kubelet fails to start", k8s)
+		}
+	}
+
+	if kernel, err := version.Parse(facts.Kernel); err != nil {
+		add(RuleNodeKernel, "info", "kernel version is unknown and was not checked")
+	} else {
+		if min, err := version.Parse(req.MinKernel); err == nil && kernel.IsOlderThan(min) {
+			add(RuleNodeKernel, "error", "Linux %s is older than %s, the oldest kernel Kubernetes %s supports", facts.Kernel, req.MinKernel, k8s)
+		} else if min, err := version.Parse(req.CgroupV2MinKernel); err == nil && facts.CgroupVersion == 2 && kernel.IsOlderThan(min) {
+			add(RuleNodeKernel, "warning", "Linux %s is older than %s, which cgroup v2 needs for complete support", facts.Kernel, req.CgroupV2MinKernel)
+		}
+	}
+
+	if facts.Swap == nil {
+		add(RuleNodeSwap, "info", "whether swap is on is unknown and was not checked")
+	} else if *facts.Swap {
+		switch {
+		case !req.Swap:
+			add(RuleNodeSwap, "error", "swap is on, which Kubernetes %s does not support without the NodeSwap feature gate; the kubelet fails to start unless failSwapOn is false", k8s)
+		case facts.CgroupVersion == 1:
+			add(RuleNodeSwap, "error", "swap is on, which the kubelet only supports on cgroup v2")
+		}
+	}
+
+	if cfg := facts.ContainerdConfig; req.SystemdCgroup && cfg != nil && !cfg.SystemdCgroup {
+		add(RuleNodeSystemdCgroup, "warning", "containerd does not use the systemd cgroup driver (SystemdCgroup = true), which kubeadm configures the kubelet with")
+	}
+	return findings
+}
+
+// sandboxWarning reports a containerd sandbox image that differs from the
+// pause image of the release, which kubeadm also warns about
+func sandboxWarning(matrix *compatibility.K8sVersionMatrix, cfg *node.ContainerdConfig) string {
+	pause, ok := matrix.Components["pause"]
+	if !ok || cfg == nil || cfg.SandboxImage == "" {
+		return ""
+	}
+	ref, err := images.Parse(cfg.SandboxImage)
+	if err != nil {
+		return fmt.Sprintf("containerd sandbox image %q is not a valid image reference", cfg.SandboxImage)
+	}
+	if ref.Tag == pause.Recommended {
+		return ""
+	}
+	return fmt.Sprintf("containerd sandbox image %s differs from pause %s used by Kubernetes %s", cfg.SandboxImage, pause.Recommended, matrix.K8sVersion)
+}
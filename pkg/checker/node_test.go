@@ -0,0 +1,116 @@
+package checker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pmady/kube-dependency-checker/pkg/compatibility"
+	"github.com/pmady/kube-dependency-checker/pkg/node"
+)
+
+func TestCheckNode(t *testing.T) {
+	facts := &node.Facts{
+		Hostname:         "ami-build",
+		Kubelet:          "1.27.16",
+		Containerd:       "1.5.18",
+		ContainerdConfig: &node.ContainerdConfig{SandboxImage: "registry.k8s.io/pause:3.8"},
+	}
+	result, err := CheckNode("1.31", facts)
+	if err != nil {
+		t.Fatalf("CheckNode() error = %v", err)
+	}
+
+	want := map[string]string{
+		// kubelet 1.27 is four minors behind 1.31
+		"kubelet":    StatusIncompatible,
+		"containerd": StatusIncompatible,
+		"runc":       StatusUnknown,
+	}
+	if len(result.Components) != len(want) {
+		t.Fatalf("Components = %+v", result.Components)
+	}
+	for _, c := range result.Components {
+		if c.Status != want[c.Name] {
+			t.Errorf("%s status = %s, want %s", c.Name, c.Status, want[c.Name])
+		}
+	}
+	if result.Cluster != "ami-build" || result.Summary.IncompatibleCount != 2 {
+		t.Errorf("result = %+v", result)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "pause 3.10") {
+		t.Errorf("Warnings = %v, want a sandbox image warning", result.Warnings)
+	}
+
+	facts = &node.Facts{Kubelet: "1.30.4", Containerd: "1.7.20", Runc: "1.1.14"}
+	result, err = CheckNode("v1.31.2", facts)
+	if err != nil {
+		t.Fatalf("CheckNode() error = %v", err)
+	}
+	if result.Summary.CompatibleComponents != 3 || len(result.Warnings) != 0 {
+		t.Errorf("result = %+v, want all compatible", result)
+	}
+
+	if _, err := CheckNode("1.10", facts); err == nil {
+		t.Error("CheckNode() expected error for a release without data")
+	}
+}
+
+func TestNodePrerequisites(t *testing.T) {
+	on, off := true, false
+	tests := []struct {
+		name  string
+		k8s   string
+		facts node.Facts
+		want  []string
+	}{
+		{
+			name:  "supported host",
+			k8s:   "1.31",
+			facts: node.Facts{CgroupVersion: 2, Kernel: "6.1.0", Swap: &on, ContainerdConfig: &node.ContainerdConfig{SystemdCgroup: true}},
+		},
+		{
+			name: "unknown facts are reported",
+			k8s:  "1.32",
+			want: []string{"info " + RuleNodeCgroup, "info " + RuleNodeKernel, "info " + RuleNodeSwap},
+		},
+		{
+			name:  "cgroup v1 in maintenance with swap",
+			k8s:   "1.31",
+			facts: node.Facts{CgroupVersion: 1, Kernel: "5.4.0", Swap: &on},
+			want:  []string{"warning " + RuleNodeCgroup, "error " + RuleNodeSwap},
+		},
+		{
+			name:  "old kernel and cgroupfs driver",
+			k8s:   "1.32",
+			facts: node.Facts{CgroupVersion: 2, Kernel: "4.14.0", Swap: &off, ContainerdConfig: &node.ContainerdConfig{}},
+			want:  []string{"error " + RuleNodeKernel, "warning " + RuleNodeSystemdCgroup},
+		},
+		{
+			name:  "cgroup v2 on an old kernel",
+			k8s:   "1.30",
+			facts: node.Facts{CgroupVersion: 2, Kernel: "5.4.0", Swap: &off},
+			want:  []string{"warning " + RuleNodeKernel},
+		},
+		{
+			name:  "swap before NodeSwap is on by default",
+			k8s:   "1.29",
+			facts: node.Facts{CgroupVersion: 2, Kernel: "6.1.0", Swap: &on},
+			want:  []string{"error " + RuleNodeSwap},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matrix, ok := compatibility.GetMatrix(tt.k8s)
+			if !ok {
+				t.Fatalf("no data for %s", tt.k8s)
+			}
+			got := make([]string, 0)
+			for _, f := range NodePrerequisites(matrix, &tt.facts) {
+				got = append(got, f.Severity+" "+f.Rule)
+			}
+			if strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+				t.Errorf("NodePrerequisites() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
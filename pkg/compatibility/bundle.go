@@ -0,0 +1,184 @@
+package compatibility
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pmady/kube-dependency-checker/pkg/version"
+	"gopkg.in/yaml.v3"
+)
+
+// SourceEmbedded is the Source of the bundle compiled into the binary
+const SourceEmbedded = "embedded"
+
+//go:embed data/matrix.yaml
+var embeddedData []byte
+
+// Bundle is a complete set of compatibility data. The data compiled into the
+// binary is a bundle, and newer bundles can be fetched with "data update".
+type Bundle struct {
+	// Generated is when the data was produced; newer bundles take precedence
+	Generated time.Time                   `json:"generated" yaml:"generated"`
+	Matrices  map[string]K8sVersionMatrix `json:"matrices" yaml:"matrices"`
+
+	// Source is where the bundle was loaded from: "embedded" or a file path
+	Source string `json:"-" yaml:"-"`
+}
+
+// ParseBundle parses and validates a bundle
+func ParseBundle(data []byte) (*Bundle, error) {
+	var b Bundle
+	if err := yaml.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// Validate checks that the bundle is complete enough to be used
+func (b *Bundle) Validate() error {
+	if b.Generated.IsZero() {
+		return fmt.Errorf("invalid bundle: generated timestamp is missing")
+	}
+	if len(b.Matrices) == 0 {
+		return fmt.Errorf("invalid bundle: no Kubernetes versions")
+	}
+	for key, m := range b.Matrices {
+		if _, err := version.Parse(key); err != nil || strings.Count(key, ".") != 1 {
+			return fmt.Errorf("invalid bundle: %q is not a Kubernetes minor version", key)
+		}
+		if len(m.Components) == 0 {
+			return fmt.Errorf("invalid bundle: Kubernetes %s has no components", key)
+		}
+		if m.EndOfLife != "" {
+			if _, ok := m.EndOfLifeDate(); !ok {
+				return fmt.Errorf("invalid bundle: Kubernetes %s has an invalid end of life date %q", key, m.EndOfLife)
+			}
+		}
+		if n := m.Node; n != nil {
+			switch n.CgroupV1 {
+			case "", CgroupV1Supported, CgroupV1Maintenance, CgroupV1Unsupported:
+			default:
+				return fmt.Errorf("invalid bundle: Kubernetes %s has an invalid cgroupV1 support level %q", key, n.CgroupV1)
+			}
+			for _, kernel := range []string{n.MinKernel, n.CgroupV2MinKernel} {
+				if _, err := version.Parse(kernel); kernel != "" && err != nil {
+					return fmt.Errorf("invalid bundle: Kubernetes %s has an invalid kernel version %q", key, kernel)
+				}
+			}
+		}
+		for patch := range m.Patches {
+			if minor, v := splitVersion(patch); v == nil || minor != key {
+				return fmt.Errorf("invalid bundle: patch entry %q is not a patch release of Kubernetes %s", patch, key)
+			}
+		}
+	}
+	return nil
+}
+
+// NewerThan reports whether the bundle was generated after other
+func (b *Bundle) NewerThan(other *Bundle) bool {
+	return other == nil || b.Generated.After(other.Generated)
+}
+
+var (
+	embeddedOnce   sync.Once
+	embeddedBundle *Bundle
+	activeBundle   atomic.Pointer[Bundle]
+)
+
+// Embedded returns the bundle compiled into the binary
+func Embedded() *Bundle {
+	embeddedOnce.Do(func() {
+		b, err := ParseBundle(embeddedData)
+		if err != nil {
+			panic(fmt.Sprintf("embedded compatibility data: %v", err))
+		}
+		b.Source = SourceEmbedded
+		embeddedBundle = b
+	})
+	return embeddedBundle
+}
+
+// Active returns the bundle used by GetMatrix and the other lookups: the
+// bundle passed to Use, or the embedded bundle
+func Active() *Bundle {
+	if b := activeBundle.Load(); b != nil {
+		return b
+	}
+	return Embedded()
+}
+
+// Use makes b the active bundle. Passing nil restores the embedded bundle.
+func Use(b *Bundle) {
+	activeBundle.Store(b)
+}
+
+// Marshal encodes the bundle as YAML in the layout of the embedded data
+// file: header comment lines first, then Kubernetes versions newest first
+func (b *Bundle) Marshal(header string) ([]byte, error) {
+	keys := make([]string, 0, len(b.Matrices))
+	for k := range b.Matrices {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		vi, erri := version.Parse(keys[i])
+		vj, errj := version.Parse(keys[j])
+		if erri != nil || errj != nil {
+			return keys[i] > keys[j]
+		}
+		return vi.Compare(vj) > 0
+	})
+
+	matrices := &yaml.Node{Kind: yaml.MappingNode}
+	for _, k := range keys {
+		var value yaml.Node
+		if err := value.Encode(b.Matrices[k]); err != nil {
+			return nil, err
+		}
+		key := &yaml.Node{Kind: yaml.ScalarNode, Value: k, Style: yaml.DoubleQuotedStyle}
+		matrices.Content = append(matrices.Content, key, &value)
+	}
+
+	var generated yaml.Node
+	if err := generated.Encode(b.Generated.UTC()); err != nil {
+		return nil, err
+	}
+	doc := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+		{Kind: yaml.ScalarNode, Value: "generated"}, &generated,
+		{Kind: yaml.ScalarNode, Value: "matrices"}, matrices,
+	}}
+
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Header returns the leading comment lines of a data file, so that they can
+// be kept when the file is rewritten with Marshal
+func Header(data []byte) string {
+	var header strings.Builder
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			break
+		}
+		header.WriteString(line)
+	}
+	return header.String()
+}
@@ -0,0 +1,27 @@
+// Package compatibilitytest provides helpers for testing compatibility data.
+package compatibilitytest
+
+import (
+	"testing"
+
+	"github.com/pmady/kube-dependency-checker/pkg/compatibility"
+)
+
+// CheckMatrices fails the test with every issue compatibility.Lint finds in
+// matrices, so that all problems are reported in one run
+func CheckMatrices(t testing.TB, matrices map[string]compatibility.K8sVersionMatrix) {
+	t.Helper()
+	for _, issue := range compatibility.Lint(matrices) {
+		t.Errorf("compatibility data: %s", issue)
+	}
+}
+
+// CheckBundle parses a bundle and checks its matrices
+func CheckBundle(t testing.TB, data []byte) {
+	t.Helper()
+	b, err := compatibility.ParseBundle(data)
+	if err != nil {
+		t.Fatalf("compatibility data: %v", err)
+	}
+	CheckMatrices(t, b.Matrices)
+}
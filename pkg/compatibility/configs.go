@@ -0,0 +1,38 @@
+package compatibility
+
+// Configuration field statuses
+const (
+	FieldDeprecated     = "deprecated"
+	FieldRemoved        = "removed"
+	FieldDefaultChanged = "default-changed"
+)
+
+// ConfigKind describes a component configuration kind, such as
+// KubeletConfiguration, in a Kubernetes release
+type ConfigKind struct {
+	// APIVersions are the API versions the release reads
+	APIVersions []string `json:"apiVersions" yaml:"apiVersions"`
+	// Fields lists deprecated, removed and changed fields, keyed by dotted
+	// path, or by "path=value" for a value that is no longer accepted
+	Fields map[string]ConfigField `json:"fields,omitempty" yaml:"fields,omitempty"`
+}
+
+// ConfigField is a configuration field that is deprecated, removed or has a
+// new default in a release
+type ConfigField struct {
+	Status string `json:"status" yaml:"status"`
+	Notes  string `json:"notes,omitempty" yaml:"notes,omitempty"`
+}
+
+// MarshalYAML writes a field on a single line, keeping the data file
+// readable
+func (f ConfigField) MarshalYAML() (interface{}, error) {
+	type plain ConfigField
+	return flowStyle(plain(f))
+}
+
+// ValidFieldStatus reports whether status is a known configuration field
+// status
+func ValidFieldStatus(status string) bool {
+	return status == FieldDeprecated || status == FieldRemoved || status == FieldDefaultChanged
+}
@@ -0,0 +1,67 @@
+package compatibility
+
+import (
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Feature gate stages, in lifecycle order
+const (
+	StageAlpha      = "alpha"
+	StageBeta       = "beta"
+	StageGA         = "ga"
+	StageDeprecated = "deprecated"
+	StageRemoved    = "removed"
+)
+
+var stageOrder = map[string]int{StageAlpha: 0, StageBeta: 1, StageGA: 2, StageDeprecated: 3, StageRemoved: 4}
+
+// FeatureGate is the state of a feature gate in a Kubernetes release
+type FeatureGate struct {
+	Stage   string `json:"stage" yaml:"stage"`
+	Default bool   `json:"default,omitempty" yaml:"default,omitempty"`
+	// LockedToDefault gates fail component startup when set to the other
+	// value
+	LockedToDefault bool `json:"lockedToDefault,omitempty" yaml:"lockedToDefault,omitempty"`
+}
+
+// MarshalYAML writes a feature gate on a single line, keeping the data file
+// readable
+func (g FeatureGate) MarshalYAML() (interface{}, error) {
+	type plain FeatureGate
+	return flowStyle(plain(g))
+}
+
+// flowStyle encodes v as a single-line YAML mapping
+func flowStyle(v interface{}) (interface{}, error) {
+	var node yaml.Node
+	if err := node.Encode(v); err != nil {
+		return nil, err
+	}
+	node.Style = yaml.FlowStyle
+	return &node, nil
+}
+
+// ValidStage reports whether stage is a known feature gate stage
+func ValidStage(stage string) bool {
+	_, ok := stageOrder[stage]
+	return ok
+}
+
+// FeatureGateNames returns the names of the feature gates in any release,
+// sorted
+func FeatureGateNames() []string {
+	seen := make(map[string]bool)
+	for _, m := range Active().Matrices {
+		for name := range m.FeatureGates {
+			seen[name] = true
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,26 @@
+package compatibility
+
+// Flag statuses
+const (
+	FlagDeprecated = "deprecated"
+	FlagRemoved    = "removed"
+)
+
+// Flag is a command line flag of a component that is deprecated or removed
+// in a Kubernetes release
+type Flag struct {
+	Status string `json:"status" yaml:"status"`
+	// Notes name the replacement, e.g. a configuration file field
+	Notes string `json:"notes,omitempty" yaml:"notes,omitempty"`
+}
+
+// MarshalYAML writes a flag on a single line, keeping the data file readable
+func (f Flag) MarshalYAML() (interface{}, error) {
+	type plain Flag
+	return flowStyle(plain(f))
+}
+
+// ValidFlagStatus reports whether status is a known flag status
+func ValidFlagStatus(status string) bool {
+	return status == FlagDeprecated || status == FlagRemoved
+}
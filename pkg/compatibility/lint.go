@@ -0,0 +1,228 @@
+package compatibility
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pmady/kube-dependency-checker/pkg/version"
+)
+
+// Issue is an inconsistency found in compatibility data
+type Issue struct {
+	K8sVersion string `json:"k8sVersion" yaml:"k8sVersion"`
+	Component  string `json:"component,omitempty" yaml:"component,omitempty"`
+	Message    string `json:"message" yaml:"message"`
+}
+
+func (i Issue) String() string {
+	if i.Component != "" {
+		return fmt.Sprintf("%s/%s: %s", i.K8sVersion, i.Component, i.Message)
+	}
+	return fmt.Sprintf("%s: %s", i.K8sVersion, i.Message)
+}
+
+// Lint checks matrices for internal consistency and returns every issue
+// found:
+//   - each K8sVersion matches its map key and patch entries belong to it
+//   - all versions parse ("x" segments, as in 3.5.x, are wildcards)
+//   - MinVersion <= MaxVersion and Recommended lies within them
+//   - Kubernetes minor versions have no gaps
+//   - archived releases are all older than the supported ones
+//   - a component's MinVersion never goes backwards in a later release
+//   - feature gate stages are known and never go backwards
+//   - flag statuses are known and removed flags stay removed
+//   - configuration kinds have API versions and known field statuses
+func Lint(matrices map[string]K8sVersionMatrix) []Issue {
+	issues := make([]Issue, 0)
+	add := func(k8sVersion, component, format string, args ...interface{}) {
+		issues = append(issues, Issue{K8sVersion: k8sVersion, Component: component, Message: fmt.Sprintf(format, args...)})
+	}
+
+	type release struct {
+		key     string
+		version *version.Version
+		matrix  K8sVersionMatrix
+	}
+	releases := make([]release, 0, len(matrices))
+
+	for key, m := range matrices {
+		if m.K8sVersion != key {
+			add(key, "", "k8sVersion %q does not match its key", m.K8sVersion)
+		}
+		v, err := version.Parse(key)
+		if err != nil {
+			add(key, "", "Kubernetes version does not parse")
+		} else {
+			releases = append(releases, release{key: key, version: v, matrix: m})
+		}
+		if m.EndOfLife != "" {
+			if _, ok := m.EndOfLifeDate(); !ok {
+				add(key, "", "endOfLife %q is not a YYYY-MM-DD date", m.EndOfLife)
+			}
+		}
+
+		for _, name := range sortedComponents(m) {
+			lintComponent(m.Components[name], func(format string, args ...interface{}) {
+				add(key, name, format, args...)
+			})
+		}
+
+		for name, gate := range m.FeatureGates {
+			if !ValidStage(gate.Stage) {
+				add(key, "", "feature gate %s has unknown stage %q", name, gate.Stage)
+			}
+		}
+
+		for component, flags := range m.Flags {
+			for name, flag := range flags {
+				if !ValidFlagStatus(flag.Status) {
+					add(key, component, "flag --%s has unknown status %q", name, flag.Status)
+				}
+			}
+		}
+
+		for kind, config := range m.Configs {
+			if len(config.APIVersions) == 0 {
+				add(key, "", "%s has no API versions", kind)
+			}
+			for field, f := range config.Fields {
+				if !ValidFieldStatus(f.Status) {
+					add(key, "", "%s field %s has unknown status %q", kind, field, f.Status)
+				}
+			}
+		}
+
+		// Patch entries are checked as resolved, with the inherited fields
+		for patch, pm := range m.Patches {
+			minor, v := splitVersion(patch)
+			if v == nil || minor != key {
+				add(key, "", "patch entry %q is not a patch release of %s", patch, key)
+				continue
+			}
+			resolved := m.Resolve(v)
+			for _, name := range sortedComponents(K8sVersionMatrix{Components: pm.Components}) {
+				lintComponent(resolved.Components[name], func(format string, args ...interface{}) {
+					add(patch, name, format, args...)
+				})
+			}
+		}
+	}
+
+	sort.Slice(releases, func(i, j int) bool {
+		return releases[i].version.Compare(releases[j].version) < 0
+	})
+	for i := 1; i < len(releases); i++ {
+		prev, cur := releases[i-1], releases[i]
+		if cur.version.Major == prev.version.Major && cur.version.Minor != prev.version.Minor+1 {
+			add(cur.key, "", "gap in Kubernetes versions: previous release is %s", prev.key)
+		}
+		if cur.matrix.Archived && !prev.matrix.Archived {
+			add(cur.key, "", "archived release is newer than supported release %s", prev.key)
+		}
+		for name, gate := range cur.matrix.FeatureGates {
+			prevGate, ok := prev.matrix.FeatureGates[name]
+			if ok && ValidStage(gate.Stage) && ValidStage(prevGate.Stage) && stageOrder[gate.Stage] < stageOrder[prevGate.Stage] {
+				add(cur.key, "", "feature gate %s goes back from %s in Kubernetes %s to %s", name, prevGate.Stage, prev.key, gate.Stage)
+			}
+		}
+		for component, flags := range prev.matrix.Flags {
+			for name, flag := range flags {
+				if flag.Status == FlagRemoved && cur.matrix.Flags[component][name].Status != FlagRemoved {
+					add(cur.key, component, "flag --%s is not listed as removed, but was removed in Kubernetes %s", name, prev.key)
+				}
+			}
+		}
+		for _, name := range sortedComponents(cur.matrix) {
+			prevInfo, ok := prev.matrix.Components[name]
+			if !ok {
+				continue
+			}
+			// Unparseable versions have already been reported
+			prevMin, _ := parsePattern(prevInfo.MinVersion)
+			curMin, _ := parsePattern(cur.matrix.Components[name].MinVersion)
+			if prevMin == nil || curMin == nil {
+				continue
+			}
+			if curMin.Compare(prevMin) < 0 {
+				add(cur.key, name, "minVersion %s is lower than %s in Kubernetes %s",
+					cur.matrix.Components[name].MinVersion, prevInfo.MinVersion, prev.key)
+			}
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if a.K8sVersion != b.K8sVersion {
+			return a.K8sVersion < b.K8sVersion
+		}
+		if a.Component != b.Component {
+			return a.Component < b.Component
+		}
+		return a.Message < b.Message
+	})
+	return issues
+}
+
+func lintComponent(info ComponentInfo, add func(format string, args ...interface{})) {
+	if info.Name == "" {
+		add("name is empty")
+	}
+	if info.MaxMinorSkew < 0 {
+		add("maxMinorSkew %d is negative", info.MaxMinorSkew)
+	}
+
+	fields := []struct {
+		name  string
+		value string
+	}{
+		{"version", info.Version},
+		{"minVersion", info.MinVersion},
+		{"maxVersion", info.MaxVersion},
+		{"recommended", info.Recommended},
+	}
+	parsed := make(map[string]*version.Version, len(fields))
+	for _, f := range fields {
+		v, err := parsePattern(f.value)
+		if err != nil {
+			add("%s %q does not parse", f.name, f.value)
+			continue
+		}
+		parsed[f.name] = v
+	}
+
+	min, max, rec := parsed["minVersion"], parsed["maxVersion"], parsed["recommended"]
+	if min != nil && max != nil && min.Compare(max) > 0 {
+		add("minVersion %s is greater than maxVersion %s", info.MinVersion, info.MaxVersion)
+	}
+	if rec != nil && min != nil && rec.Compare(min) < 0 {
+		add("recommended %s is lower than minVersion %s", info.Recommended, info.MinVersion)
+	}
+	if rec != nil && max != nil && rec.Compare(max) > 0 {
+		add("recommended %s is greater than maxVersion %s", info.Recommended, info.MaxVersion)
+	}
+}
+
+// parsePattern parses a version in which "x" segments are wildcards,
+// returning the lowest matching version, or nil for an empty string
+func parsePattern(s string) (*version.Version, error) {
+	if s == "" {
+		return nil, nil
+	}
+	segments := strings.Split(strings.TrimPrefix(s, "v"), ".")
+	for i, seg := range segments {
+		if seg == "x" || seg == "X" || seg == "*" {
+			segments[i] = "0"
+		}
+	}
+	return version.Parse(strings.Join(segments, "."))
+}
+
+func sortedComponents(m K8sVersionMatrix) []string {
+	names := make([]string, 0, len(m.Components))
+	for name := range m.Components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,80 @@
+package compatibility_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pmady/kube-dependency-checker/pkg/compatibility"
+	"github.com/pmady/kube-dependency-checker/pkg/compatibility/compatibilitytest"
+)
+
+func TestEmbeddedDataIsConsistent(t *testing.T) {
+	compatibilitytest.CheckMatrices(t, compatibility.Embedded().Matrices)
+}
+
+func TestLint(t *testing.T) {
+	matrices := map[string]compatibility.K8sVersionMatrix{
+		"1.30": {
+			K8sVersion: "1.30",
+			Components: map[string]compatibility.ComponentInfo{
+				"etcd": {Name: "etcd", Version: "3.5.x", MinVersion: "3.5.0", MaxVersion: "3.5.99", Recommended: "3.5.12"},
+			},
+			FeatureGates: map[string]compatibility.FeatureGate{
+				"PodSecurity": {Stage: compatibility.StageRemoved},
+			},
+			Flags: map[string]map[string]compatibility.Flag{
+				"kubelet": {"container-runtime": {Status: compatibility.FlagRemoved}},
+			},
+		},
+		"1.31": {
+			K8sVersion: "1.30",
+			Components: map[string]compatibility.ComponentInfo{
+				"etcd":    {Name: "etcd", MinVersion: "3.4.0", MaxVersion: "3.5.99", Recommended: "3.6.0"},
+				"coredns": {Name: "CoreDNS", MinVersion: "1.11.0", MaxVersion: "1.10.0", Recommended: "latest"},
+			},
+			FeatureGates: map[string]compatibility.FeatureGate{
+				"PodSecurity": {Stage: compatibility.StageGA, Default: true, LockedToDefault: true},
+				"NodeSwap":    {Stage: "preview"},
+			},
+			Flags: map[string]map[string]compatibility.Flag{
+				"kubelet": {"max-pods": {Status: "obsolete"}},
+			},
+			Configs: map[string]compatibility.ConfigKind{
+				"KubeletConfiguration": {Fields: map[string]compatibility.ConfigField{
+					"cgroupDriver": {Status: "changed"},
+				}},
+			},
+		},
+		"1.33": {
+			K8sVersion: "1.33",
+			Archived:   true,
+			Components: map[string]compatibility.ComponentInfo{
+				"etcd": {Name: "etcd", MinVersion: "3.5.0"},
+			},
+		},
+	}
+
+	want := []string{
+		"1.31: KubeletConfiguration field cgroupDriver has unknown status \"changed\"",
+		"1.31: KubeletConfiguration has no API versions",
+		"1.31: feature gate NodeSwap has unknown stage \"preview\"",
+		"1.31: feature gate PodSecurity goes back from removed in Kubernetes 1.30 to ga",
+		"1.31: k8sVersion \"1.30\" does not match its key",
+		"1.31/coredns: minVersion 1.11.0 is greater than maxVersion 1.10.0",
+		"1.31/coredns: recommended \"latest\" does not parse",
+		"1.31/etcd: minVersion 3.4.0 is lower than 3.5.0 in Kubernetes 1.30",
+		"1.31/etcd: recommended 3.6.0 is greater than maxVersion 3.5.99",
+		"1.31/kubelet: flag --container-runtime is not listed as removed, but was removed in Kubernetes 1.30",
+		"1.31/kubelet: flag --max-pods has unknown status \"obsolete\"",
+		"1.33: archived release is newer than supported release 1.31",
+		"1.33: gap in Kubernetes versions: previous release is 1.31",
+	}
+	issues := compatibility.Lint(matrices)
+	got := make([]string, len(issues))
+	for i, issue := range issues {
+		got[i] = issue.String()
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Lint() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
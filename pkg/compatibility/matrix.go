@@ -1,403 +1,279 @@
 // Package compatibility provides Kubernetes version compatibility data.
 // It contains the compatibility matrix for various Kubernetes versions
 // and their associated component versions (etcd, CoreDNS, containerd, etc.).
+// The matrix is read from an embedded data bundle, which can be replaced at
+// runtime by a newer bundle fetched with "data update".
 package compatibility
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pmady/kube-dependency-checker/pkg/version"
+)
+
 // ComponentInfo holds version compatibility information for a component
 type ComponentInfo struct {
-	Name           string
-	Version        string
-	MinVersion     string
-	MaxVersion     string
-	Recommended    string
-	SkewPolicy     string
-	MaxMinorSkew   int
-	CanBeNewer     bool // kubectl can be newer than API server
-	Notes          string
+	Name         string `json:"name" yaml:"name"`
+	Version      string `json:"version,omitempty" yaml:"version,omitempty"`
+	MinVersion   string `json:"minVersion,omitempty" yaml:"minVersion,omitempty"`
+	MaxVersion   string `json:"maxVersion,omitempty" yaml:"maxVersion,omitempty"`
+	Recommended  string `json:"recommended,omitempty" yaml:"recommended,omitempty"`
+	SkewPolicy   string `json:"skewPolicy,omitempty" yaml:"skewPolicy,omitempty"`
+	MaxMinorSkew int    `json:"maxMinorSkew,omitempty" yaml:"maxMinorSkew,omitempty"`
+	CanBeNewer   bool   `json:"canBeNewer,omitempty" yaml:"canBeNewer,omitempty"` // kubectl can be newer than API server
+	Notes        string `json:"notes,omitempty" yaml:"notes,omitempty"`
 }
 
 // K8sVersionMatrix holds all component compatibility info for a K8s version
 type K8sVersionMatrix struct {
-	K8sVersion string
-	Components map[string]ComponentInfo
+	K8sVersion string                   `json:"k8sVersion" yaml:"k8sVersion"`
+	EndOfLife  string                   `json:"endOfLife,omitempty" yaml:"endOfLife,omitempty"` // upstream end of maintenance, YYYY-MM-DD
+	Archived   bool                     `json:"archived,omitempty" yaml:"archived,omitempty"`   // out of support, kept for upgrade planning
+	Components map[string]ComponentInfo `json:"components" yaml:"components"`
+	Node       *NodeRequirements        `json:"node,omitempty" yaml:"node,omitempty"`
+	// FeatureGates lists the state of notable feature gates. Gates removed
+	// in an earlier release are kept with stage "removed".
+	FeatureGates map[string]FeatureGate `json:"featureGates,omitempty" yaml:"featureGates,omitempty"`
+	// Flags lists deprecated and removed command line flags per component,
+	// keyed by flag name without dashes. Removed flags stay listed.
+	Flags map[string]map[string]Flag `json:"flags,omitempty" yaml:"flags,omitempty"`
+	// Configs describes component configuration kinds, keyed by kind
+	Configs map[string]ConfigKind `json:"configs,omitempty" yaml:"configs,omitempty"`
+	// Patches override component fields for patch releases, keyed by full
+	// version, e.g. "1.30.2"
+	Patches map[string]PatchMatrix `json:"patches,omitempty" yaml:"patches,omitempty"`
+}
+
+// cgroup v1 support levels
+const (
+	CgroupV1Supported   = "supported"
+	CgroupV1Maintenance = "maintenance"
+	CgroupV1Unsupported = "unsupported"
+)
+
+// NodeRequirements are the host prerequisites of a Kubernetes release
+type NodeRequirements struct {
+	// CgroupV1 is "supported", "maintenance" (still works, but deprecated
+	// and without new features) or "unsupported"
+	CgroupV1 string `json:"cgroupV1,omitempty" yaml:"cgroupV1,omitempty"`
+	// MinKernel is the oldest Linux kernel the release supports
+	MinKernel string `json:"minKernel,omitempty" yaml:"minKernel,omitempty"`
+	// CgroupV2MinKernel is the oldest kernel with complete cgroup v2 support
+	CgroupV2MinKernel string `json:"cgroupV2MinKernel,omitempty" yaml:"cgroupV2MinKernel,omitempty"`
+	// Swap reports whether the kubelet supports swap without feature gates
+	Swap bool `json:"swap,omitempty" yaml:"swap,omitempty"`
+	// SystemdCgroup requires containerd to use the systemd cgroup driver,
+	// which kubeadm configures the kubelet with
+	SystemdCgroup bool   `json:"systemdCgroup,omitempty" yaml:"systemdCgroup,omitempty"`
+	Notes         string `json:"notes,omitempty" yaml:"notes,omitempty"`
+}
+
+// PatchMatrix holds the component fields that change in a patch release.
+// A patch release inherits everything else from its minor version and from
+// earlier patch releases; empty fields keep the inherited value.
+type PatchMatrix struct {
+	Components map[string]ComponentInfo `json:"components" yaml:"components"`
+}
+
+// EndOfLifeDate returns the parsed end of life date, if known
+func (m *K8sVersionMatrix) EndOfLifeDate() (time.Time, bool) {
+	if m.EndOfLife == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse("2006-01-02", m.EndOfLife)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// SupportWarning describes why the release is out of support, or returns ""
+// for a supported release
+func (m *K8sVersionMatrix) SupportWarning() string {
+	if !m.Archived {
+		return ""
+	}
+	if m.EndOfLife != "" {
+		return fmt.Sprintf("Kubernetes %s is out of support: it reached end of life on %s and its data is archived", m.K8sVersion, m.EndOfLife)
+	}
+	return fmt.Sprintf("Kubernetes %s is out of support and its data is archived", m.K8sVersion)
+}
+
+// Resolve returns the matrix for a patch release of this minor version:
+// the minor's components with the overrides of every patch entry up to and
+// including patch applied in order
+func (m *K8sVersionMatrix) Resolve(patch *version.Version) K8sVersionMatrix {
+	resolved := K8sVersionMatrix{
+		K8sVersion:   patch.String(),
+		EndOfLife:    m.EndOfLife,
+		Archived:     m.Archived,
+		Components:   make(map[string]ComponentInfo, len(m.Components)),
+		Node:         m.Node,
+		FeatureGates: m.FeatureGates,
+		Flags:        m.Flags,
+		Configs:      m.Configs,
+	}
+	for name, c := range m.Components {
+		resolved.Components[name] = c
+	}
+	for _, key := range m.PatchVersions() {
+		v, _ := version.Parse(key)
+		if v.IsNewerThan(patch) {
+			break
+		}
+		for name, override := range m.Patches[key].Components {
+			resolved.Components[name] = mergeComponent(resolved.Components[name], override)
+		}
+	}
+	return resolved
+}
+
+// PatchVersions returns the keys of the patch entries, oldest first
+func (m *K8sVersionMatrix) PatchVersions() []string {
+	keys := make([]string, 0, len(m.Patches))
+	for k := range m.Patches {
+		if _, err := version.Parse(k); err == nil {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		vi, _ := version.Parse(keys[i])
+		vj, _ := version.Parse(keys[j])
+		return vi.IsOlderThan(vj)
+	})
+	return keys
+}
+
+// mergeComponent overrides the fields of base that are set in override
+func mergeComponent(base, override ComponentInfo) ComponentInfo {
+	for _, f := range []struct{ dst, src *string }{
+		{&base.Name, &override.Name},
+		{&base.Version, &override.Version},
+		{&base.MinVersion, &override.MinVersion},
+		{&base.MaxVersion, &override.MaxVersion},
+		{&base.Recommended, &override.Recommended},
+		{&base.SkewPolicy, &override.SkewPolicy},
+		{&base.Notes, &override.Notes},
+	} {
+		if *f.src != "" {
+			*f.dst = *f.src
+		}
+	}
+	if override.MaxMinorSkew != 0 {
+		base.MaxMinorSkew = override.MaxMinorSkew
+	}
+	if override.CanBeNewer {
+		base.CanBeNewer = true
+	}
+	return base
 }
 
-// CompatibilityMatrix holds the full compatibility data
-var CompatibilityMatrix = map[string]K8sVersionMatrix{
-	"1.33": {
-		K8sVersion: "1.33",
-		Components: map[string]ComponentInfo{
-			"etcd": {
-				Name:        "etcd",
-				Version:     "3.5.x",
-				MinVersion:  "3.5.0",
-				MaxVersion:  "3.5.99",
-				Recommended: "3.5.15",
-				Notes:       "etcd 3.5.x is required for Kubernetes 1.33",
-			},
-			"coredns": {
-				Name:        "CoreDNS",
-				Version:     "1.12.0",
-				MinVersion:  "1.11.0",
-				MaxVersion:  "1.12.99",
-				Recommended: "1.12.0",
-				Notes:       "Installed by kubeadm",
-			},
-			"containerd": {
-				Name:        "containerd",
-				Version:     "1.7.x",
-				MinVersion:  "1.7.0",
-				MaxVersion:  "2.0.99",
-				Recommended: "1.7.22",
-				Notes:       "containerd 1.7+ or 2.0+ supported",
-			},
-			"kubelet": {
-				Name:         "kubelet",
-				SkewPolicy:   "Up to 3 minor versions older than kube-apiserver",
-				MaxMinorSkew: 3,
-				CanBeNewer:   false,
-			},
-			"kube-proxy": {
-				Name:         "kube-proxy",
-				SkewPolicy:   "Up to 3 minor versions older than kube-apiserver",
-				MaxMinorSkew: 3,
-				CanBeNewer:   false,
-			},
-			"kube-controller-manager": {
-				Name:         "kube-controller-manager",
-				SkewPolicy:   "Up to 1 minor version older than kube-apiserver",
-				MaxMinorSkew: 1,
-				CanBeNewer:   false,
-			},
-			"kube-scheduler": {
-				Name:         "kube-scheduler",
-				SkewPolicy:   "Up to 1 minor version older than kube-apiserver",
-				MaxMinorSkew: 1,
-				CanBeNewer:   false,
-			},
-			"kubectl": {
-				Name:         "kubectl",
-				SkewPolicy:   "Within 1 minor version (older or newer)",
-				MaxMinorSkew: 1,
-				CanBeNewer:   true,
-			},
-		},
-	},
-	"1.32": {
-		K8sVersion: "1.32",
-		Components: map[string]ComponentInfo{
-			"etcd": {
-				Name:        "etcd",
-				Version:     "3.5.x",
-				MinVersion:  "3.5.0",
-				MaxVersion:  "3.5.99",
-				Recommended: "3.5.15",
-				Notes:       "etcd 3.5.x is required for Kubernetes 1.32",
-			},
-			"coredns": {
-				Name:        "CoreDNS",
-				Version:     "1.11.3",
-				MinVersion:  "1.10.0",
-				MaxVersion:  "1.11.99",
-				Recommended: "1.11.3",
-				Notes:       "Installed by kubeadm",
-			},
-			"containerd": {
-				Name:        "containerd",
-				Version:     "1.7.x",
-				MinVersion:  "1.6.0",
-				MaxVersion:  "2.0.99",
-				Recommended: "1.7.22",
-				Notes:       "containerd 1.6+ supported",
-			},
-			"kubelet": {
-				Name:         "kubelet",
-				SkewPolicy:   "Up to 3 minor versions older than kube-apiserver",
-				MaxMinorSkew: 3,
-				CanBeNewer:   false,
-			},
-			"kube-proxy": {
-				Name:         "kube-proxy",
-				SkewPolicy:   "Up to 3 minor versions older than kube-apiserver",
-				MaxMinorSkew: 3,
-				CanBeNewer:   false,
-			},
-			"kube-controller-manager": {
-				Name:         "kube-controller-manager",
-				SkewPolicy:   "Up to 1 minor version older than kube-apiserver",
-				MaxMinorSkew: 1,
-				CanBeNewer:   false,
-			},
-			"kube-scheduler": {
-				Name:         "kube-scheduler",
-				SkewPolicy:   "Up to 1 minor version older than kube-apiserver",
-				MaxMinorSkew: 1,
-				CanBeNewer:   false,
-			},
-			"kubectl": {
-				Name:         "kubectl",
-				SkewPolicy:   "Within 1 minor version (older or newer)",
-				MaxMinorSkew: 1,
-				CanBeNewer:   true,
-			},
-		},
-	},
-	"1.31": {
-		K8sVersion: "1.31",
-		Components: map[string]ComponentInfo{
-			"etcd": {
-				Name:        "etcd",
-				Version:     "3.5.x",
-				MinVersion:  "3.5.0",
-				MaxVersion:  "3.5.99",
-				Recommended: "3.5.12",
-				Notes:       "etcd 3.5.x is required for Kubernetes 1.31",
-			},
-			"coredns": {
-				Name:        "CoreDNS",
-				Version:     "1.11.3",
-				MinVersion:  "1.10.0",
-				MaxVersion:  "1.11.99",
-				Recommended: "1.11.3",
-				Notes:       "Installed by kubeadm",
-			},
-			"containerd": {
-				Name:        "containerd",
-				Version:     "1.7.x",
-				MinVersion:  "1.6.0",
-				MaxVersion:  "1.7.99",
-				Recommended: "1.7.20",
-				Notes:       "containerd 1.6+ supported",
-			},
-			"kubelet": {
-				Name:         "kubelet",
-				SkewPolicy:   "Up to 3 minor versions older than kube-apiserver",
-				MaxMinorSkew: 3,
-				CanBeNewer:   false,
-			},
-			"kube-proxy": {
-				Name:         "kube-proxy",
-				SkewPolicy:   "Up to 3 minor versions older than kube-apiserver",
-				MaxMinorSkew: 3,
-				CanBeNewer:   false,
-			},
-			"kube-controller-manager": {
-				Name:         "kube-controller-manager",
-				SkewPolicy:   "Up to 1 minor version older than kube-apiserver",
-				MaxMinorSkew: 1,
-				CanBeNewer:   false,
-			},
-			"kube-scheduler": {
-				Name:         "kube-scheduler",
-				SkewPolicy:   "Up to 1 minor version older than kube-apiserver",
-				MaxMinorSkew: 1,
-				CanBeNewer:   false,
-			},
-			"kubectl": {
-				Name:         "kubectl",
-				SkewPolicy:   "Within 1 minor version (older or newer)",
-				MaxMinorSkew: 1,
-				CanBeNewer:   true,
-			},
-		},
-	},
-	"1.30": {
-		K8sVersion: "1.30",
-		Components: map[string]ComponentInfo{
-			"etcd": {
-				Name:        "etcd",
-				Version:     "3.5.x",
-				MinVersion:  "3.5.0",
-				MaxVersion:  "3.5.99",
-				Recommended: "3.5.12",
-				Notes:       "etcd 3.5.x is required for Kubernetes 1.30",
-			},
-			"coredns": {
-				Name:        "CoreDNS",
-				Version:     "1.11.1",
-				MinVersion:  "1.10.0",
-				MaxVersion:  "1.11.99",
-				Recommended: "1.11.1",
-				Notes:       "Installed by kubeadm",
-			},
-			"containerd": {
-				Name:        "containerd",
-				Version:     "1.7.x",
-				MinVersion:  "1.6.0",
-				MaxVersion:  "1.7.99",
-				Recommended: "1.7.16",
-				Notes:       "containerd 1.6+ supported",
-			},
-			"kubelet": {
-				Name:         "kubelet",
-				SkewPolicy:   "Up to 3 minor versions older than kube-apiserver",
-				MaxMinorSkew: 3,
-				CanBeNewer:   false,
-			},
-			"kube-proxy": {
-				Name:         "kube-proxy",
-				SkewPolicy:   "Up to 3 minor versions older than kube-apiserver",
-				MaxMinorSkew: 3,
-				CanBeNewer:   false,
-			},
-			"kube-controller-manager": {
-				Name:         "kube-controller-manager",
-				SkewPolicy:   "Up to 1 minor version older than kube-apiserver",
-				MaxMinorSkew: 1,
-				CanBeNewer:   false,
-			},
-			"kube-scheduler": {
-				Name:         "kube-scheduler",
-				SkewPolicy:   "Up to 1 minor version older than kube-apiserver",
-				MaxMinorSkew: 1,
-				CanBeNewer:   false,
-			},
-			"kubectl": {
-				Name:         "kubectl",
-				SkewPolicy:   "Within 1 minor version (older or newer)",
-				MaxMinorSkew: 1,
-				CanBeNewer:   true,
-			},
-		},
-	},
-	"1.29": {
-		K8sVersion: "1.29",
-		Components: map[string]ComponentInfo{
-			"etcd": {
-				Name:        "etcd",
-				Version:     "3.5.x",
-				MinVersion:  "3.5.0",
-				MaxVersion:  "3.5.99",
-				Recommended: "3.5.10",
-				Notes:       "etcd 3.5.x is required for Kubernetes 1.29",
-			},
-			"coredns": {
-				Name:        "CoreDNS",
-				Version:     "1.11.1",
-				MinVersion:  "1.9.0",
-				MaxVersion:  "1.11.99",
-				Recommended: "1.11.1",
-				Notes:       "Installed by kubeadm",
-			},
-			"containerd": {
-				Name:        "containerd",
-				Version:     "1.7.x",
-				MinVersion:  "1.6.0",
-				MaxVersion:  "1.7.99",
-				Recommended: "1.7.13",
-				Notes:       "containerd 1.6+ supported",
-			},
-			"kubelet": {
-				Name:         "kubelet",
-				SkewPolicy:   "Up to 3 minor versions older than kube-apiserver",
-				MaxMinorSkew: 3,
-				CanBeNewer:   false,
-			},
-			"kube-proxy": {
-				Name:         "kube-proxy",
-				SkewPolicy:   "Up to 3 minor versions older than kube-apiserver",
-				MaxMinorSkew: 3,
-				CanBeNewer:   false,
-			},
-			"kube-controller-manager": {
-				Name:         "kube-controller-manager",
-				SkewPolicy:   "Up to 1 minor version older than kube-apiserver",
-				MaxMinorSkew: 1,
-				CanBeNewer:   false,
-			},
-			"kube-scheduler": {
-				Name:         "kube-scheduler",
-				SkewPolicy:   "Up to 1 minor version older than kube-apiserver",
-				MaxMinorSkew: 1,
-				CanBeNewer:   false,
-			},
-			"kubectl": {
-				Name:         "kubectl",
-				SkewPolicy:   "Within 1 minor version (older or newer)",
-				MaxMinorSkew: 1,
-				CanBeNewer:   true,
-			},
-		},
-	},
-	"1.28": {
-		K8sVersion: "1.28",
-		Components: map[string]ComponentInfo{
-			"etcd": {
-				Name:        "etcd",
-				Version:     "3.5.x",
-				MinVersion:  "3.5.0",
-				MaxVersion:  "3.5.99",
-				Recommended: "3.5.9",
-				Notes:       "etcd 3.5.x is required for Kubernetes 1.28",
-			},
-			"coredns": {
-				Name:        "CoreDNS",
-				Version:     "1.10.1",
-				MinVersion:  "1.9.0",
-				MaxVersion:  "1.10.99",
-				Recommended: "1.10.1",
-				Notes:       "Installed by kubeadm",
-			},
-			"containerd": {
-				Name:        "containerd",
-				Version:     "1.7.x",
-				MinVersion:  "1.6.0",
-				MaxVersion:  "1.7.99",
-				Recommended: "1.7.8",
-				Notes:       "containerd 1.6+ supported",
-			},
-			"kubelet": {
-				Name:         "kubelet",
-				SkewPolicy:   "Up to 3 minor versions older than kube-apiserver",
-				MaxMinorSkew: 3,
-				CanBeNewer:   false,
-			},
-			"kube-proxy": {
-				Name:         "kube-proxy",
-				SkewPolicy:   "Up to 3 minor versions older than kube-apiserver",
-				MaxMinorSkew: 3,
-				CanBeNewer:   false,
-			},
-			"kube-controller-manager": {
-				Name:         "kube-controller-manager",
-				SkewPolicy:   "Up to 1 minor version older than kube-apiserver",
-				MaxMinorSkew: 1,
-				CanBeNewer:   false,
-			},
-			"kube-scheduler": {
-				Name:         "kube-scheduler",
-				SkewPolicy:   "Up to 1 minor version older than kube-apiserver",
-				MaxMinorSkew: 1,
-				CanBeNewer:   false,
-			},
-			"kubectl": {
-				Name:         "kubectl",
-				SkewPolicy:   "Within 1 minor version (older or newer)",
-				MaxMinorSkew: 1,
-				CanBeNewer:   true,
-			},
-		},
-	},
+// splitVersion returns the minor key of a Kubernetes version and, for a full
+// patch version such as "1.30.2", the parsed patch version
+func splitVersion(k8sVersion string) (string, *version.Version) {
+	k8sVersion = strings.TrimPrefix(k8sVersion, "v")
+	if strings.Count(k8sVersion, ".") != 2 {
+		return k8sVersion, nil
+	}
+	v, err := version.Parse(k8sVersion)
+	if err != nil {
+		return k8sVersion, nil
+	}
+	return v.ShortString(), v
 }
 
-// GetMatrix returns the compatibility matrix for a given K8s version
+// GetMatrix returns the compatibility matrix for a given K8s version. A
+// full patch version such as "1.30.2" resolves to its minor's matrix with
+// the patch entries up to that release applied. Vendor builds such as
+// "v1.30.2-eks-1" or "1.30.2-gke.1" resolve as the release they are based on.
 func GetMatrix(k8sVersion string) (*K8sVersionMatrix, bool) {
-	matrix, ok := CompatibilityMatrix[k8sVersion]
+	if clean, ok := version.Clean(k8sVersion); ok {
+		k8sVersion = clean
+	}
+	minor, patch := splitVersion(k8sVersion)
+	matrix, ok := Active().Matrices[minor]
 	if !ok {
 		return nil, false
 	}
+	if patch != nil {
+		resolved := matrix.Resolve(patch)
+		return &resolved, true
+	}
 	return &matrix, true
 }
 
-// GetSupportedVersions returns all supported K8s versions
-func GetSupportedVersions() []string {
-	versions := make([]string, 0, len(CompatibilityMatrix))
-	for v := range CompatibilityMatrix {
-		versions = append(versions, v)
+// GetSupportedVersions returns the supported K8s versions, oldest first.
+// Archived releases are left out.
+func GetSupportedVersions() []*version.Version {
+	return releases(func(m *K8sVersionMatrix) bool { return !m.Archived })
+}
+
+// GetArchivedVersions returns the K8s versions that are out of support but
+// still have an archived matrix, oldest first
+func GetArchivedVersions() []*version.Version {
+	return releases(func(m *K8sVersionMatrix) bool { return m.Archived })
+}
+
+// Latest returns the newest supported K8s version, or nil if there is none
+func Latest() *version.Version {
+	versions := GetSupportedVersions()
+	if len(versions) == 0 {
+		return nil
+	}
+	return versions[len(versions)-1]
+}
+
+// Previous returns the newest release with data, archived or not, that is
+// older than the minor version of v
+func Previous(v *version.Version) (*version.Version, bool) {
+	minor := minorOf(v)
+	all := releases(nil)
+	for i := len(all) - 1; i >= 0; i-- {
+		if all[i].IsOlderThan(minor) {
+			return all[i], true
+		}
+	}
+	return nil, false
+}
+
+// Range returns the releases with data, archived or not, from the minor
+// version of from up to and including the minor version of to, oldest
+// first. Minor versions missing from the data are missing from the range.
+func Range(from, to *version.Version) []*version.Version {
+	lo, hi := minorOf(from), minorOf(to)
+	versions := make([]*version.Version, 0)
+	for _, v := range releases(nil) {
+		if !v.IsOlderThan(lo) && !v.IsNewerThan(hi) {
+			versions = append(versions, v)
+		}
 	}
 	return versions
 }
 
+// releases returns the minor versions of the active data whose matrix
+// matches keep, or all of them when keep is nil, oldest first
+func releases(keep func(*K8sVersionMatrix) bool) []*version.Version {
+	matrices := Active().Matrices
+	versions := make([]*version.Version, 0, len(matrices))
+	for key, m := range matrices {
+		if keep != nil && !keep(&m) {
+			continue
+		}
+		if v, err := version.Parse(key); err == nil {
+			versions = append(versions, v)
+		}
+	}
+	version.Sort(versions)
+	return versions
+}
+
+func minorOf(v *version.Version) *version.Version {
+	return &version.Version{Major: v.Major, Minor: v.Minor, Raw: v.ShortString()}
+}
+
 // GetComponentInfo returns component info for a specific K8s version
 func GetComponentInfo(k8sVersion, component string) (*ComponentInfo, bool) {
 	matrix, ok := GetMatrix(k8sVersion)
@@ -1,7 +1,11 @@
 package compatibility
 
 import (
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/pmady/kube-dependency-checker/pkg/version"
 )
 
 func TestGetMatrix(t *testing.T) {
@@ -55,25 +59,75 @@ func TestGetComponentInfo(t *testing.T) {
 }
 
 func TestGetSupportedVersions(t *testing.T) {
-	versions := GetSupportedVersions()
-	if len(versions) == 0 {
-		t.Error("GetSupportedVersions() returned empty slice")
-	}
-
-	// Check that expected versions are present
-	expectedVersions := []string{"1.28", "1.29", "1.30", "1.31", "1.32", "1.33"}
-	for _, expected := range expectedVersions {
-		found := false
-		for _, v := range versions {
-			if v == expected {
-				found = true
-				break
-			}
+	if got := join(GetSupportedVersions()); got != "1.28,1.29,1.30,1.31,1.32,1.33" {
+		t.Errorf("GetSupportedVersions() = %s, want 1.28 to 1.33 oldest first", got)
+	}
+}
+
+func TestArchivedVersions(t *testing.T) {
+	if got := join(GetArchivedVersions()); got != "1.25,1.26,1.27" {
+		t.Errorf("GetArchivedVersions() = %s, want 1.25,1.26,1.27", got)
+	}
+
+	matrix, ok := GetMatrix("1.25.16")
+	if !ok {
+		t.Fatal("GetMatrix(1.25.16) not found")
+	}
+	if want := "Kubernetes 1.25.16 is out of support: it reached end of life on 2023-10-28 and its data is archived"; matrix.SupportWarning() != want {
+		t.Errorf("SupportWarning() = %q, want %q", matrix.SupportWarning(), want)
+	}
+	if matrix, _ := GetMatrix("1.28"); matrix.SupportWarning() != "" {
+		t.Errorf("SupportWarning() for 1.28 = %q, want none", matrix.SupportWarning())
+	}
+}
+
+func TestVersionHelpers(t *testing.T) {
+	matrices := make(map[string]K8sVersionMatrix)
+	for _, key := range []string{"1.9", "1.10", "1.11", "1.99", "1.100", "2.0"} {
+		matrices[key] = K8sVersionMatrix{K8sVersion: key, Components: map[string]ComponentInfo{"etcd": {Name: "etcd"}}}
+	}
+	m := matrices["2.0"]
+	m.Archived = true // unusual, but it must not be the latest
+	matrices["2.0"] = m
+	Use(&Bundle{Generated: time.Now(), Matrices: matrices})
+	t.Cleanup(func() { Use(nil) })
+
+	if got := join(GetSupportedVersions()); got != "1.9,1.10,1.11,1.99,1.100" {
+		t.Errorf("GetSupportedVersions() = %s, want semantic order", got)
+	}
+	if got := Latest().ShortString(); got != "1.100" {
+		t.Errorf("Latest() = %s, want 1.100", got)
+	}
+
+	for _, tt := range []struct{ v, want string }{
+		{"1.10.3", "1.9"},
+		{"1.99", "1.11"},
+		{"2.0", "1.100"},
+		{"1.9", ""},
+	} {
+		v, _ := version.Parse(tt.v)
+		got := ""
+		if prev, ok := Previous(v); ok {
+			got = prev.ShortString()
 		}
-		if !found {
-			t.Errorf("GetSupportedVersions() missing expected version %s", expected)
+		if got != tt.want {
+			t.Errorf("Previous(%s) = %q, want %q", tt.v, got, tt.want)
 		}
 	}
+
+	from, _ := version.Parse("1.10.2")
+	to, _ := version.Parse("2.0")
+	if got := join(Range(from, to)); got != "1.10,1.11,1.99,1.100,2.0" {
+		t.Errorf("Range() = %s", got)
+	}
+}
+
+func join(versions []*version.Version) string {
+	names := make([]string, len(versions))
+	for i, v := range versions {
+		names[i] = v.ShortString()
+	}
+	return strings.Join(names, ",")
 }
 
 func TestComponentInfoFields(t *testing.T) {
@@ -106,3 +160,144 @@ func TestComponentInfoFields(t *testing.T) {
 		t.Errorf("kubelet MaxMinorSkew = %d, want 3", kubeletInfo.MaxMinorSkew)
 	}
 }
+
+func TestEmbeddedBundle(t *testing.T) {
+	b := Embedded()
+	if b.Source != SourceEmbedded {
+		t.Errorf("Embedded().Source = %q", b.Source)
+	}
+	if b.Generated.IsZero() {
+		t.Error("embedded bundle has no generated timestamp")
+	}
+	for key, m := range b.Matrices {
+		if m.K8sVersion != key {
+			t.Errorf("matrix %s has K8sVersion %s", key, m.K8sVersion)
+		}
+	}
+}
+
+func TestUse(t *testing.T) {
+	b, err := ParseBundle([]byte(`
+generated: 2099-01-01T00:00:00Z
+matrices:
+  "1.99":
+    k8sVersion: "1.99"
+    components:
+      etcd: {name: etcd, recommended: 3.6.4}
+`))
+	if err != nil {
+		t.Fatalf("ParseBundle() error = %v", err)
+	}
+	Use(b)
+	defer Use(nil)
+
+	if info, ok := GetComponentInfo("1.99", "etcd"); !ok || info.Recommended != "3.6.4" {
+		t.Errorf("GetComponentInfo(1.99, etcd) = %v, %v", info, ok)
+	}
+	if _, ok := GetMatrix("1.30"); ok {
+		t.Error("GetMatrix(1.30) found data that is not in the active bundle")
+	}
+
+	Use(nil)
+	if _, ok := GetMatrix("1.30"); !ok {
+		t.Error("GetMatrix(1.30) not found after restoring the embedded bundle")
+	}
+}
+
+func TestParseBundleInvalid(t *testing.T) {
+	for _, data := range []string{
+		"matrices: {}\n",
+		"generated: 2025-01-01T00:00:00Z\nmatrices: {}\n",
+		"generated: 2025-01-01T00:00:00Z\nmatrices:\n  \"1.30\": {k8sVersion: \"1.30\"}\n",
+		"generated: 2025-01-01T00:00:00Z\nmatrices:\n  \"1.30\": {k8sVersion: \"1.30\", endOfLife: soon, components: {etcd: {name: etcd}}}\n",
+		"generated: 2025-01-01T00:00:00Z\nmatrices:\n  \"1.30\": {k8sVersion: \"1.30\", components: {etcd: {name: etcd}}, node: {cgroupV1: legacy}}\n",
+		"generated: 2025-01-01T00:00:00Z\nmatrices:\n  \"1.30\": {k8sVersion: \"1.30\", components: {etcd: {name: etcd}}, node: {minKernel: five}}\n",
+		"matrices: [",
+	} {
+		if _, err := ParseBundle([]byte(data)); err == nil {
+			t.Errorf("ParseBundle(%q) expected error", data)
+		}
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	data, err := Embedded().Marshal(Header(embeddedData))
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(data) != string(embeddedData) {
+		t.Error("Marshal() of the embedded bundle differs from data/matrix.yaml")
+	}
+}
+
+func TestGetMatrixPatch(t *testing.T) {
+	b, err := ParseBundle([]byte(`
+generated: 2099-01-01T00:00:00Z
+matrices:
+  "1.30":
+    k8sVersion: "1.30"
+    components:
+      etcd: {name: etcd, version: 3.5.x, minVersion: 3.5.0, maxVersion: 3.5.99, recommended: 3.5.12}
+    patches:
+      "1.30.10":
+        components:
+          etcd: {recommended: 3.5.16}
+      "1.30.2":
+        components:
+          etcd: {recommended: 3.5.13, notes: bumped in 1.30.2}
+`))
+	if err != nil {
+		t.Fatalf("ParseBundle() error = %v", err)
+	}
+	Use(b)
+	defer Use(nil)
+
+	tests := []struct {
+		k8sVersion, recommended, notes string
+	}{
+		{"1.30", "3.5.12", ""},
+		{"1.30.0", "3.5.12", ""},
+		{"1.30.2", "3.5.13", "bumped in 1.30.2"},
+		{"v1.30.9", "3.5.13", "bumped in 1.30.2"},
+		{"1.30.10", "3.5.16", "bumped in 1.30.2"},
+		{"v1.30.2-eks-1", "3.5.13", "bumped in 1.30.2"},
+		{"1.30.10-gke.1", "3.5.16", "bumped in 1.30.2"},
+	}
+	for _, tt := range tests {
+		info, ok := GetComponentInfo(tt.k8sVersion, "etcd")
+		if !ok {
+			t.Fatalf("GetComponentInfo(%s) not found", tt.k8sVersion)
+		}
+		if info.Recommended != tt.recommended || info.Notes != tt.notes || info.MinVersion != "3.5.0" {
+			t.Errorf("GetComponentInfo(%s) = %+v", tt.k8sVersion, info)
+		}
+	}
+	if _, ok := GetMatrix("1.31.2"); ok {
+		t.Error("GetMatrix(1.31.2) found a release that is not in the data")
+	}
+	if _, err := ParseBundle([]byte("generated: 2099-01-01T00:00:00Z\nmatrices:\n  \"1.30\":\n    k8sVersion: \"1.30\"\n    components: {etcd: {name: etcd}}\n    patches: {\"1.31.1\": {components: {}}}\n")); err == nil {
+		t.Error("ParseBundle() accepted a patch entry of another minor")
+	}
+}
+
+func TestEmbeddedPatches(t *testing.T) {
+	tests := []struct {
+		k8sVersion, component, recommended string
+	}{
+		{"1.29.2", "etcd", "3.5.10"},
+		{"1.29.3", "etcd", "3.5.12"},
+		{"1.29.10", "etcd", "3.5.15"},
+		{"1.29.6", "coredns", "1.11.1"},
+		{"1.29.7", "coredns", "1.11.3"},
+	}
+	for _, tt := range tests {
+		m, ok := GetMatrix(tt.k8sVersion)
+		if !ok {
+			t.Fatalf("GetMatrix(%s) not found", tt.k8sVersion)
+		}
+		info := m.Components[tt.component]
+		if info.Recommended != tt.recommended || info.MinVersion == "" {
+			t.Errorf("GetMatrix(%s) %s = %+v, want recommended %s", tt.k8sVersion, tt.component, info, tt.recommended)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// Package componentconfig reads KubeletConfiguration and
+// KubeProxyConfiguration documents from configuration files, kubeadm
+// configurations and the ConfigMaps kubeadm stores them in.
+package componentconfig
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pmady/kube-dependency-checker/pkg/kubeadm"
+	"gopkg.in/yaml.v3"
+)
+
+// Configuration kinds and the components that read them
+const (
+	KindKubelet   = "KubeletConfiguration"
+	KindKubeProxy = "KubeProxyConfiguration"
+)
+
+var components = map[string]string{
+	KindKubelet:   "kubelet",
+	KindKubeProxy: "kube-proxy",
+}
+
+// Component returns the component that reads a configuration kind
+func Component(kind string) (string, bool) {
+	c, ok := components[kind]
+	return c, ok
+}
+
+// Document is a component configuration and the file it was read from
+type Document struct {
+	kubeadm.Document
+	Source string
+}
+
+// Load reads the component configurations of a file
+func Load(path string) ([]Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := Parse(path, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("%s: no %s or %s found", path, KindKubelet, KindKubeProxy)
+	}
+	return docs, nil
+}
+
+// Parse reads the component configurations of a multi-document file.
+// ConfigMaps, such as kube-system/kube-proxy and kubelet-config, are
+// searched for configurations in their data.
+func Parse(source string, data []byte) ([]Document, error) {
+	all, err := kubeadm.ParseDocuments(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	docs := make([]Document, 0)
+	for _, doc := range all {
+		switch {
+		case doc.Kind == "ConfigMap":
+			found, err := fromConfigMap(source, doc)
+			if err != nil {
+				return nil, err
+			}
+			docs = append(docs, found...)
+		case components[doc.Kind] != "":
+			docs = append(docs, Document{Document: doc, Source: source})
+		}
+	}
+	return docs, nil
+}
+
+// FromKubeadm returns the component configurations of a kubeadm
+// configuration file
+func FromKubeadm(cfg *kubeadm.Config, source string) []Document {
+	docs := make([]Document, 0)
+	for _, doc := range cfg.Documents {
+		if components[doc.Kind] != "" {
+			docs = append(docs, Document{Document: doc, Source: source})
+		}
+	}
+	return docs
+}
+
+func fromConfigMap(source string, cm kubeadm.Document) ([]Document, error) {
+	var configMap struct {
+		Metadata struct {
+			Name string `yaml:"name"`
+		} `yaml:"metadata"`
+		Data map[string]string `yaml:"data"`
+	}
+	if err := cm.Node.Decode(&configMap); err != nil {
+		return nil, fmt.Errorf("%s: invalid ConfigMap: %w", source, err)
+	}
+	keys := make([]string, 0, len(configMap.Data))
+	for key := range configMap.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	docs := make([]Document, 0)
+	for _, key := range keys {
+		found, err := kubeadm.ParseDocuments([]byte(configMap.Data[key]))
+		if err != nil {
+			// Not every key holds YAML, e.g. kube-proxy's kubeconfig.conf
+			continue
+		}
+		for _, doc := range found {
+			if components[doc.Kind] != "" {
+				docs = append(docs, Document{Document: doc, Source: fmt.Sprintf("%s (ConfigMap %s, %s)", source, configMap.Metadata.Name, key)})
+			}
+		}
+	}
+	return docs, nil
+}
+
+// Lookup returns the value at a dotted path, e.g. "memorySwap.swapBehavior"
+func (d Document) Lookup(path string) (*yaml.Node, bool) {
+	node := d.Node
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	for _, key := range strings.Split(path, ".") {
+		if node.Kind != yaml.MappingNode {
+			return nil, false
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				next = node.Content[i+1]
+				break
+			}
+		}
+		if next == nil {
+			return nil, false
+		}
+		node = next
+	}
+	return node, true
+}
@@ -0,0 +1,69 @@
+package componentconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const kubeProxyConfigMap = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: kube-proxy
+  namespace: kube-system
+data:
+  config.conf: |-
+    apiVersion: kubeproxy.config.k8s.io/v1alpha1
+    kind: KubeProxyConfiguration
+    mode: ipvs
+  kubeconfig.conf: |-
+    apiVersion: v1
+    kind: Config
+    clusters: [
+---
+apiVersion: kubelet.config.k8s.io/v1beta1
+kind: KubeletConfiguration
+memorySwap:
+  swapBehavior: LimitedSwap
+`
+
+func TestParse(t *testing.T) {
+	docs, err := Parse("configs.yaml", []byte(kubeProxyConfigMap))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 2 {
+		t.Fatalf("got %d documents, want 2", len(docs))
+	}
+
+	proxy := docs[0]
+	if proxy.Kind != KindKubeProxy || proxy.Source != "configs.yaml (ConfigMap kube-proxy, config.conf)" {
+		t.Errorf("docs[0] = %s from %q", proxy.Kind, proxy.Source)
+	}
+	if mode, ok := proxy.Lookup("mode"); !ok || mode.Value != "ipvs" {
+		t.Errorf("Lookup(mode) = %v, %v", mode, ok)
+	}
+
+	kubelet := docs[1]
+	if kubelet.Kind != KindKubelet || kubelet.APIVersion != "kubelet.config.k8s.io/v1beta1" || kubelet.Source != "configs.yaml" {
+		t.Errorf("docs[1] = %s %s from %q", kubelet.APIVersion, kubelet.Kind, kubelet.Source)
+	}
+	if swap, ok := kubelet.Lookup("memorySwap.swapBehavior"); !ok || swap.Value != "LimitedSwap" {
+		t.Errorf("Lookup(memorySwap.swapBehavior) = %v, %v", swap, ok)
+	}
+	for _, path := range []string{"cgroupDriver", "memorySwap.swapBehavior.mode", "kind.name"} {
+		if _, ok := kubelet.Lookup(path); ok {
+			t.Errorf("Lookup(%s) found a value", path)
+		}
+	}
+}
+
+func TestLoadWithoutConfigurations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pod.yaml")
+	if err := os.WriteFile(path, []byte("apiVersion: v1\nkind: Pod\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Load() of a file without configurations succeeded")
+	}
+}
@@ -0,0 +1,312 @@
+// Package config resolves command-line flag values from configuration
+// files and KDC_* environment variables.
+//
+// A configuration file is YAML whose keys are flag names. Top-level keys
+// apply to every command with that flag; a key named after a command holds
+// values for that command only:
+//
+//	output: json
+//	check:
+//	  workers: 10
+//	  inventory: [clusters/]
+//
+// Values are resolved with the precedence: command-line flag, environment
+// variable (KDC_CHECK_WORKERS, then KDC_WORKERS), repo-local file, user
+// file, flag default. Flags listed in Config.FlagOnly, such as those that
+// weaken data verification, are refused in configuration files, and those
+// in Config.UserOnly, such as the kubeconfig to use, in repo-local files.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the repo-local configuration file
+const FileName = ".kube-dependency-checker.yaml"
+
+// EnvPrefix prefixes the environment variables bound to flags
+const EnvPrefix = "KDC_"
+
+// Sources of a resolved setting other than a file path
+const (
+	SourceFlag    = "flag"
+	SourceDefault = "default"
+)
+
+// File is a parsed configuration file
+type File struct {
+	Path   string
+	Values map[string]interface{}
+	// RepoLocal marks a file found in the working tree rather than the
+	// user's own configuration
+	RepoLocal bool
+}
+
+// Config holds the configuration files, in increasing order of precedence,
+// and the environment used to resolve flags
+type Config struct {
+	Files  []*File
+	Getenv func(string) string
+	// FlagOnly are flags that only the command line or the environment may
+	// set; a configuration file setting one is an error
+	FlagOnly map[string]bool
+	// UserOnly are flags that a repo-local file may not set, because a
+	// cloned repository must not choose them for the user
+	UserOnly map[string]bool
+
+	applied map[*pflag.Flag]bool // flags set by Apply rather than on the command line
+}
+
+// Setting is the effective value of a flag and where it came from
+type Setting struct {
+	Name   string `json:"name" yaml:"name"`
+	Value  string `json:"value" yaml:"value"`
+	Source string `json:"source" yaml:"source"`
+
+	values []string // individual values for list flags
+}
+
+// UserConfigPath returns ~/.config/kube-dependency-checker/config.yaml,
+// honouring $XDG_CONFIG_HOME
+func UserConfigPath() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "kube-dependency-checker", "config.yaml"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "kube-dependency-checker", "config.yaml"), nil
+}
+
+// FindRepoConfig looks for FileName in dir and its parents, stopping at the
+// repository root (a directory containing .git). It returns "" when there
+// is none.
+func FindRepoConfig(dir string) string {
+	for {
+		path := filepath.Join(dir, FileName)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Load reads the user configuration file and the repo-local file found from
+// the working directory. userPath may be empty to skip the user file; a
+// missing user file is not an error.
+func Load(userPath, workDir string) (*Config, error) {
+	cfg := &Config{Getenv: os.Getenv}
+
+	if userPath != "" {
+		f, err := LoadFile(userPath)
+		switch {
+		case err == nil:
+			cfg.Files = append(cfg.Files, f)
+		case !errors.Is(err, fs.ErrNotExist):
+			return nil, err
+		}
+	}
+
+	if repoPath := FindRepoConfig(workDir); repoPath != "" {
+		f, err := LoadFile(repoPath)
+		if err != nil {
+			return nil, err
+		}
+		f.RepoLocal = true
+		cfg.Files = append(cfg.Files, f)
+	}
+	return cfg, nil
+}
+
+// LoadFile parses a configuration file
+func LoadFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("%s: invalid configuration: %w", path, err)
+	}
+	return &File{Path: path, Values: values}, nil
+}
+
+// EnvName returns the environment variable bound to a flag, e.g.
+// KDC_CHECK_K8S_VERSION for the k8s-version flag of the check command
+func EnvName(section []string, flag string) string {
+	parts := append(append([]string{}, section...), flag)
+	name := strings.Join(parts, "_")
+	return EnvPrefix + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+}
+
+// Resolve returns the effective value of every flag in the set for the
+// command at the section path (e.g. ["check"]), without changing the flags
+func (c *Config) Resolve(section []string, flags *pflag.FlagSet) ([]Setting, error) {
+	settings := make([]Setting, 0)
+	var resolveErr error
+	flags.VisitAll(func(f *pflag.Flag) {
+		if resolveErr != nil {
+			return
+		}
+		s, err := c.resolve(section, f)
+		if err != nil {
+			resolveErr = err
+			return
+		}
+		settings = append(settings, s)
+	})
+	if resolveErr != nil {
+		return nil, resolveErr
+	}
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Name < settings[j].Name })
+	return settings, nil
+}
+
+// Apply sets every flag that was not given on the command line from the
+// environment or the configuration files, marking it changed as if it had
+// been, and returns the effective settings
+func (c *Config) Apply(section []string, flags *pflag.FlagSet) ([]Setting, error) {
+	settings, err := c.Resolve(section, flags)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range settings {
+		if s.Source == SourceFlag || s.Source == SourceDefault {
+			continue
+		}
+		for _, v := range s.values {
+			if err := flags.Set(s.Name, v); err != nil {
+				return nil, fmt.Errorf("invalid value %q for %s from %s: %w", v, s.Name, s.Source, err)
+			}
+		}
+		if c.applied == nil {
+			c.applied = make(map[*pflag.Flag]bool)
+		}
+		c.applied[flags.Lookup(s.Name)] = true
+	}
+	return settings, nil
+}
+
+func (c *Config) resolve(section []string, f *pflag.Flag) (Setting, error) {
+	if f.Changed && !c.applied[f] {
+		return Setting{Name: f.Name, Value: f.Value.String(), Source: SourceFlag}, nil
+	}
+
+	getenv := c.Getenv
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+	names := []string{EnvName(nil, f.Name)}
+	if len(section) > 0 {
+		names = []string{EnvName(section, f.Name), names[0]}
+	}
+	for _, name := range names {
+		if v := getenv(name); v != "" {
+			values := []string{v}
+			if isList(f) {
+				values = splitList(v)
+			}
+			return Setting{Name: f.Name, Value: v, Source: "env " + name, values: values}, nil
+		}
+	}
+
+	for i := len(c.Files) - 1; i >= 0; i-- {
+		file := c.Files[i]
+		raw, ok := lookup(file.Values, section, f.Name)
+		if !ok {
+			continue
+		}
+		if c.FlagOnly[f.Name] {
+			return Setting{}, fmt.Errorf("%s: %s cannot be set in a configuration file, use --%s or %s",
+				file.Path, f.Name, f.Name, EnvName(nil, f.Name))
+		}
+		if file.RepoLocal && c.UserOnly[f.Name] {
+			return Setting{}, fmt.Errorf("%s: %s cannot be set in a repo-local configuration file, use --%s, %s or the user configuration file",
+				file.Path, f.Name, f.Name, EnvName(nil, f.Name))
+		}
+		values, err := toStrings(raw)
+		if err != nil {
+			return Setting{}, fmt.Errorf("%s: %s: %w", file.Path, f.Name, err)
+		}
+		return Setting{Name: f.Name, Value: strings.Join(values, ","), Source: file.Path, values: values}, nil
+	}
+
+	return Setting{Name: f.Name, Value: f.DefValue, Source: SourceDefault}, nil
+}
+
+// lookup finds a flag value in the command's section, falling back to the
+// enclosing sections and finally the top level
+func lookup(values map[string]interface{}, section []string, name string) (interface{}, bool) {
+	scopes := []map[string]interface{}{values}
+	current := values
+	for _, s := range section {
+		next, ok := current[s].(map[string]interface{})
+		if !ok {
+			break
+		}
+		scopes = append(scopes, next)
+		current = next
+	}
+
+	for i := len(scopes) - 1; i >= 0; i-- {
+		v, ok := scopes[i][name]
+		if !ok {
+			continue
+		}
+		// A map is the section of a subcommand, not a value
+		if _, isMap := v.(map[string]interface{}); isMap {
+			continue
+		}
+		return v, true
+	}
+	return nil, false
+}
+
+func toStrings(v interface{}) ([]string, error) {
+	switch t := v.(type) {
+	case nil:
+		return nil, fmt.Errorf("value is empty")
+	case []interface{}:
+		values := make([]string, 0, len(t))
+		for _, item := range t {
+			if _, ok := item.(map[string]interface{}); ok {
+				return nil, fmt.Errorf("list items must be scalars")
+			}
+			values = append(values, fmt.Sprint(item))
+		}
+		return values, nil
+	}
+	return []string{fmt.Sprint(v)}, nil
+}
+
+func isList(f *pflag.Flag) bool {
+	return strings.HasSuffix(f.Value.Type(), "Slice") || strings.HasSuffix(f.Value.Type(), "Array")
+}
+
+func splitList(v string) []string {
+	parts := strings.Split(v, ",")
+	values := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			values = append(values, p)
+		}
+	}
+	return values
+}
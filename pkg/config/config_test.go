@@ -0,0 +1,239 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testFlags() (*pflag.FlagSet, *string, *int, *[]string, *bool) {
+	flags := pflag.NewFlagSet("check", pflag.ContinueOnError)
+	output := flags.String("output", "table", "")
+	workers := flags.Int("workers", 5, "")
+	inventory := flags.StringArray("inventory", nil, "")
+	wide := flags.Bool("wide", false, "")
+	return flags, output, workers, inventory, wide
+}
+
+func TestApplyPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	userPath := filepath.Join(dir, "user", "config.yaml")
+	writeFile(t, userPath, "output: yaml\nworkers: 2\nwide: true\n")
+
+	repo := filepath.Join(dir, "repo")
+	writeFile(t, filepath.Join(repo, ".git", "HEAD"), "")
+	writeFile(t, filepath.Join(repo, FileName), "check:\n  workers: 10\n  inventory: [a.yaml, b.yaml]\n")
+	workDir := filepath.Join(repo, "sub")
+	if err := os.MkdirAll(workDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(userPath, workDir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(cfg.Files) != 2 {
+		t.Fatalf("Load() found %d files, want user and repo-local", len(cfg.Files))
+	}
+	cfg.Getenv = func(name string) string {
+		if name == "KDC_OUTPUT" {
+			return "json"
+		}
+		return ""
+	}
+
+	flags, output, workers, inventory, wide := testFlags()
+	if err := flags.Parse([]string{"--wide=false"}); err != nil {
+		t.Fatal(err)
+	}
+	settings, err := cfg.Apply([]string{"check"}, flags)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	if *output != "json" {
+		t.Errorf("output = %q, want json from the environment", *output)
+	}
+	if *workers != 10 {
+		t.Errorf("workers = %d, want 10 from the repo-local check section", *workers)
+	}
+	if len(*inventory) != 2 || (*inventory)[1] != "b.yaml" {
+		t.Errorf("inventory = %v, want [a.yaml b.yaml]", *inventory)
+	}
+	if *wide {
+		t.Error("wide = true, want the command-line value false")
+	}
+
+	sources := make(map[string]string)
+	for _, s := range settings {
+		sources[s.Name] = s.Source
+	}
+	want := map[string]string{
+		"output":    "env KDC_OUTPUT",
+		"workers":   filepath.Join(repo, FileName),
+		"inventory": filepath.Join(repo, FileName),
+		"wide":      SourceFlag,
+	}
+	for name, source := range want {
+		if sources[name] != source {
+			t.Errorf("source of %s = %q, want %q", name, sources[name], source)
+		}
+	}
+}
+
+func TestResolveCommandEnv(t *testing.T) {
+	cfg := &Config{Getenv: func(name string) string {
+		switch name {
+		case "KDC_CHECK_WORKERS":
+			return "8"
+		case "KDC_WORKERS":
+			return "3"
+		case "KDC_INVENTORY":
+			return "a.yaml, b.yaml"
+		}
+		return ""
+	}}
+
+	flags, _, workers, inventory, _ := testFlags()
+	if _, err := cfg.Apply([]string{"check"}, flags); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if *workers != 8 {
+		t.Errorf("workers = %d, want the command-specific KDC_CHECK_WORKERS", *workers)
+	}
+	if len(*inventory) != 2 {
+		t.Errorf("inventory = %v, want two entries from a comma-separated variable", *inventory)
+	}
+
+	settings, err := cfg.Resolve(nil, flags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range settings {
+		if s.Name == "workers" && s.Value != "3" {
+			t.Errorf("global workers = %q, want 3 from KDC_WORKERS", s.Value)
+		}
+	}
+}
+
+func TestApplyInvalidValue(t *testing.T) {
+	cfg := &Config{
+		Files:  []*File{{Path: "config.yaml", Values: map[string]interface{}{"workers": "many"}}},
+		Getenv: func(string) string { return "" },
+	}
+	flags, _, _, _, _ := testFlags()
+	if _, err := cfg.Apply(nil, flags); err == nil {
+		t.Error("Apply() expected error for a non-numeric workers value")
+	}
+}
+
+func TestApplyRequiredFromEnv(t *testing.T) {
+	cfg := &Config{Getenv: func(name string) string {
+		if name == "KDC_UPGRADE_FROM" {
+			return "1.28"
+		}
+		return ""
+	}}
+	cmd := &cobra.Command{Use: "upgrade"}
+	from := cmd.Flags().String("from", "", "")
+	if err := cmd.MarkFlagRequired("from"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := cfg.Apply([]string{"upgrade"}, cmd.Flags()); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if *from != "1.28" {
+		t.Errorf("from = %q, want 1.28 from the environment", *from)
+	}
+	if err := cmd.ValidateRequiredFlags(); err != nil {
+		t.Errorf("ValidateRequiredFlags() error = %v, want the environment to satisfy it", err)
+	}
+}
+
+func TestApplyFlagOnly(t *testing.T) {
+	cfg := &Config{
+		Files:    []*File{{Path: FileName, Values: map[string]interface{}{"check": map[string]interface{}{"wide": true}}}},
+		Getenv:   func(string) string { return "" },
+		FlagOnly: map[string]bool{"wide": true},
+	}
+	flags, _, _, _, wide := testFlags()
+	if _, err := cfg.Apply([]string{"check"}, flags); err == nil {
+		t.Error("Apply() accepted a flag-only setting from a configuration file")
+	}
+
+	// The environment and the command line may still set it
+	cfg.Getenv = func(name string) string {
+		if name == "KDC_WIDE" {
+			return "true"
+		}
+		return ""
+	}
+	if _, err := cfg.Apply([]string{"check"}, flags); err != nil || !*wide {
+		t.Errorf("Apply() from the environment = %v, wide = %t", err, *wide)
+	}
+}
+
+func TestApplyUserOnly(t *testing.T) {
+	dir := t.TempDir()
+	userPath := filepath.Join(dir, "user", "config.yaml")
+	writeFile(t, userPath, "check:\n  output: yaml\n")
+	writeFile(t, filepath.Join(dir, ".git", "HEAD"), "")
+	writeFile(t, filepath.Join(dir, FileName), "check:\n  output: json\n")
+
+	cfg, err := Load(userPath, dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Files[0].RepoLocal || !cfg.Files[1].RepoLocal {
+		t.Fatalf("RepoLocal = %t, %t, want only the repo-local file marked", cfg.Files[0].RepoLocal, cfg.Files[1].RepoLocal)
+	}
+	cfg.Getenv = func(string) string { return "" }
+	cfg.UserOnly = map[string]bool{"output": true}
+
+	flags, _, _, _, _ := testFlags()
+	if _, err := cfg.Apply([]string{"check"}, flags); err == nil {
+		t.Error("Apply() accepted a user-only setting from a repo-local file")
+	}
+
+	// The user file may set it
+	cfg.Files = cfg.Files[:1]
+	flags, output, _, _, _ := testFlags()
+	if _, err := cfg.Apply([]string{"check"}, flags); err != nil || *output != "yaml" {
+		t.Errorf("Apply() from the user file = %v, output = %q", err, *output)
+	}
+}
+
+func TestLoadMissingUserFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".git", "HEAD"), "")
+	cfg, err := Load(filepath.Join(dir, "missing.yaml"), dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(cfg.Files) != 0 {
+		t.Errorf("Load() files = %d, want 0", len(cfg.Files))
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	if got := EnvName(nil, "k8s-version"); got != "KDC_K8S_VERSION" {
+		t.Errorf("EnvName() = %s", got)
+	}
+	if got := EnvName([]string{"config", "view"}, "output"); got != "KDC_CONFIG_VIEW_OUTPUT" {
+		t.Errorf("EnvName() = %s", got)
+	}
+}
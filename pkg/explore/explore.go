@@ -0,0 +1,490 @@
+// Package explore implements the interactive compatibility explorer. The
+// explorer follows the model/update/view pattern: Update applies a key
+// press to a Model and View renders it as text, so both can be tested
+// without a terminal. Run connects them to one.
+package explore
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pmady/kube-dependency-checker/pkg/checker"
+	"github.com/pmady/kube-dependency-checker/pkg/compatibility"
+	"github.com/pmady/kube-dependency-checker/pkg/output"
+)
+
+// Key is a key press: one of the named keys below or a single character
+type Key string
+
+// Named keys
+const (
+	KeyUp    Key = "up"
+	KeyDown  Key = "down"
+	KeyLeft  Key = "left"
+	KeyRight Key = "right"
+	KeyEnter Key = "enter"
+	KeyBack  Key = "back"
+	KeyQuit  Key = "ctrl+c"
+)
+
+// Screen is a view of the explorer
+type Screen int
+
+// Screens
+const (
+	ScreenVersions Screen = iota
+	ScreenRelease
+	ScreenComponent
+	ScreenCompare
+	ScreenPlan
+)
+
+// Pending actions waiting for a second release to be chosen
+const (
+	actionNone    = ""
+	actionCompare = "compare"
+	actionPlan    = "plan"
+)
+
+// view is an entry of the navigation stack
+type view struct {
+	screen    Screen
+	cursor    int
+	version   string // release of ScreenRelease and ScreenComponent, left side of ScreenCompare, source of ScreenPlan
+	other     string // right side of ScreenCompare, target of ScreenPlan
+	component string // key of the component of ScreenComponent
+}
+
+// Model is the state of the explorer
+type Model struct {
+	// Versions are the releases in the data, newest first, archived
+	// releases last
+	Versions []string
+
+	stack   []view
+	pending string // action started on the versions screen
+	base    string // release the pending action started from
+	status  string
+	quit    bool
+}
+
+// New returns a model listing the releases of the active compatibility data
+func New() Model {
+	return Model{
+		Versions: checker.ListK8sVersions(checker.VersionsOptions{Archived: true}).K8sVersions,
+		stack:    []view{{screen: ScreenVersions}},
+	}
+}
+
+// Screen returns the current screen
+func (m Model) Screen() Screen {
+	return m.top().screen
+}
+
+// Quit reports whether the explorer should exit
+func (m Model) Quit() bool {
+	return m.quit
+}
+
+func (m Model) top() view {
+	return m.stack[len(m.stack)-1]
+}
+
+// push returns the model with v opened on top of the current screen. The
+// stack is copied so that earlier models are not changed.
+func (m Model) push(v view) Model {
+	m.stack = append(append([]view(nil), m.stack...), v)
+	return m
+}
+
+func (m Model) replaceTop(v view) Model {
+	stack := append([]view(nil), m.stack...)
+	stack[len(stack)-1] = v
+	m.stack = stack
+	return m
+}
+
+// Update returns the model after a key press
+func (m Model) Update(key Key) Model {
+	m.status = ""
+	switch key {
+	case KeyQuit, "q":
+		m.quit = true
+		return m
+	case KeyBack, "h":
+		if m.pending != actionNone {
+			m.pending, m.base = actionNone, ""
+			return m
+		}
+		if len(m.stack) > 1 {
+			m.stack = m.stack[:len(m.stack)-1]
+		}
+		return m
+	case KeyUp, "k":
+		return m.moveCursor(-1)
+	case KeyDown, "j":
+		return m.moveCursor(1)
+	}
+
+	v := m.top()
+	switch v.screen {
+	case ScreenVersions:
+		return m.updateVersions(key)
+	case ScreenRelease:
+		if key == KeyEnter || key == KeyRight || key == "l" {
+			components := sortedComponents(v.version)
+			if len(components) > 0 {
+				return m.push(view{screen: ScreenComponent, version: v.version, component: components[v.cursor]})
+			}
+		}
+	case ScreenPlan:
+		// The target of a plan is moved to a newer or older release in place
+		switch key {
+		case KeyLeft, "[":
+			return m.shiftTarget(1)
+		case KeyRight, "]":
+			return m.shiftTarget(-1)
+		}
+	}
+	return m
+}
+
+func (m Model) updateVersions(key Key) Model {
+	v := m.top()
+	if len(m.Versions) == 0 {
+		return m
+	}
+	selected := m.Versions[v.cursor]
+
+	switch key {
+	case "c", "u":
+		// The first release of a comparison or plan is remembered until the
+		// second is chosen with enter
+		m.pending, m.base = actionCompare, selected
+		if key == "u" {
+			m.pending = actionPlan
+		}
+		return m
+	case KeyEnter, KeyRight, "l":
+		switch m.pending {
+		case actionCompare:
+			m = m.push(view{screen: ScreenCompare, version: m.base, other: selected})
+		case actionPlan:
+			if _, err := checker.PlanUpgrade(m.base, selected); err != nil {
+				m.status = err.Error()
+				return m
+			}
+			m = m.push(view{screen: ScreenPlan, version: m.base, other: selected})
+		default:
+			return m.push(view{screen: ScreenRelease, version: selected})
+		}
+		m.pending, m.base = actionNone, ""
+	}
+	return m
+}
+
+// shiftTarget moves the target of the plan by delta positions in Versions,
+// skipping targets that cannot be planned
+func (m Model) shiftTarget(delta int) Model {
+	v := m.top()
+	for i := indexOf(m.Versions, v.other) + delta; i >= 0 && i < len(m.Versions); i += delta {
+		if _, err := checker.PlanUpgrade(v.version, m.Versions[i]); err == nil {
+			v.other = m.Versions[i]
+			return m.replaceTop(v)
+		}
+	}
+	m.status = "no other upgrade target in that direction"
+	return m
+}
+
+func (m Model) moveCursor(delta int) Model {
+	v := m.top()
+	n := m.rows(v)
+	if n == 0 {
+		return m
+	}
+	v.cursor += delta
+	if v.cursor < 0 {
+		v.cursor = 0
+	}
+	if v.cursor >= n {
+		v.cursor = n - 1
+	}
+	return m.replaceTop(v)
+}
+
+// rows returns the number of selectable rows of a screen
+func (m Model) rows(v view) int {
+	switch v.screen {
+	case ScreenVersions:
+		return len(m.Versions)
+	case ScreenRelease:
+		return len(sortedComponents(v.version))
+	}
+	return 0
+}
+
+// View renders the model
+func (m Model) View() string {
+	var b strings.Builder
+	v := m.top()
+	switch v.screen {
+	case ScreenVersions:
+		m.viewVersions(&b, v)
+	case ScreenRelease:
+		viewRelease(&b, v)
+	case ScreenComponent:
+		viewComponent(&b, v)
+	case ScreenCompare:
+		viewCompare(&b, v)
+	case ScreenPlan:
+		viewPlan(&b, v)
+	}
+
+	b.WriteString("\n")
+	if m.status != "" {
+		b.WriteString(m.status + "\n")
+	}
+	b.WriteString(m.help() + "\n")
+	return b.String()
+}
+
+func (m Model) help() string {
+	switch {
+	case m.pending == actionCompare:
+		return fmt.Sprintf("Compare %s with: ↑/↓ move  enter choose  esc cancel", m.base)
+	case m.pending == actionPlan:
+		return fmt.Sprintf("Upgrade %s to: ↑/↓ move  enter choose  esc cancel", m.base)
+	}
+	switch m.top().screen {
+	case ScreenVersions:
+		return "↑/↓ move  enter open  c compare  u plan upgrade  q quit"
+	case ScreenRelease:
+		return "↑/↓ move  enter open  esc back  q quit"
+	case ScreenPlan:
+		return "←/→ change target  esc back  q quit"
+	}
+	return "esc back  q quit"
+}
+
+func (m Model) viewVersions(b *strings.Builder, v view) {
+	b.WriteString("Kubernetes releases\n\n")
+	for i, k8sVersion := range m.Versions {
+		line := cursor(i == v.cursor) + k8sVersion
+		if matrix, ok := compatibility.GetMatrix(k8sVersion); ok {
+			switch {
+			case matrix.Archived:
+				line += "  (archived, end of life " + dash(matrix.EndOfLife) + ")"
+			case matrix.EndOfLife != "":
+				line += "  (end of life " + matrix.EndOfLife + ")"
+			}
+		}
+		b.WriteString(line + "\n")
+	}
+}
+
+func viewRelease(b *strings.Builder, v view) {
+	fmt.Fprintf(b, "Kubernetes %s\n\n", v.version)
+	matrix, ok := compatibility.GetMatrix(v.version)
+	if !ok {
+		return
+	}
+	tw := tabwriter.NewWriter(b, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "  COMPONENT\tVERSION\tMIN\tMAX\tRECOMMENDED")
+	for i, key := range sortedComponents(v.version) {
+		info := matrix.Components[key]
+		_, _ = fmt.Fprintf(tw, "%s%s\t%s\t%s\t%s\t%s\n", cursor(i == v.cursor), info.Name,
+			dash(info.Version), dash(info.MinVersion), dash(info.MaxVersion), dash(info.Recommended))
+	}
+	_ = tw.Flush()
+	if warning := matrix.SupportWarning(); warning != "" {
+		fmt.Fprintf(b, "\n⚠ %s\n", warning)
+	}
+}
+
+func viewComponent(b *strings.Builder, v view) {
+	matrix, ok := compatibility.GetMatrix(v.version)
+	if !ok {
+		return
+	}
+	info := matrix.Components[v.component]
+	fmt.Fprintf(b, "%s in Kubernetes %s\n\n", info.Name, v.version)
+
+	tw := tabwriter.NewWriter(b, 0, 0, 2, ' ', 0)
+	for _, field := range [][2]string{
+		{"Version", info.Version},
+		{"Min version", info.MinVersion},
+		{"Max version", info.MaxVersion},
+		{"Recommended", info.Recommended},
+		{"Skew policy", info.SkewPolicy},
+		{"Notes", info.Notes},
+	} {
+		if field[1] != "" {
+			_, _ = fmt.Fprintf(tw, "%s:\t%s\n", field[0], field[1])
+		}
+	}
+	if info.MaxMinorSkew != 0 {
+		_, _ = fmt.Fprintf(tw, "Max minor skew:\t%d\n", info.MaxMinorSkew)
+	}
+	_ = tw.Flush()
+
+	history, err := checker.ComponentVersions(v.component, checker.VersionsOptions{Archived: true})
+	if err != nil || len(history.Entries) == 0 {
+		return
+	}
+	b.WriteString("\nAcross releases\n")
+	tw = tabwriter.NewWriter(b, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "KUBERNETES\tRECOMMENDED\tMIN\tMAX")
+	for _, e := range history.Entries {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", e.K8sVersion, dash(recommended(e.Recommended, e.Version)), dash(e.MinVersion), dash(e.MaxVersion))
+	}
+	_ = tw.Flush()
+}
+
+func viewCompare(b *strings.Builder, v view) {
+	fmt.Fprintf(b, "Kubernetes %s compared with %s\n\n", v.version, v.other)
+	left, ok := compatibility.GetMatrix(v.version)
+	if !ok {
+		return
+	}
+	right, ok := compatibility.GetMatrix(v.other)
+	if !ok {
+		return
+	}
+
+	keys := make(map[string]bool)
+	for key := range left.Components {
+		keys[key] = true
+	}
+	for key := range right.Components {
+		keys[key] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	tw := tabwriter.NewWriter(b, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "  COMPONENT\t%s\t%s\n", v.version, v.other)
+	for _, key := range sorted {
+		l, r := left.Components[key], right.Components[key]
+		name := l.Name
+		if name == "" {
+			name = r.Name
+		}
+		from, to := recommended(l.Recommended, l.Version), recommended(r.Recommended, r.Version)
+		marker := "  "
+		if from != to {
+			marker = "* "
+		}
+		_, _ = fmt.Fprintf(tw, "%s%s\t%s\t%s\n", marker, name, dash(from), dash(to))
+	}
+	_ = tw.Flush()
+
+	gates := make([]string, 0)
+	for _, name := range compatibility.FeatureGateNames() {
+		l, lok := left.FeatureGates[name]
+		r, rok := right.FeatureGates[name]
+		if lok != rok || l != r {
+			gates = append(gates, fmt.Sprintf("  %s: %s → %s", name, gateState(l, lok), gateState(r, rok)))
+		}
+	}
+	if len(gates) > 0 {
+		b.WriteString("\nFeature gates\n" + strings.Join(gates, "\n") + "\n")
+	}
+}
+
+func viewPlan(b *strings.Builder, v view) {
+	fmt.Fprintf(b, "Upgrade plan from %s to %s\n\n", v.version, v.other)
+	plan, err := checker.PlanUpgrade(v.version, v.other)
+	if err != nil {
+		b.WriteString(err.Error() + "\n")
+		return
+	}
+	b.WriteString("Steps\n")
+	for _, step := range plan.Steps {
+		fmt.Fprintf(b, "  %d. %s → %s\n", step.Step, step.From, step.To)
+	}
+
+	b.WriteString("\nComponents\n")
+	tw := tabwriter.NewWriter(b, 0, 0, 2, ' ', 0)
+	for _, c := range plan.Components {
+		_, _ = fmt.Fprintf(tw, "%s%s\t%s\t→ %s\n", changeMarker(c), c.Name, dash(c.From), dash(c.To))
+	}
+	_ = tw.Flush()
+
+	for _, w := range plan.Warnings {
+		fmt.Fprintf(b, "\n⚠ %s", w)
+	}
+	if len(plan.Warnings) > 0 {
+		b.WriteString("\n")
+	}
+}
+
+// sortedComponents returns the component keys of a release, sorted
+func sortedComponents(k8sVersion string) []string {
+	matrix, ok := compatibility.GetMatrix(k8sVersion)
+	if !ok {
+		return nil
+	}
+	keys := make([]string, 0, len(matrix.Components))
+	for key := range matrix.Components {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func gateState(g compatibility.FeatureGate, ok bool) string {
+	if !ok {
+		return "-"
+	}
+	if g.Stage == compatibility.StageRemoved {
+		return g.Stage
+	}
+	state := fmt.Sprintf("%s, default %t", g.Stage, g.Default)
+	if g.LockedToDefault {
+		state += ", locked"
+	}
+	return state
+}
+
+func changeMarker(c output.ComponentChange) string {
+	if c.Changed {
+		return "* "
+	}
+	return "  "
+}
+
+func cursor(selected bool) string {
+	if selected {
+		return "> "
+	}
+	return "  "
+}
+
+func recommended(rec, v string) string {
+	if rec != "" {
+		return rec
+	}
+	return v
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func indexOf(list []string, s string) int {
+	for i, item := range list {
+		if item == s {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,176 @@
+package explore
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// press applies key presses to a model in order
+func press(m Model, keys ...Key) Model {
+	for _, key := range keys {
+		m = m.Update(key)
+	}
+	return m
+}
+
+// down returns n KeyDown presses
+func down(n int) []Key {
+	keys := make([]Key, n)
+	for i := range keys {
+		keys[i] = KeyDown
+	}
+	return keys
+}
+
+func TestNavigateReleaseAndComponent(t *testing.T) {
+	m := New()
+	if len(m.Versions) == 0 || m.Versions[0] != "1.33" {
+		t.Fatalf("Versions = %v, want newest first", m.Versions)
+	}
+
+	i := indexOf(m.Versions, "1.31")
+	m = press(m, append(down(i), KeyEnter)...)
+	if m.Screen() != ScreenRelease {
+		t.Fatalf("Screen() = %v, want ScreenRelease", m.Screen())
+	}
+	view := m.View()
+	if !strings.Contains(view, "Kubernetes 1.31") || !strings.Contains(view, "> containerd") {
+		t.Errorf("release view:\n%s", view)
+	}
+
+	// The cursor stops at the last component
+	m = press(m, down(100)...)
+	if !strings.Contains(m.View(), "> runc") {
+		t.Errorf("cursor is not on the last component:\n%s", m.View())
+	}
+
+	m = press(m, KeyEnter)
+	if m.Screen() != ScreenComponent {
+		t.Fatalf("Screen() = %v, want ScreenComponent", m.Screen())
+	}
+	view = m.View()
+	for _, want := range []string{"runc in Kubernetes 1.31", "Recommended:  1.1.14", "Across releases", "1.25"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("component view is missing %q:\n%s", want, view)
+		}
+	}
+
+	m = press(m, KeyBack, KeyBack)
+	if m.Screen() != ScreenVersions || !strings.Contains(m.View(), "> 1.31") {
+		t.Errorf("back did not return to the versions screen:\n%s", m.View())
+	}
+	if m = press(m, KeyBack); m.Screen() != ScreenVersions || m.Quit() {
+		t.Errorf("back on the first screen changed it")
+	}
+	if !press(m, "q").Quit() {
+		t.Error("q did not quit")
+	}
+}
+
+func TestUpdateDoesNotChangeEarlierModels(t *testing.T) {
+	m := New()
+	next := press(m, KeyDown, KeyEnter)
+	if m.Screen() != ScreenVersions || !strings.Contains(m.View(), "> 1.33") {
+		t.Errorf("Update changed the model it was called on:\n%s", m.View())
+	}
+	if next.Screen() != ScreenRelease {
+		t.Errorf("Screen() = %v, want ScreenRelease", next.Screen())
+	}
+}
+
+func TestCompare(t *testing.T) {
+	m := New()
+	m = press(m, append(down(indexOf(m.Versions, "1.30")), "c")...)
+	if !strings.Contains(m.View(), "Compare 1.30 with") {
+		t.Errorf("compare is not pending:\n%s", m.View())
+	}
+	m = press(m, append(down(indexOf(m.Versions, "1.28")-indexOf(m.Versions, "1.30")), KeyEnter)...)
+	if m.Screen() != ScreenCompare {
+		t.Fatalf("Screen() = %v, want ScreenCompare", m.Screen())
+	}
+	view := m.View()
+	for _, want := range []string{"Kubernetes 1.30 compared with 1.28", "* CoreDNS", "Feature gates"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("compare view is missing %q:\n%s", want, view)
+		}
+	}
+
+	// Escape cancels a pending comparison before leaving the screen
+	m = press(New(), "c", KeyBack)
+	if m.Screen() != ScreenVersions || strings.Contains(m.View(), "Compare") {
+		t.Errorf("compare was not cancelled:\n%s", m.View())
+	}
+}
+
+func TestPlan(t *testing.T) {
+	m := New()
+	from, to := indexOf(m.Versions, "1.29"), indexOf(m.Versions, "1.31")
+
+	// A target older than the source is refused on the versions screen
+	m = press(m, append(down(from), "u", KeyDown, KeyEnter)...)
+	if m.Screen() != ScreenVersions || !strings.Contains(m.View(), "must be newer") {
+		t.Fatalf("older target was not refused:\n%s", m.View())
+	}
+
+	m = press(New(), append(down(from), "u")...)
+	for i := from; i > to; i-- {
+		m = press(m, KeyUp)
+	}
+	m = press(m, KeyEnter)
+	if m.Screen() != ScreenPlan {
+		t.Fatalf("Screen() = %v, want ScreenPlan", m.Screen())
+	}
+	view := m.View()
+	for _, want := range []string{"Upgrade plan from 1.29 to 1.31", "1. 1.29 → 1.30", "2. 1.30 → 1.31"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("plan view is missing %q:\n%s", want, view)
+		}
+	}
+
+	// The target moves to newer releases with the right arrow and back
+	m = press(m, KeyRight)
+	if view := m.View(); !strings.Contains(view, "from 1.29 to 1.32") || !strings.Contains(view, "3. 1.31 → 1.32") {
+		t.Errorf("target did not move to 1.32:\n%s", view)
+	}
+	m = press(m, KeyLeft, KeyLeft, KeyLeft)
+	if view := m.View(); !strings.Contains(view, "from 1.29 to 1.30") || !strings.Contains(view, "no other upgrade target") {
+		t.Errorf("target did not stop at 1.30:\n%s", view)
+	}
+}
+
+func TestReadKey(t *testing.T) {
+	r := bufio.NewReader(bytes.NewBufferString("j\x1b[A\x1b[D\r\x7fq\x03"))
+	want := []Key{"j", KeyUp, KeyLeft, KeyEnter, KeyBack, "q", KeyQuit}
+	for _, w := range want {
+		got, err := ReadKey(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != w {
+			t.Errorf("ReadKey() = %q, want %q", got, w)
+		}
+	}
+
+	// A lone escape goes back
+	if got, _ := ReadKey(bufio.NewReader(bytes.NewBufferString("\x1b"))); got != KeyBack {
+		t.Errorf("ReadKey(esc) = %q, want %q", got, KeyBack)
+	}
+}
+
+func TestRun(t *testing.T) {
+	var out bytes.Buffer
+	if err := Run(New(), strings.NewReader("\rq"), &out); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	if !strings.Contains(got, "Kubernetes 1.33\r\n") || !strings.HasSuffix(got, leaveScreen) {
+		t.Errorf("Run() wrote:\n%q", got)
+	}
+
+	// Run stops at the end of the input
+	if err := Run(New(), strings.NewReader("jj"), &out); err != nil {
+		t.Errorf("Run() = %v at the end of the input", err)
+	}
+}
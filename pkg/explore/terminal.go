@@ -0,0 +1,101 @@
+package explore
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Escape sequences of the alternate screen
+const (
+	enterScreen = "\x1b[?1049h\x1b[?25l"
+	leaveScreen = "\x1b[?25h\x1b[?1049l"
+	clearScreen = "\x1b[H\x1b[2J"
+)
+
+// ReadKey reads a key press. Arrow keys arrive as escape sequences; an
+// escape that is not followed by a sequence is KeyBack.
+func ReadKey(r *bufio.Reader) (Key, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	switch c {
+	case 3: // ctrl+c, read as a byte in raw mode
+		return KeyQuit, nil
+	case '\r', '\n':
+		return KeyEnter, nil
+	case 127, '\b':
+		return KeyBack, nil
+	case 0x1b:
+		if r.Buffered() < 2 {
+			return KeyBack, nil
+		}
+		seq, err := r.Peek(2)
+		if err != nil || seq[0] != '[' && seq[0] != 'O' {
+			return KeyBack, nil
+		}
+		_, _ = r.Discard(2)
+		switch seq[1] {
+		case 'A':
+			return KeyUp, nil
+		case 'B':
+			return KeyDown, nil
+		case 'C':
+			return KeyRight, nil
+		case 'D':
+			return KeyLeft, nil
+		}
+		return "", nil
+	}
+	return Key(string(rune(c))), nil
+}
+
+// Run runs the explorer on a terminal until it quits or in is closed
+func Run(m Model, in io.Reader, out io.Writer) error {
+	_, _ = io.WriteString(out, enterScreen)
+	defer func() { _, _ = io.WriteString(out, leaveScreen) }()
+
+	r := bufio.NewReader(in)
+	for !m.Quit() {
+		// In raw mode a newline does not return the carriage
+		view := strings.ReplaceAll(m.View(), "\n", "\r\n")
+		if _, err := io.WriteString(out, clearScreen+view); err != nil {
+			return err
+		}
+		key, err := ReadKey(r)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if key != "" {
+			m = m.Update(key)
+		}
+	}
+	return nil
+}
+
+// MakeRaw switches the terminal on f to raw mode, so key presses are read
+// as they are typed, and returns a function restoring its previous mode.
+// It uses stty, which is available on every Unix-like system.
+func MakeRaw(f *os.File) (func(), error) {
+	state, err := stty(f, "-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty(f, "raw", "-echo"); err != nil {
+		return nil, err
+	}
+	return func() { _, _ = stty(f, strings.TrimSpace(state)) }, nil
+}
+
+func stty(f *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = f
+	out, err := cmd.Output()
+	return string(out), err
+}
@@ -0,0 +1,429 @@
+// Package exporter periodically evaluates a fleet of clusters and exposes the
+// results as Prometheus metrics.
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pmady/kube-dependency-checker/pkg/checker"
+	"github.com/pmady/kube-dependency-checker/pkg/compatibility"
+	"github.com/pmady/kube-dependency-checker/pkg/fleet"
+	"github.com/pmady/kube-dependency-checker/pkg/images"
+	"github.com/pmady/kube-dependency-checker/pkg/inventory"
+	"github.com/pmady/kube-dependency-checker/pkg/output"
+)
+
+// Source provides the clusters to evaluate
+type Source interface {
+	// Name identifies the source in logs
+	Name() string
+	// Clusters returns the observed state of each cluster
+	Clusters(ctx context.Context) ([]inventory.Cluster, error)
+}
+
+// ClusterError reports a cluster of a source that could not be inspected.
+// Sources return them, joined with errors.Join, next to the clusters that
+// could be; the other clusters of the source are still evaluated.
+type ClusterError struct {
+	Cluster string
+	Err     error
+}
+
+func (e *ClusterError) Error() string {
+	return fmt.Sprintf("cluster %s: %v", e.Cluster, e.Err)
+}
+
+func (e *ClusterError) Unwrap() error {
+	return e.Err
+}
+
+// FileSource reads clusters from an inventory file or directory. Clusters
+// with a pod list snapshot get their component versions from it.
+type FileSource struct {
+	Path string
+	// Images maps container images to components; nil uses images.Default()
+	Images *images.Mapper
+}
+
+// Name returns the inventory path
+func (s *FileSource) Name() string {
+	return s.Path
+}
+
+// Clusters loads the inventory from disk
+func (s *FileSource) Clusters(ctx context.Context) ([]inventory.Cluster, error) {
+	inv, err := inventory.Load(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	mapper := mapperOrDefault(s.Images)
+	clusters := make([]inventory.Cluster, 0, len(inv.Clusters))
+	var errs []error
+	for _, c := range inv.Clusters {
+		if err := c.ReadPods(mapper); err != nil {
+			errs = append(errs, &ClusterError{Cluster: c.Name, Err: err})
+			continue
+		}
+		clusters = append(clusters, c)
+	}
+	return clusters, errors.Join(errs...)
+}
+
+// TargetSource inspects fleet targets, such as kubeconfig contexts, on
+// every refresh
+type TargetSource struct {
+	Label   string
+	Targets []fleet.Target
+	Options fleet.Options
+}
+
+// Name returns the label of the targets
+func (s *TargetSource) Name() string {
+	return s.Label
+}
+
+// Clusters inspects the targets concurrently; unreachable ones are
+// returned as ClusterErrors
+func (s *TargetSource) Clusters(ctx context.Context) ([]inventory.Cluster, error) {
+	clusters, failures := fleet.Inspect(ctx, s.Targets, s.Options)
+	errs := make([]error, 0, len(failures))
+	for _, f := range failures {
+		errs = append(errs, &ClusterError{Cluster: f.Cluster, Err: errors.New(f.Error)})
+	}
+	return clusters, errors.Join(errs...)
+}
+
+// SnapshotSource reads a directory of saved kube-system pod lists ("kubectl
+// get pods -n kube-system -o yaml"), one per cluster, named after the
+// cluster (prod-east.yaml). The Kubernetes version is that of the
+// kube-apiserver pod. Pod lists hold no kubelet versions, so the clusters
+// have no node pools and no kubelet skew metric.
+type SnapshotSource struct {
+	Dir string
+	// Images maps container images to components; nil uses images.Default()
+	Images *images.Mapper
+}
+
+// Name returns the snapshot directory
+func (s *SnapshotSource) Name() string {
+	return s.Dir
+}
+
+// Clusters reads every .yaml, .yml and .json snapshot in the directory
+func (s *SnapshotSource) Clusters(ctx context.Context) ([]inventory.Cluster, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// The API server version is read from its image like the components
+	mapper := *mapperOrDefault(s.Images)
+	mapper.Components = make(map[string]string, len(mapper.Components)+1)
+	for repo, component := range mapperOrDefault(s.Images).Components {
+		mapper.Components[repo] = component
+	}
+	mapper.Components[kubeAPIServer] = kubeAPIServer
+
+	clusters := make([]inventory.Cluster, 0, len(entries))
+	var errs []error
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if e.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+		name := strings.TrimSuffix(e.Name(), ext)
+		path := filepath.Join(s.Dir, e.Name())
+		components, err := images.LoadPodComponents(path, &mapper)
+		if err != nil {
+			errs = append(errs, &ClusterError{Cluster: name, Err: err})
+			continue
+		}
+		k8sVersion, ok := components[kubeAPIServer]
+		if !ok {
+			errs = append(errs, &ClusterError{Cluster: name, Err: fmt.Errorf("%s: no %s pod", path, kubeAPIServer)})
+			continue
+		}
+		delete(components, kubeAPIServer)
+		clusters = append(clusters, inventory.Cluster{
+			Name:       name,
+			Source:     path,
+			K8sVersion: k8sVersion,
+			Components: components,
+		})
+	}
+	return clusters, errors.Join(errs...)
+}
+
+const kubeAPIServer = "kube-apiserver"
+
+func mapperOrDefault(m *images.Mapper) *images.Mapper {
+	if m == nil {
+		return images.Default()
+	}
+	return m
+}
+
+// clusterMetrics holds the evaluated state of one cluster
+type clusterMetrics struct {
+	name       string
+	k8sVersion string
+	result     *output.CheckResult
+	eol        time.Time
+	hasEOL     bool
+	skews      map[string]int // node pool name -> kubelet skew in minors
+}
+
+// Exporter evaluates clusters from its sources and serves the metrics
+type Exporter struct {
+	sources []Source
+	logger  *log.Logger
+	now     func() time.Time
+
+	mu            sync.RWMutex
+	clusters      []clusterMetrics
+	errors        map[string]string // cluster or source name -> last error
+	lastRefreshed time.Time
+}
+
+// New creates an exporter for the given sources
+func New(logger *log.Logger, sources ...Source) *Exporter {
+	return &Exporter{
+		sources: sources,
+		logger:  logger,
+		now:     time.Now,
+		errors:  make(map[string]string),
+	}
+}
+
+// Run refreshes the metrics every interval until ctx is cancelled
+func (e *Exporter) Run(ctx context.Context, interval time.Duration) {
+	e.Refresh(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.Refresh(ctx)
+		}
+	}
+}
+
+// Refresh evaluates all clusters from all sources. Clusters that cannot be
+// evaluated are reported through kdc_cluster_check_success.
+func (e *Exporter) Refresh(ctx context.Context) {
+	clusters := make([]clusterMetrics, 0)
+	errs := make(map[string]string)
+
+	for _, src := range e.sources {
+		states, err := src.Clusters(ctx)
+		if failed, ok := clusterErrors(err); ok {
+			// Only some clusters failed; the others are still evaluated
+			for _, ce := range failed {
+				e.logger.Printf("%v", ce)
+				errs[ce.Cluster] = ce.Err.Error()
+			}
+		} else if err != nil {
+			e.logger.Printf("source %s: %v", src.Name(), err)
+			errs[src.Name()] = err.Error()
+			continue
+		}
+
+		for i := range states {
+			m, err := evaluate(&states[i])
+			if err != nil {
+				e.logger.Printf("cluster %s: %v", states[i].Name, err)
+				errs[states[i].Name] = err.Error()
+				continue
+			}
+			clusters = append(clusters, m)
+		}
+	}
+
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i].name < clusters[j].name })
+
+	e.mu.Lock()
+	e.clusters = clusters
+	e.errors = errs
+	e.lastRefreshed = e.now()
+	e.mu.Unlock()
+}
+
+// clusterErrors returns the ClusterErrors of err, and whether err consists
+// of nothing else
+func clusterErrors(err error) ([]*ClusterError, bool) {
+	if err == nil {
+		return nil, false
+	}
+	all := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		all = joined.Unwrap()
+	}
+	failed := make([]*ClusterError, 0, len(all))
+	for _, e := range all {
+		var ce *ClusterError
+		if !errors.As(e, &ce) {
+			return nil, false
+		}
+		failed = append(failed, ce)
+	}
+	return failed, true
+}
+
+func evaluate(cluster *inventory.Cluster) (clusterMetrics, error) {
+	result, err := checker.Evaluate(cluster)
+	if err != nil {
+		return clusterMetrics{}, err
+	}
+
+	m := clusterMetrics{
+		name:       cluster.Name,
+		k8sVersion: result.K8sVersion,
+		result:     result,
+		skews:      make(map[string]int),
+	}
+
+	if matrix, ok := compatibility.GetMatrix(result.K8sVersion); ok {
+		m.eol, m.hasEOL = matrix.EndOfLifeDate()
+	}
+
+	for _, pool := range cluster.NodePools {
+		skew, err := checker.SkewMinors(cluster.K8sVersion, pool.KubeletVersion)
+		if err != nil {
+			continue
+		}
+		m.skews[pool.Name] = skew
+	}
+
+	return m, nil
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = e.WriteMetrics(w)
+}
+
+// WriteMetrics writes the current metrics in the Prometheus text format
+func (e *Exporter) WriteMetrics(w io.Writer) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	mw := &metricWriter{w: w}
+	now := e.now()
+
+	mw.header("kdc_component_compatible", "Whether the component version is compatible with the cluster's Kubernetes version (1) or not (0). Components with unknown versions are omitted.")
+	for _, c := range e.clusters {
+		for _, comp := range c.result.Components {
+			switch comp.Status {
+			case checker.StatusCompatible:
+				mw.sample("kdc_component_compatible", 1, "cluster", c.name, "component", strings.ToLower(comp.Name))
+			case checker.StatusIncompatible:
+				mw.sample("kdc_component_compatible", 0, "cluster", c.name, "component", strings.ToLower(comp.Name))
+			}
+		}
+	}
+
+	mw.header("kdc_k8s_days_until_eol", "Days until the cluster's Kubernetes minor version reaches upstream end of life. Negative once past end of life.")
+	for _, c := range e.clusters {
+		if !c.hasEOL {
+			continue
+		}
+		days := math.Floor(c.eol.Sub(now).Hours() / 24)
+		mw.sample("kdc_k8s_days_until_eol", days, "cluster", c.name, "k8s_version", c.k8sVersion)
+	}
+
+	mw.header("kdc_kubelet_skew_minors", "Number of minor versions the node pool's kubelet lags behind kube-apiserver.")
+	for _, c := range e.clusters {
+		pools := make([]string, 0, len(c.skews))
+		for pool := range c.skews {
+			pools = append(pools, pool)
+		}
+		sort.Strings(pools)
+		for _, pool := range pools {
+			mw.sample("kdc_kubelet_skew_minors", float64(c.skews[pool]), "cluster", c.name, "nodepool", pool)
+		}
+	}
+
+	mw.header("kdc_cluster_check_success", "Whether the cluster could be evaluated (1) or not (0).")
+	names := make([]string, 0, len(e.clusters)+len(e.errors))
+	success := make(map[string]bool)
+	for _, c := range e.clusters {
+		names = append(names, c.name)
+		success[c.name] = true
+	}
+	for name := range e.errors {
+		if _, ok := success[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := 0.0
+		if success[name] {
+			value = 1
+		}
+		mw.sample("kdc_cluster_check_success", value, "cluster", name)
+	}
+
+	if !e.lastRefreshed.IsZero() {
+		mw.header("kdc_last_refresh_timestamp_seconds", "Unix time of the last evaluation.")
+		mw.sample("kdc_last_refresh_timestamp_seconds", float64(e.lastRefreshed.Unix()))
+	}
+
+	return mw.err
+}
+
+// metricWriter writes gauges in the Prometheus text exposition format
+type metricWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (m *metricWriter) header(name, help string) {
+	m.printf("# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+}
+
+func (m *metricWriter) sample(name string, value float64, labels ...string) {
+	var b strings.Builder
+	b.WriteString(name)
+	if len(labels) > 0 {
+		b.WriteString("{")
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			fmt.Fprintf(&b, "%s=\"%s\"", labels[i], escapeLabel(labels[i+1]))
+		}
+		b.WriteString("}")
+	}
+	m.printf("%s %s\n", b.String(), strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+func (m *metricWriter) printf(format string, args ...interface{}) {
+	if m.err != nil {
+		return
+	}
+	_, m.err = fmt.Fprintf(m.w, format, args...)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
@@ -0,0 +1,193 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pmady/kube-dependency-checker/pkg/fleet"
+	"github.com/pmady/kube-dependency-checker/pkg/inventory"
+)
+
+type staticSource struct {
+	clusters []inventory.Cluster
+	err      error
+}
+
+func (s *staticSource) Name() string { return "static" }
+
+func (s *staticSource) Clusters(ctx context.Context) ([]inventory.Cluster, error) {
+	return s.clusters, s.err
+}
+
+func newTestExporter(sources ...Source) *Exporter {
+	e := New(log.New(io.Discard, "", 0), sources...)
+	e.now = func() time.Time { return time.Date(2025, 6, 18, 12, 0, 0, 0, time.UTC) }
+	return e
+}
+
+func TestWriteMetrics(t *testing.T) {
+	src := &staticSource{clusters: []inventory.Cluster{
+		{
+			Name:       "prod",
+			K8sVersion: "1.30.4",
+			Components: map[string]string{"etcd": "3.5.12", "coredns": "1.9.3"},
+			NodePools: []inventory.NodePool{
+				{Name: "default", KubeletVersion: "1.30.2"},
+				{Name: "legacy", KubeletVersion: "1.27.9"},
+			},
+		},
+		{Name: "broken", K8sVersion: "1.20.0"},
+	}}
+
+	e := newTestExporter(src)
+	e.Refresh(context.Background())
+
+	var b strings.Builder
+	if err := e.WriteMetrics(&b); err != nil {
+		t.Fatalf("WriteMetrics() error = %v", err)
+	}
+	got := b.String()
+
+	want := []string{
+		`kdc_component_compatible{cluster="prod",component="etcd"} 1`,
+		`kdc_component_compatible{cluster="prod",component="coredns"} 0`,
+		`kdc_k8s_days_until_eol{cluster="prod",k8s_version="1.30"} 9`,
+		`kdc_kubelet_skew_minors{cluster="prod",nodepool="default"} 0`,
+		`kdc_kubelet_skew_minors{cluster="prod",nodepool="legacy"} 3`,
+		`kdc_cluster_check_success{cluster="broken"} 0`,
+		`kdc_cluster_check_success{cluster="prod"} 1`,
+		`# TYPE kdc_component_compatible gauge`,
+	}
+	for _, line := range want {
+		if !strings.Contains(got, line+"\n") {
+			t.Errorf("metrics missing line %q\ngot:\n%s", line, got)
+		}
+	}
+
+	// containerd has no observed version and must not be reported
+	if strings.Contains(got, `component="containerd"`) {
+		t.Error("metrics contain a sample for a component with unknown version")
+	}
+}
+
+func TestRefreshSourceError(t *testing.T) {
+	e := newTestExporter(&staticSource{err: errors.New("unreachable")})
+	e.Refresh(context.Background())
+
+	var b strings.Builder
+	if err := e.WriteMetrics(&b); err != nil {
+		t.Fatalf("WriteMetrics() error = %v", err)
+	}
+	if !strings.Contains(b.String(), `kdc_cluster_check_success{cluster="static"} 0`) {
+		t.Errorf("metrics do not report the failed source:\n%s", b.String())
+	}
+}
+
+const apiServerPods = `apiVersion: v1
+kind: List
+items:
+  - kind: Pod
+    spec:
+      containers:
+        - name: kube-apiserver
+          image: registry.k8s.io/kube-apiserver:v1.30.4
+  - kind: Pod
+    spec:
+      containers:
+        - name: etcd
+          image: registry.k8s.io/etcd:3.4.27-0
+`
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSnapshotSource(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "prod-east.yaml"), apiServerPods)
+	writeFile(t, filepath.Join(dir, "managed.yaml"), "kind: List\nitems: []\n")
+	writeFile(t, filepath.Join(dir, "README.md"), "not a snapshot")
+
+	e := newTestExporter(&SnapshotSource{Dir: dir})
+	e.Refresh(context.Background())
+
+	var b strings.Builder
+	if err := e.WriteMetrics(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`kdc_component_compatible{cluster="prod-east",component="etcd"} 0`,
+		`kdc_k8s_days_until_eol{cluster="prod-east",k8s_version="1.30"} 9`,
+		`kdc_cluster_check_success{cluster="managed"} 0`,
+		`kdc_cluster_check_success{cluster="prod-east"} 1`,
+	} {
+		if !strings.Contains(b.String(), line+"\n") {
+			t.Errorf("metrics missing line %q\ngot:\n%s", line, b.String())
+		}
+	}
+	if strings.Contains(b.String(), `component="kube-apiserver"`) || strings.Contains(b.String(), `cluster="README"`) {
+		t.Errorf("metrics report the API server or a non-snapshot file:\n%s", b.String())
+	}
+}
+
+func TestFileSourceReadsPodSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "pods.yaml"), apiServerPods)
+	path := filepath.Join(dir, "fleet.yaml")
+	writeFile(t, path, "clusters:\n  - name: prod\n    k8sVersion: 1.30.4\n    pods: pods.yaml\n")
+
+	clusters, err := (&FileSource{Path: path}).Clusters(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clusters) != 1 || clusters[0].Components["etcd"] != "3.4.27" {
+		t.Errorf("Clusters() = %+v, want etcd 3.4.27 from the snapshot", clusters)
+	}
+}
+
+func TestTargetSourcePartialFailure(t *testing.T) {
+	src := &TargetSource{
+		Label: "kubeconfig",
+		Targets: []fleet.Target{
+			fleet.StaticTarget(inventory.Cluster{Name: "prod", K8sVersion: "1.30.4", Components: map[string]string{"etcd": "3.5.12"}}),
+			{Name: "down", Inspect: func(ctx context.Context) (*inventory.Cluster, error) {
+				return nil, errors.New("connection refused")
+			}},
+		},
+	}
+	e := newTestExporter(src)
+	e.Refresh(context.Background())
+
+	var b strings.Builder
+	if err := e.WriteMetrics(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`kdc_component_compatible{cluster="prod",component="etcd"} 1`,
+		`kdc_cluster_check_success{cluster="down"} 0`,
+		`kdc_cluster_check_success{cluster="prod"} 1`,
+	} {
+		if !strings.Contains(b.String(), line+"\n") {
+			t.Errorf("metrics missing line %q\ngot:\n%s", line, b.String())
+		}
+	}
+	if strings.Contains(b.String(), `cluster="kubeconfig"`) {
+		t.Errorf("a failed target was reported as a failed source:\n%s", b.String())
+	}
+}
+
+func TestEscapeLabel(t *testing.T) {
+	if got := escapeLabel("a\"b\\c\nd"); got != `a\"b\\c\nd` {
+		t.Errorf("escapeLabel() = %s", got)
+	}
+}
@@ -0,0 +1,130 @@
+// Package flags reads the command line flags Kubernetes components are
+// started with, from kubeadm static pod manifests and from the environment
+// files that pass flags to the kubelet, such as
+// /var/lib/kubelet/kubeadm-flags.env and /etc/default/kubelet.
+package flags
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pmady/kube-dependency-checker/pkg/images"
+	"gopkg.in/yaml.v3"
+)
+
+// Usage is a flag passed to a component
+type Usage struct {
+	Component string `json:"component" yaml:"component"`
+	Flag      string `json:"flag" yaml:"flag"`
+	Source    string `json:"source" yaml:"source"`
+}
+
+// Load reads the flags of a file, or of every file in a directory such as
+// /etc/kubernetes/manifests
+func Load(path string) ([]Usage, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	paths := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		paths = paths[:0]
+		for _, e := range entries {
+			if !e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+				paths = append(paths, filepath.Join(path, e.Name()))
+			}
+		}
+		sort.Strings(paths)
+	}
+
+	usages := make([]Usage, 0)
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		u, err := Parse(p, data)
+		if err != nil {
+			return nil, err
+		}
+		usages = append(usages, u...)
+	}
+	return usages, nil
+}
+
+// Parse reads the flags of a static pod manifest or, for any other file, of
+// the *ARGS variables of a kubelet environment file
+func Parse(name string, data []byte) ([]Usage, error) {
+	var pod images.Pod
+	if err := yaml.Unmarshal(data, &pod); err == nil && pod.Kind == "Pod" {
+		return parseManifest(name, pod), nil
+	}
+	return parseEnvFile(name, data)
+}
+
+// parseManifest reads the command and args of each container, naming the
+// component after the container's command
+func parseManifest(name string, pod images.Pod) []Usage {
+	usages := make([]Usage, 0)
+	for _, c := range pod.Spec.Containers {
+		argv := append(append([]string{}, c.Command...), c.Args...)
+		if len(argv) == 0 {
+			continue
+		}
+		component := c.Name
+		if len(c.Command) > 0 {
+			component = filepath.Base(c.Command[0])
+		}
+		usages = append(usages, fromArgs(component, name, argv)...)
+	}
+	return usages
+}
+
+// parseEnvFile reads shell or systemd assignments of variables whose name
+// ends in ARGS, e.g. KUBELET_KUBEADM_ARGS="--node-ip=10.0.0.1"
+func parseEnvFile(name string, data []byte) ([]Usage, error) {
+	usages := make([]Usage, 0)
+	found := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		// systemd drop-ins quote the whole assignment
+		line = strings.TrimPrefix(line, "Environment=")
+		line = strings.Trim(line, `"`)
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || !strings.HasSuffix(key, "ARGS") {
+			continue
+		}
+		found = true
+		usages = append(usages, fromArgs("kubelet", name, strings.Fields(strings.Trim(value, `"'`)))...)
+	}
+	if !found {
+		return nil, fmt.Errorf("%s: neither a static pod manifest nor a kubelet environment file", name)
+	}
+	return usages, nil
+}
+
+func fromArgs(component, source string, argv []string) []Usage {
+	usages := make([]Usage, 0)
+	for _, arg := range argv {
+		if !strings.HasPrefix(arg, "--") || arg == "--" {
+			continue
+		}
+		flag, _, _ := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		usages = append(usages, Usage{Component: component, Flag: flag, Source: source})
+	}
+	return usages
+}
@@ -0,0 +1,75 @@
+package flags
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const controllerManagerManifest = `apiVersion: v1
+kind: Pod
+metadata:
+  name: kube-controller-manager
+  namespace: kube-system
+spec:
+  containers:
+  - name: kube-controller-manager
+    image: registry.k8s.io/kube-controller-manager:v1.28.4
+    command:
+    - kube-controller-manager
+    - --pod-eviction-timeout=5m
+    - --leader-elect
+    args: ["--v=2", "--", "positional"]
+`
+
+const kubeletEnv = `# Written by kubeadm
+KUBELET_KUBEADM_ARGS="--container-runtime-endpoint=unix:///run/containerd/containerd.sock --pod-infra-container-image=registry.k8s.io/pause:3.9"
+export KUBELET_EXTRA_ARGS='--max-pods 110'
+OTHER="--not-a-kubelet-flag"
+`
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string]string{
+		"kube-controller-manager.yaml": controllerManagerManifest,
+		"kubeadm-flags.env":            kubeletEnv,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	usages, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := []Usage{
+		{"kube-controller-manager", "pod-eviction-timeout", filepath.Join(dir, "kube-controller-manager.yaml")},
+		{"kube-controller-manager", "leader-elect", filepath.Join(dir, "kube-controller-manager.yaml")},
+		{"kube-controller-manager", "v", filepath.Join(dir, "kube-controller-manager.yaml")},
+		{"kubelet", "container-runtime-endpoint", filepath.Join(dir, "kubeadm-flags.env")},
+		{"kubelet", "pod-infra-container-image", filepath.Join(dir, "kubeadm-flags.env")},
+		{"kubelet", "max-pods", filepath.Join(dir, "kubeadm-flags.env")},
+	}
+	if len(usages) != len(want) {
+		t.Fatalf("Load() = %+v, want %+v", usages, want)
+	}
+	for i := range want {
+		if usages[i] != want[i] {
+			t.Errorf("usage %d = %+v, want %+v", i, usages[i], want[i])
+		}
+	}
+}
+
+func TestParseSystemdDropIn(t *testing.T) {
+	usages, err := Parse("10-kubeadm.conf", []byte("[Service]\nEnvironment=\"KUBELET_EXTRA_ARGS=--node-ip=10.0.0.1\"\n"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(usages) != 1 || usages[0].Flag != "node-ip" || usages[0].Component != "kubelet" {
+		t.Errorf("Parse() = %+v", usages)
+	}
+	if _, err := Parse("notes.txt", []byte("nothing to see\n")); err == nil {
+		t.Error("Parse() expected error for a file without flags")
+	}
+}
@@ -0,0 +1,176 @@
+// Package fleet checks many clusters concurrently and aggregates the results.
+package fleet
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pmady/kube-dependency-checker/pkg/checker"
+	"github.com/pmady/kube-dependency-checker/pkg/inventory"
+	"github.com/pmady/kube-dependency-checker/pkg/output"
+)
+
+// DefaultWorkers is the default number of clusters checked in parallel
+const DefaultWorkers = 5
+
+// DefaultTimeout is the default time allowed for checking one cluster
+const DefaultTimeout = 30 * time.Second
+
+// Target is a cluster to check
+type Target struct {
+	Name string
+	// Inspect returns the observed state of the cluster
+	Inspect func(ctx context.Context) (*inventory.Cluster, error)
+}
+
+// Options controls how a fleet is checked
+type Options struct {
+	Workers int
+	Timeout time.Duration
+}
+
+// StaticTarget returns a target for an already known cluster state
+func StaticTarget(cluster inventory.Cluster) Target {
+	return Target{
+		Name: cluster.Name,
+		Inspect: func(ctx context.Context) (*inventory.Cluster, error) {
+			return &cluster, nil
+		},
+	}
+}
+
+// Check inspects and evaluates every target using a bounded worker pool.
+// Each cluster gets its own timeout; clusters that fail are reported as
+// failures while the remaining results are still returned.
+func Check(ctx context.Context, targets []Target, opts Options) *output.FleetResult {
+	outcomes := run(ctx, targets, opts, func(ctx context.Context, target Target, timeout time.Duration) (interface{}, error) {
+		cluster, err := inspectTarget(ctx, target, timeout)
+		if err != nil {
+			return nil, err
+		}
+		return checker.Evaluate(cluster)
+	})
+
+	fleet := &output.FleetResult{
+		Clusters: make([]output.CheckResult, 0, len(targets)),
+	}
+	for i, o := range outcomes {
+		if o.err != nil {
+			fleet.Failures = append(fleet.Failures, output.ClusterFailure{
+				Cluster: targets[i].Name,
+				Error:   o.err.Error(),
+			})
+			continue
+		}
+		fleet.Clusters = append(fleet.Clusters, *o.value.(*output.CheckResult))
+	}
+	fleet.Summary = Summarize(fleet)
+
+	return fleet
+}
+
+// Inspect inspects every target like Check, without evaluating them. It
+// returns the clusters that were inspected and the targets that failed.
+func Inspect(ctx context.Context, targets []Target, opts Options) ([]inventory.Cluster, []output.ClusterFailure) {
+	outcomes := run(ctx, targets, opts, func(ctx context.Context, target Target, timeout time.Duration) (interface{}, error) {
+		return inspectTarget(ctx, target, timeout)
+	})
+
+	clusters := make([]inventory.Cluster, 0, len(targets))
+	var failures []output.ClusterFailure
+	for i, o := range outcomes {
+		if o.err != nil {
+			failures = append(failures, output.ClusterFailure{Cluster: targets[i].Name, Error: o.err.Error()})
+			continue
+		}
+		clusters = append(clusters, *o.value.(*inventory.Cluster))
+	}
+	return clusters, failures
+}
+
+type outcome struct {
+	value interface{}
+	err   error
+}
+
+// run calls fn for every target using a bounded worker pool and returns
+// the outcomes in target order
+func run(ctx context.Context, targets []Target, opts Options, fn func(context.Context, Target, time.Duration) (interface{}, error)) []outcome {
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultWorkers
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	outcomes := make([]outcome, len(targets))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < opts.Workers && w < len(targets); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				value, err := fn(ctx, targets[i], opts.Timeout)
+				outcomes[i] = outcome{value: value, err: err}
+			}
+		}()
+	}
+	for i := range targets {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return outcomes
+}
+
+// Summarize counts clusters by outcome
+func Summarize(fleet *output.FleetResult) output.FleetSummary {
+	summary := output.FleetSummary{
+		TotalClusters:       len(fleet.Clusters) + len(fleet.Failures),
+		UnreachableClusters: len(fleet.Failures),
+	}
+	for _, c := range fleet.Clusters {
+		if c.Summary.IncompatibleCount > 0 {
+			summary.IncompatibleClusters++
+		} else {
+			summary.CompatibleClusters++
+		}
+	}
+	return summary
+}
+
+// inspectTarget inspects a target within its timeout
+func inspectTarget(ctx context.Context, target Target, timeout time.Duration) (*inventory.Cluster, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type inspected struct {
+		cluster *inventory.Cluster
+		err     error
+	}
+	done := make(chan inspected, 1)
+	go func() {
+		cluster, err := target.Inspect(ctx)
+		done <- inspected{cluster: cluster, err: err}
+	}()
+
+	// Enforce the timeout even if Inspect ignores ctx
+	var cluster *inventory.Cluster
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timed out after %s", timeout)
+	case r := <-done:
+		if r.err != nil {
+			return nil, r.err
+		}
+		cluster = r.cluster
+	}
+
+	if cluster.Name == "" {
+		cluster.Name = target.Name
+	}
+	return cluster, nil
+}
@@ -0,0 +1,73 @@
+package fleet
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pmady/kube-dependency-checker/pkg/inventory"
+)
+
+func TestCheckPartialResults(t *testing.T) {
+	targets := []Target{
+		StaticTarget(inventory.Cluster{Name: "ok", K8sVersion: "1.30.4", Components: map[string]string{"etcd": "3.5.12"}}),
+		StaticTarget(inventory.Cluster{Name: "drift", K8sVersion: "1.30.4", Components: map[string]string{"etcd": "3.4.27"}}),
+		{Name: "down", Inspect: func(ctx context.Context) (*inventory.Cluster, error) {
+			return nil, errors.New("connection refused")
+		}},
+		{Name: "slow", Inspect: func(ctx context.Context) (*inventory.Cluster, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}},
+	}
+
+	result := Check(context.Background(), targets, Options{Workers: 2, Timeout: 50 * time.Millisecond})
+
+	if len(result.Clusters) != 2 {
+		t.Fatalf("Check() returned %d cluster results, want 2", len(result.Clusters))
+	}
+	if result.Clusters[0].Cluster != "ok" || result.Clusters[1].Cluster != "drift" {
+		t.Errorf("cluster order = %s, %s; want ok, drift", result.Clusters[0].Cluster, result.Clusters[1].Cluster)
+	}
+	if len(result.Failures) != 2 || result.Failures[0].Cluster != "down" || result.Failures[1].Cluster != "slow" {
+		t.Errorf("Failures = %+v, want down and slow", result.Failures)
+	}
+
+	want := struct{ total, compatible, incompatible, unreachable int }{4, 1, 1, 2}
+	s := result.Summary
+	if s.TotalClusters != want.total || s.CompatibleClusters != want.compatible ||
+		s.IncompatibleClusters != want.incompatible || s.UnreachableClusters != want.unreachable {
+		t.Errorf("Summary = %+v, want %+v", s, want)
+	}
+}
+
+func TestCheckBoundedWorkers(t *testing.T) {
+	var running, peak int32
+	inspect := func(ctx context.Context) (*inventory.Cluster, error) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return &inventory.Cluster{K8sVersion: "1.30.0"}, nil
+	}
+
+	targets := make([]Target, 10)
+	for i := range targets {
+		targets[i] = Target{Name: "c", Inspect: inspect}
+	}
+
+	result := Check(context.Background(), targets, Options{Workers: 3, Timeout: time.Second})
+	if len(result.Clusters) != 10 {
+		t.Errorf("Check() returned %d results, want 10", len(result.Clusters))
+	}
+	if peak > 3 {
+		t.Errorf("peak concurrency = %d, want at most 3", peak)
+	}
+}
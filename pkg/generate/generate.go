@@ -0,0 +1,278 @@
+// Package generate derives compatibility data from local copies of upstream
+// artefacts, so that the etcd, CoreDNS and pause versions in the data files
+// do not have to be maintained by hand.
+//
+// Sources, from least to most authoritative:
+//   - Kubernetes CHANGELOG-1.xx.md files ("Update etcd to v3.5.15")
+//   - CoreDNS's kubernetes/CoreDNS-k8s_version.md table
+//   - kubeadm's cmd/kubeadm/app/constants/constants.go, the versions kubeadm
+//     actually installs
+package generate
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pmady/kube-dependency-checker/pkg/compatibility"
+	"github.com/pmady/kube-dependency-checker/pkg/version"
+)
+
+// Sources are local copies of upstream files
+type Sources struct {
+	// KubeadmConstants are constants.go files, one per Kubernetes release
+	KubeadmConstants []string
+	CoreDNSVersions  string
+	Changelogs       []string
+}
+
+// Derive reads the sources and merges the versions they give, more
+// authoritative sources taking precedence
+func Derive(src Sources) (Versions, error) {
+	versions := make(Versions)
+	read := func(path string, parse func(string, []byte) (Versions, error)) error {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		v, err := parse(path, data)
+		if err != nil {
+			return err
+		}
+		versions.merge(v)
+		return nil
+	}
+
+	for _, path := range src.Changelogs {
+		if err := read(path, ParseChangelog); err != nil {
+			return nil, err
+		}
+	}
+	if src.CoreDNSVersions != "" {
+		if err := read(src.CoreDNSVersions, ParseCoreDNSVersions); err != nil {
+			return nil, err
+		}
+	}
+
+	// Later kubeadm releases backport etcd bumps to older minors, so the
+	// constants files are applied oldest first and the newest wins
+	constants := make([]Versions, 0, len(src.KubeadmConstants))
+	currents := make([]*version.Version, 0, len(src.KubeadmConstants))
+	for _, path := range src.KubeadmConstants {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		v, err := ParseKubeadmConstants(path, data)
+		if err != nil {
+			return nil, err
+		}
+		m := currentK8sRe.FindStringSubmatch(string(data))
+		current, _ := version.Parse(m[1] + "." + m[2])
+		constants = append(constants, v)
+		currents = append(currents, current)
+	}
+	order := make([]int, len(constants))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return currents[order[a]].IsOlderThan(currents[order[b]])
+	})
+	for _, i := range order {
+		versions.merge(constants[i])
+	}
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no sources given")
+	}
+	return versions, nil
+}
+
+// Change is a field updated by Apply
+type Change struct {
+	K8sVersion string `json:"k8sVersion" yaml:"k8sVersion"`
+	Component  string `json:"component" yaml:"component"`
+	Field      string `json:"field" yaml:"field"`
+	Old        string `json:"old,omitempty" yaml:"old,omitempty"`
+	New        string `json:"new" yaml:"new"`
+	Source     string `json:"source" yaml:"source"`
+}
+
+func (c Change) String() string {
+	old := c.Old
+	if old == "" {
+		old = "(none)"
+	}
+	return fmt.Sprintf("%s %s %s: %s -> %s (%s)", c.K8sVersion, c.Component, c.Field, old, c.New, c.Source)
+}
+
+// componentDefaults describe components added to a release that lacks them
+var componentDefaults = map[string]compatibility.ComponentInfo{
+	Etcd:    {Name: "etcd"},
+	CoreDNS: {Name: "CoreDNS", Notes: "Installed by kubeadm"},
+	Pause:   {Name: "pause", Notes: "Sandbox image used by kubeadm"},
+}
+
+// Apply updates the bundle with the derived versions and returns the
+// changes made. Kubernetes releases missing from the bundle are created by
+// copying the newest earlier release. Generated is set to now if anything
+// changed.
+func Apply(b *compatibility.Bundle, versions Versions, now time.Time) ([]Change, error) {
+	minors := make([]*version.Version, 0, len(versions))
+	for minor := range versions {
+		v, err := version.Parse(minor)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Kubernetes version %q", minor)
+		}
+		minors = append(minors, v)
+	}
+	sort.Slice(minors, func(i, j int) bool { return minors[i].IsOlderThan(minors[j]) })
+
+	changes := make([]Change, 0)
+	for _, v := range minors {
+		minor := v.ShortString()
+		m, ok := b.Matrices[minor]
+		if !ok {
+			m = newRelease(b, v)
+			changes = append(changes, Change{K8sVersion: minor, Component: "*", Field: "release", New: minor, Source: "copied from " + previousRelease(b, v)})
+		}
+
+		components := make(map[string]compatibility.ComponentInfo, len(m.Components))
+		for k, c := range m.Components {
+			components[k] = c
+		}
+		names := make([]string, 0, len(versions[minor]))
+		for name := range versions[minor] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			d := versions[minor][name]
+			info, ok := components[name]
+			if !ok {
+				info = componentDefaults[name]
+			}
+			updated, fieldChanges := update(info, name, d.Version)
+			for _, c := range fieldChanges {
+				c.K8sVersion = minor
+				c.Component = name
+				c.Source = d.Source
+				changes = append(changes, c)
+			}
+			components[name] = updated
+		}
+		m.Components = components
+		b.Matrices[minor] = m
+	}
+
+	if len(changes) > 0 {
+		b.Generated = now.UTC().Truncate(time.Second)
+	}
+	return changes, nil
+}
+
+// update sets a component's version fields from a derived version, widening
+// MinVersion/MaxVersion when the version falls outside them. etcd's
+// MinVersion is raised to the new minor, which is the one required. Notes
+// naming the old version are rewritten to name the new one.
+func update(info compatibility.ComponentInfo, name, derived string) (compatibility.ComponentInfo, []Change) {
+	changes := make([]Change, 0)
+	set := func(field string, target *string, value string) {
+		if *target != value {
+			changes = append(changes, Change{Field: field, Old: *target, New: value})
+			*target = value
+		}
+	}
+
+	v, err := version.Parse(derived)
+	if err != nil {
+		return info, changes
+	}
+	old := info.Version
+	if name == Etcd {
+		// etcd is required at the minor level; kubeadm pins the patch
+		set("version", &info.Version, fmt.Sprintf("%d.%d.x", v.Major, v.Minor))
+	} else {
+		set("version", &info.Version, derived)
+	}
+	set("recommended", &info.Recommended, derived)
+	if old != "" && old != info.Version && strings.Contains(info.Notes, old) {
+		set("notes", &info.Notes, strings.ReplaceAll(info.Notes, old, info.Version))
+	}
+
+	if info.MinVersion != "" || info.MaxVersion != "" || name != Pause {
+		floor, _ := version.Parse(fmt.Sprintf("%d.%d.0", v.Major, v.Minor))
+		min, err := version.Parse(info.MinVersion)
+		if err != nil || v.IsOlderThan(min) || (name == Etcd && min.IsOlderThan(floor)) {
+			set("minVersion", &info.MinVersion, floor.String())
+		}
+		if max, err := version.Parse(info.MaxVersion); err != nil || v.IsNewerThan(max) {
+			set("maxVersion", &info.MaxVersion, fmt.Sprintf("%d.%d.99", v.Major, v.Minor))
+		}
+	}
+	return info, changes
+}
+
+// previousRelease returns the newest release in the bundle older than v
+func previousRelease(b *compatibility.Bundle, v *version.Version) string {
+	var best *version.Version
+	for key := range b.Matrices {
+		kv, err := version.Parse(key)
+		if err != nil || !kv.IsOlderThan(v) {
+			continue
+		}
+		if best == nil || kv.IsNewerThan(best) {
+			best = kv
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	return best.ShortString()
+}
+
+// newRelease copies the previous release's components, node requirements,
+// feature gates, flags and configuration kinds for a new Kubernetes minor
+// version. End of life, archival and patch overrides are the previous
+// release's own and are not copied.
+func newRelease(b *compatibility.Bundle, v *version.Version) compatibility.K8sVersionMatrix {
+	m := compatibility.K8sVersionMatrix{
+		K8sVersion: v.ShortString(),
+		Components: make(map[string]compatibility.ComponentInfo),
+	}
+	prev, ok := b.Matrices[previousRelease(b, v)]
+	if !ok {
+		return m
+	}
+	for name, c := range prev.Components {
+		c.Notes = strings.ReplaceAll(c.Notes, prev.K8sVersion, m.K8sVersion)
+		m.Components[name] = c
+	}
+	if prev.Node != nil {
+		node := *prev.Node
+		m.Node = &node
+	}
+	m.FeatureGates = maps.Clone(prev.FeatureGates)
+	if prev.Flags != nil {
+		m.Flags = make(map[string]map[string]compatibility.Flag, len(prev.Flags))
+		for component, flags := range prev.Flags {
+			m.Flags[component] = maps.Clone(flags)
+		}
+	}
+	if prev.Configs != nil {
+		m.Configs = make(map[string]compatibility.ConfigKind, len(prev.Configs))
+		for kind, c := range prev.Configs {
+			m.Configs[kind] = compatibility.ConfigKind{
+				APIVersions: slices.Clone(c.APIVersions),
+				Fields:      maps.Clone(c.Fields),
+			}
+		}
+	}
+	return m
+}
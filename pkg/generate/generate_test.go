@@ -0,0 +1,261 @@
+package generate
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pmady/kube-dependency-checker/pkg/compatibility"
+)
+
+const constants133 = `package constants
+
+var (
+	// CurrentKubernetesVersion specifies current Kubernetes version supported by kubeadm
+	CurrentKubernetesVersion = version.MustParseSemantic("v1.33.0-beta.0")
+)
+
+const (
+	// DefaultEtcdVersion indicates the default etcd version that kubeadm uses
+	DefaultEtcdVersion = "3.5.21-0"
+
+	// PauseVersion indicates the default pause image version for kubeadm
+	PauseVersion = "3.10"
+
+	// CoreDNSVersion is the version of CoreDNS to be deployed if it is used
+	CoreDNSVersion = "v1.12.0"
+)
+
+var (
+	// SupportedEtcdVersion lists officially supported etcd versions with corresponding Kubernetes releases
+	SupportedEtcdVersion = map[uint8]string{
+		31: "3.5.21-0",
+		32: "3.5.21-0",
+		33: "3.5.21-0",
+	}
+)
+`
+
+const constants132 = `package constants
+
+var CurrentKubernetesVersion = version.MustParseSemantic("v1.32.0")
+
+const (
+	DefaultEtcdVersion = "3.5.16-0"
+	PauseVersion = "3.10"
+	CoreDNSVersion = "v1.11.3"
+)
+
+var SupportedEtcdVersion = map[uint8]string{
+	30: "3.5.16-0",
+	31: "3.5.16-0",
+	32: "3.5.16-0",
+}
+`
+
+const coreDNSVersions = `# CoreDNS versions in Kubernetes
+
+| Kubernetes Version | CoreDNS version in Kube-up | CoreDNS version in Kubeadm | Changes in CoreDNS from previous release to Kubernetes |
+|---|---|---|---|
+| **v1.33** | v1.12.0 | v1.12.0 | [Changes](#v1.33) |
+| v1.32 | v1.11.3 | v1.11.3 | [Changes](#v1.32) |
+| v1.30 | v1.11.1 | v1.11.1 | |
+| v1.28 | v1.10.1 | v1.10.1 | |
+`
+
+const changelog128 = `# v1.28.0
+
+## Changes by Kind
+
+- Updated etcd to v3.5.9 (#118079, @ahrtr)
+- kubeadm: update pause image to 3.9
+- Update CoreDNS to v1.10.1
+- etcd client: bump go.etcd.io/etcd to v3.5.8
+`
+
+func writeSources(t *testing.T) Sources {
+	t.Helper()
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	return Sources{
+		// Deliberately newest first: the order of files does not matter
+		KubeadmConstants: []string{write("constants-1.33.go", constants133), write("constants-1.32.go", constants132)},
+		CoreDNSVersions:  write("CoreDNS-k8s_version.md", coreDNSVersions),
+		Changelogs:       []string{write("CHANGELOG-1.28.md", changelog128)},
+	}
+}
+
+func TestDerive(t *testing.T) {
+	versions, err := Derive(writeSources(t))
+	if err != nil {
+		t.Fatalf("Derive() error = %v", err)
+	}
+
+	tests := []struct {
+		minor, component, want string
+	}{
+		// kubeadm 1.33 backports etcd 3.5.21 to 1.31 and 1.32
+		{"1.31", Etcd, "3.5.21"},
+		{"1.32", Etcd, "3.5.21"},
+		{"1.30", Etcd, "3.5.16"},
+		{"1.33", CoreDNS, "1.12.0"},
+		{"1.32", CoreDNS, "1.11.3"},
+		{"1.30", CoreDNS, "1.11.1"},
+		{"1.33", Pause, "3.10"},
+		{"1.28", Etcd, "3.5.9"},
+		{"1.28", Pause, "3.9"},
+		{"1.28", CoreDNS, "1.10.1"},
+	}
+	for _, tt := range tests {
+		if got := versions[tt.minor][tt.component].Version; got != tt.want {
+			t.Errorf("%s %s = %q, want %q", tt.minor, tt.component, got, tt.want)
+		}
+	}
+	if src := versions["1.28"][CoreDNS].Source; !strings.HasSuffix(src, "CoreDNS-k8s_version.md") {
+		t.Errorf("1.28 CoreDNS source = %s, want the CoreDNS table over the changelog", src)
+	}
+}
+
+func TestApply(t *testing.T) {
+	b := &compatibility.Bundle{
+		Matrices: map[string]compatibility.K8sVersionMatrix{
+			"1.32": {
+				K8sVersion: "1.32",
+				Components: map[string]compatibility.ComponentInfo{
+					"etcd":    {Name: "etcd", Version: "3.5.x", MinVersion: "3.5.0", MaxVersion: "3.5.99", Recommended: "3.5.15", Notes: "etcd 3.5.x is required for Kubernetes 1.32"},
+					"coredns": {Name: "CoreDNS", Version: "1.11.3", MinVersion: "1.11.0", MaxVersion: "1.11.99", Recommended: "1.11.3"},
+				},
+			},
+		},
+	}
+	versions := Versions{}
+	versions.set("1.32", Etcd, "3.5.21", "constants.go")
+	versions.set("1.32", CoreDNS, "1.11.3", "constants.go")
+	versions.set("1.33", CoreDNS, "1.12.0", "constants.go")
+	versions.set("1.33", Etcd, "3.6.4", "constants.go")
+	versions.set("1.33", Pause, "3.10", "constants.go")
+
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	changes, err := Apply(b, versions, now)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	got := make([]string, len(changes))
+	for i, c := range changes {
+		got[i] = c.String()
+	}
+	want := []string{
+		"1.32 etcd recommended: 3.5.15 -> 3.5.21 (constants.go)",
+		"1.33 * release: (none) -> 1.33 (copied from 1.32)",
+		"1.33 coredns version: 1.11.3 -> 1.12.0 (constants.go)",
+		"1.33 coredns recommended: 1.11.3 -> 1.12.0 (constants.go)",
+		"1.33 coredns maxVersion: 1.11.99 -> 1.12.99 (constants.go)",
+		"1.33 etcd version: 3.5.x -> 3.6.x (constants.go)",
+		"1.33 etcd recommended: 3.5.21 -> 3.6.4 (constants.go)",
+		"1.33 etcd notes: etcd 3.5.x is required for Kubernetes 1.33 -> etcd 3.6.x is required for Kubernetes 1.33 (constants.go)",
+		"1.33 etcd minVersion: 3.5.0 -> 3.6.0 (constants.go)",
+		"1.33 etcd maxVersion: 3.5.99 -> 3.6.99 (constants.go)",
+		"1.33 pause version: (none) -> 3.10 (constants.go)",
+		"1.33 pause recommended: (none) -> 3.10 (constants.go)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Apply() changes =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if !b.Generated.Equal(now) {
+		t.Errorf("Generated = %s, want %s", b.Generated, now)
+	}
+	if notes := b.Matrices["1.33"].Components["etcd"].Notes; notes != "etcd 3.6.x is required for Kubernetes 1.33" {
+		t.Errorf("copied and updated etcd notes = %q", notes)
+	}
+	if issues := compatibility.Lint(b.Matrices); len(issues) != 0 {
+		t.Errorf("generated data has lint issues: %v", issues)
+	}
+
+	// Applying the same versions again changes nothing
+	if changes, _ := Apply(b, versions, now.Add(time.Hour)); len(changes) != 0 {
+		t.Errorf("second Apply() changes = %v, want none", changes)
+	}
+}
+
+func TestApplyNewReleaseKeepsData(t *testing.T) {
+	embedded := compatibility.Embedded()
+	b := &compatibility.Bundle{Matrices: maps.Clone(embedded.Matrices)}
+	prev := b.Matrices["1.33"]
+
+	versions := Versions{}
+	versions.set("1.34", Pause, "3.10.1", "constants.go")
+	if _, err := Apply(b, versions, time.Now()); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	m := b.Matrices["1.34"]
+	if len(prev.FeatureGates) == 0 || len(prev.Flags) == 0 || len(prev.Configs) == 0 {
+		t.Fatal("1.33 has no feature gate, flag or configuration data to copy")
+	}
+	if !reflect.DeepEqual(m.FeatureGates, prev.FeatureGates) {
+		t.Error("1.34 feature gates differ from 1.33")
+	}
+	if !reflect.DeepEqual(m.Flags, prev.Flags) {
+		t.Error("1.34 flags differ from 1.33")
+	}
+	if !reflect.DeepEqual(m.Configs, prev.Configs) {
+		t.Error("1.34 configuration kinds differ from 1.33")
+	}
+	if m.Node == nil || *m.Node != *prev.Node {
+		t.Errorf("1.34 node requirements = %+v, want those of 1.33", m.Node)
+	}
+	if issues := compatibility.Lint(b.Matrices); len(issues) != 0 {
+		t.Errorf("generated 1.34 has lint issues: %v", issues)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	if _, err := ParseKubeadmConstants("constants.go", []byte("package constants\n")); err == nil {
+		t.Error("ParseKubeadmConstants() expected error without CurrentKubernetesVersion")
+	}
+	if _, err := ParseCoreDNSVersions("versions.md", []byte("# nothing here\n")); err == nil {
+		t.Error("ParseCoreDNSVersions() expected error without a table")
+	}
+	if _, err := ParseChangelog("NOTES.md", []byte("no release heading\n")); err == nil {
+		t.Error("ParseChangelog() expected error without a release")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	oldText := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\n"
+	newText := "a\nb\nC\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n"
+	want := `--- old
++++ new
+@@ -1,6 +1,6 @@
+ a
+ b
+-c
++C
+ d
+ e
+ f
+@@ -10,3 +10,4 @@
+ j
+ k
+ l
++m
+`
+	if got := Diff("old", "new", oldText, newText); got != want {
+		t.Errorf("Diff() =\n%s\nwant\n%s", got, want)
+	}
+	if got := Diff("old", "new", oldText, oldText); got != "" {
+		t.Errorf("Diff() of equal texts = %q", got)
+	}
+}
@@ -0,0 +1,192 @@
+package generate
+
+import (
+	"bufio"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pmady/kube-dependency-checker/pkg/version"
+)
+
+// Component names derived by the generator
+const (
+	Etcd    = "etcd"
+	CoreDNS = "coredns"
+	Pause   = "pause"
+)
+
+// Versions maps a Kubernetes minor version, e.g. "1.31", to the versions
+// derived for it, keyed by component
+type Versions map[string]map[string]Derived
+
+// Derived is a component version and the file it was read from
+type Derived struct {
+	Version string `json:"version" yaml:"version"`
+	Source  string `json:"source" yaml:"source"`
+}
+
+func (v Versions) set(minor, component, value, source string) {
+	if v[minor] == nil {
+		v[minor] = make(map[string]Derived)
+	}
+	v[minor][component] = Derived{Version: value, Source: source}
+}
+
+// merge copies other into v; values in other win
+func (v Versions) merge(other Versions) {
+	for minor, components := range other {
+		for component, d := range components {
+			v.set(minor, component, d.Version, d.Source)
+		}
+	}
+}
+
+var (
+	currentK8sRe   = regexp.MustCompile(`CurrentKubernetesVersion\s*=\s*version\.MustParseSemantic\("v?(\d+)\.(\d+)`)
+	defaultEtcdRe  = regexp.MustCompile(`DefaultEtcdVersion\s*=\s*"v?([0-9.]+)`)
+	coreDNSConstRe = regexp.MustCompile(`CoreDNSVersion\s*=\s*"v?([0-9.]+)"`)
+	pauseConstRe   = regexp.MustCompile(`PauseVersion\s*=\s*"v?([0-9.]+)"`)
+	etcdMapStartRe = regexp.MustCompile(`SupportedEtcdVersion\s*=\s*map\[uint8\]string\{`)
+	etcdMapEntryRe = regexp.MustCompile(`^\s*(\d+)\s*:\s*"v?([0-9.]+)`)
+)
+
+// ParseKubeadmConstants reads kubeadm's cmd/kubeadm/app/constants/constants.go.
+// The pause and CoreDNS versions belong to the file's CurrentKubernetesVersion;
+// SupportedEtcdVersion gives the etcd version of every minor it lists.
+func ParseKubeadmConstants(name string, data []byte) (Versions, error) {
+	src := string(data)
+	m := currentK8sRe.FindStringSubmatch(src)
+	if m == nil {
+		return nil, fmt.Errorf("%s: CurrentKubernetesVersion not found", name)
+	}
+	major := m[1]
+	current := m[1] + "." + m[2]
+
+	versions := make(Versions)
+	if m := defaultEtcdRe.FindStringSubmatch(src); m != nil {
+		versions.set(current, Etcd, m[1], name)
+	}
+	if m := coreDNSConstRe.FindStringSubmatch(src); m != nil {
+		versions.set(current, CoreDNS, m[1], name)
+	}
+	if m := pauseConstRe.FindStringSubmatch(src); m != nil {
+		versions.set(current, Pause, m[1], name)
+	}
+
+	if loc := etcdMapStartRe.FindStringIndex(src); loc != nil {
+		scanner := bufio.NewScanner(strings.NewReader(src[loc[1]:]))
+		for scanner.Scan() {
+			line := scanner.Text()
+			if strings.Contains(line, "}") {
+				break
+			}
+			if m := etcdMapEntryRe.FindStringSubmatch(line); m != nil {
+				versions.set(major+"."+m[1], Etcd, m[2], name)
+			}
+		}
+	}
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("%s: no etcd, CoreDNS or pause versions found", name)
+	}
+	return versions, nil
+}
+
+var (
+	k8sCellRe     = regexp.MustCompile(`v?(\d+\.\d+)`)
+	versionCellRe = regexp.MustCompile(`v?(\d+\.\d+\.\d+)`)
+)
+
+// ParseCoreDNSVersions reads the Markdown table in CoreDNS's
+// kubernetes/CoreDNS-k8s_version.md, using the kubeadm column when there is
+// one
+func ParseCoreDNSVersions(name string, data []byte) (Versions, error) {
+	versions := make(Versions)
+	column := -1
+	for _, line := range strings.Split(string(data), "\n") {
+		cells := tableCells(line)
+		if len(cells) < 2 {
+			continue
+		}
+		if column < 0 {
+			// The header row picks the column; default to the first version
+			column = 1
+			for i, c := range cells {
+				if strings.Contains(strings.ToLower(c), "kubeadm") {
+					column = i
+				}
+			}
+			continue
+		}
+		k8s := k8sCellRe.FindStringSubmatch(strings.Trim(cells[0], "* "))
+		if k8s == nil || column >= len(cells) {
+			continue
+		}
+		if v := versionCellRe.FindStringSubmatch(cells[column]); v != nil {
+			versions.set(k8s[1], CoreDNS, v[1], name)
+		}
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("%s: no Kubernetes/CoreDNS version table found", name)
+	}
+	return versions, nil
+}
+
+func tableCells(line string) []string {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "|") {
+		return nil
+	}
+	cells := strings.Split(strings.Trim(line, "|"), "|")
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+	}
+	// Skip the separator row
+	if strings.Trim(strings.Join(cells, ""), "-: ") == "" {
+		return nil
+	}
+	return cells
+}
+
+var (
+	changelogNameRe = regexp.MustCompile(`CHANGELOG-(\d+\.\d+)\.md$`)
+	changelogHeadRe = regexp.MustCompile(`(?m)^#+\s*v(\d+\.\d+)\.\d+`)
+	changelogRes    = map[string]*regexp.Regexp{
+		Etcd:    regexp.MustCompile(`(?i)\betcd\b[^\n]*?\b(?:to|:)\s*v?(3\.\d+\.\d+)`),
+		CoreDNS: regexp.MustCompile(`(?i)\bcoredns\b[^\n]*?\b(?:to|:)\s*v?(1\.\d+\.\d+)`),
+		Pause:   regexp.MustCompile(`(?i)\bpause\b[^\n]*?\b(?:to|:)\s*v?(3\.\d+(?:\.\d+)?)`),
+	}
+)
+
+// ParseChangelog reads a Kubernetes CHANGELOG-1.xx.md and returns the
+// highest etcd, CoreDNS and pause versions its entries update to
+func ParseChangelog(name string, data []byte) (Versions, error) {
+	minor := ""
+	if m := changelogNameRe.FindStringSubmatch(filepath.Base(name)); m != nil {
+		minor = m[1]
+	} else if m := changelogHeadRe.FindSubmatch(data); m != nil {
+		minor = string(m[1])
+	} else {
+		return nil, fmt.Errorf("%s: cannot tell which Kubernetes release the changelog is for", name)
+	}
+
+	versions := make(Versions)
+	for component, re := range changelogRes {
+		var highest *version.Version
+		for _, m := range re.FindAllStringSubmatch(string(data), -1) {
+			v, err := version.Parse(m[1])
+			if err != nil {
+				continue
+			}
+			if highest == nil || v.IsNewerThan(highest) {
+				highest = v
+			}
+		}
+		if highest != nil {
+			versions.set(minor, component, highest.Raw, name)
+		}
+	}
+	return versions, nil
+}
@@ -0,0 +1,185 @@
+// Package images parses container image references and maps them to the
+// Kubernetes components they run, so that component versions can be read
+// from pod specs, e.g. registry.k8s.io/etcd:3.5.12-0 is etcd 3.5.12.
+package images
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pmady/kube-dependency-checker/pkg/version"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultRegistry is the registry of image names without one
+const DefaultRegistry = "docker.io"
+
+// Reference is a parsed image reference,
+// [registry/]repository[:tag][@digest]
+type Reference struct {
+	Registry   string `json:"registry" yaml:"registry"`
+	Repository string `json:"repository" yaml:"repository"`
+	Tag        string `json:"tag,omitempty" yaml:"tag,omitempty"`
+	Digest     string `json:"digest,omitempty" yaml:"digest,omitempty"`
+}
+
+// Parse parses an image reference. Names without a registry are Docker Hub
+// images, so nginx:1.25 is docker.io/library/nginx:1.25.
+func Parse(image string) (Reference, error) {
+	s := strings.TrimSpace(image)
+	var ref Reference
+	if name, digest, ok := strings.Cut(s, "@"); ok {
+		algorithm, hex, ok := strings.Cut(digest, ":")
+		if !ok || algorithm == "" || hex == "" {
+			return Reference{}, fmt.Errorf("invalid image %q: malformed digest", image)
+		}
+		s, ref.Digest = name, digest
+	}
+	if i := strings.LastIndex(s, ":"); i > strings.LastIndex(s, "/") {
+		s, ref.Tag = s[:i], s[i+1:]
+		if ref.Tag == "" {
+			return Reference{}, fmt.Errorf("invalid image %q: empty tag", image)
+		}
+	}
+	ref.Registry, ref.Repository = splitName(s)
+	if ref.Repository == "" || strings.HasSuffix(ref.Repository, "/") || strings.ContainsAny(ref.Repository, " :@") {
+		return Reference{}, fmt.Errorf("invalid image %q", image)
+	}
+	return ref, nil
+}
+
+// splitName splits a name into registry and repository. The first path
+// element is a registry when it looks like a host name.
+func splitName(name string) (string, string) {
+	first, rest, ok := strings.Cut(name, "/")
+	if ok && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		return first, rest
+	}
+	if !strings.Contains(name, "/") {
+		return DefaultRegistry, "library/" + name
+	}
+	return DefaultRegistry, name
+}
+
+// Name returns the registry and repository, e.g. registry.k8s.io/etcd
+func (r Reference) Name() string {
+	return r.Registry + "/" + r.Repository
+}
+
+func (r Reference) String() string {
+	s := r.Name()
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// Version returns the version in the tag, e.g. 3.5.12 for 3.5.12-0 and
+// 1.11.1 for v1.11.1-eksbuild.4. Digest-only references have none.
+func (r Reference) Version() (string, bool) {
+	return version.Clean(r.Tag)
+}
+
+// Mapper maps image repositories to component names
+type Mapper struct {
+	// Mirrors rewrites repository prefixes before lookup, e.g.
+	// harbor.example.com/k8s to registry.k8s.io
+	Mirrors map[string]string `json:"mirrors,omitempty" yaml:"mirrors,omitempty"`
+	// Components maps a full repository name, e.g. registry.k8s.io/etcd,
+	// or the last element of one, e.g. etcd, to a component
+	Components map[string]string `json:"components,omitempty" yaml:"components,omitempty"`
+}
+
+// Default returns the mapper for the images kubeadm and the managed
+// Kubernetes services run. Repositories are matched on their last element,
+// so mirrors and provider registries such as ECR's eks/coredns match too.
+func Default() *Mapper {
+	return &Mapper{
+		Mirrors: make(map[string]string),
+		Components: map[string]string{
+			"etcd":                    "etcd",
+			"coredns":                 "coredns",
+			"kube-proxy":              "kube-proxy",
+			"kube-controller-manager": "kube-controller-manager",
+			"kube-scheduler":          "kube-scheduler",
+		},
+	}
+}
+
+// LoadMapper reads a mapping file and adds it to the default mapping:
+//
+//	mirrors:
+//	  harbor.example.com/k8s: registry.k8s.io
+//	components:
+//	  registry.example.com/platform/etcd-fips: etcd
+func LoadMapper(path string) (*Mapper, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file Mapper
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: invalid image mapping: %w", path, err)
+	}
+
+	m := Default()
+	for from, to := range file.Mirrors {
+		if strings.Trim(from, "/") == "" || strings.Trim(to, "/") == "" {
+			return nil, fmt.Errorf("%s: mirror %q -> %q must name two repositories", path, from, to)
+		}
+		m.Mirrors[strings.Trim(from, "/")] = strings.Trim(to, "/")
+	}
+	for repo, component := range file.Components {
+		if repo == "" || component == "" {
+			return nil, fmt.Errorf("%s: component mapping %q -> %q is incomplete", path, repo, component)
+		}
+		m.Components[repo] = component
+	}
+	return m, nil
+}
+
+// Canonical applies the longest matching mirror rewrite to a reference
+func (m *Mapper) Canonical(ref Reference) Reference {
+	name := ref.Name()
+	prefixes := make([]string, 0, len(m.Mirrors))
+	for prefix := range m.Mirrors {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+	for _, prefix := range prefixes {
+		if name == prefix || strings.HasPrefix(name, prefix+"/") {
+			ref.Registry, ref.Repository = splitName(m.Mirrors[prefix] + strings.TrimPrefix(name, prefix))
+			break
+		}
+	}
+	return ref
+}
+
+// Component returns the component run by an image and its version. Images
+// that are not mapped, or whose tag holds no version, are not components.
+func (m *Mapper) Component(image string) (string, string, bool) {
+	ref, err := Parse(image)
+	if err != nil {
+		return "", "", false
+	}
+	ref = m.Canonical(ref)
+
+	component, ok := m.Components[ref.Name()]
+	if !ok {
+		component, ok = m.Components[path.Base(ref.Repository)]
+	}
+	if !ok {
+		return "", "", false
+	}
+	v, ok := ref.Version()
+	if !ok {
+		return "", "", false
+	}
+	return component, v, true
+}
@@ -0,0 +1,145 @@
+package images
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		image string
+		want  Reference
+	}{
+		{"registry.k8s.io/etcd:3.5.12-0", Reference{Registry: "registry.k8s.io", Repository: "etcd", Tag: "3.5.12-0"}},
+		{"registry.k8s.io/coredns/coredns:v1.11.1", Reference{Registry: "registry.k8s.io", Repository: "coredns/coredns", Tag: "v1.11.1"}},
+		{"registry.local:5000/kube-scheduler", Reference{Registry: "registry.local:5000", Repository: "kube-scheduler"}},
+		{"localhost/etcd:3.5.9", Reference{Registry: "localhost", Repository: "etcd", Tag: "3.5.9"}},
+		{"nginx:1.25", Reference{Registry: "docker.io", Repository: "library/nginx", Tag: "1.25"}},
+		{"bitnami/etcd", Reference{Registry: "docker.io", Repository: "bitnami/etcd"}},
+		{"registry.k8s.io/kube-proxy@sha256:0123", Reference{Registry: "registry.k8s.io", Repository: "kube-proxy", Digest: "sha256:0123"}},
+		{"registry.k8s.io/kube-proxy:v1.30.4@sha256:0123", Reference{Registry: "registry.k8s.io", Repository: "kube-proxy", Tag: "v1.30.4", Digest: "sha256:0123"}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.image)
+		if err != nil {
+			t.Errorf("Parse(%s) error = %v", tt.image, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%s) = %+v, want %+v", tt.image, got, tt.want)
+		}
+	}
+
+	for _, image := range []string{"", "etcd:", "registry.k8s.io/etcd@sha256", "registry.k8s.io/", "registry.k8s.io/etcd@"} {
+		if _, err := Parse(image); err == nil {
+			t.Errorf("Parse(%q) expected error", image)
+		}
+	}
+}
+
+func TestMapperComponent(t *testing.T) {
+	mapper := Default()
+	mapper.Mirrors["harbor.example.com/k8s-mirror"] = "registry.k8s.io"
+	mapper.Components["registry.k8s.io/etcd-fips"] = "etcd"
+
+	tests := []struct {
+		image         string
+		wantComponent string
+		wantVersion   string
+		wantOk        bool
+	}{
+		{"registry.k8s.io/etcd:3.5.12-0", "etcd", "3.5.12", true},
+		{"registry.k8s.io/coredns/coredns:v1.11.1", "coredns", "1.11.1", true},
+		{"602401143452.dkr.ecr.us-west-2.amazonaws.com/eks/coredns:v1.11.1-eksbuild.4", "coredns", "1.11.1", true},
+		{"registry.local:5000/kube-scheduler:v1.30.2", "kube-scheduler", "1.30.2", true},
+		{"registry.k8s.io/kube-proxy:v1.30.4@sha256:0123", "kube-proxy", "1.30.4", true},
+		{"harbor.example.com/k8s-mirror/etcd-fips:3.5.15-0", "etcd", "3.5.15", true},
+		{"registry.k8s.io/kube-proxy@sha256:0123", "", "", false},
+		{"registry.local:5000/kube-scheduler", "", "", false},
+		{"harbor.example.com/k8s-mirror-other/etcd-fips:3.5.15-0", "", "", false},
+		{"nginx:1.25", "", "", false},
+	}
+	for _, tt := range tests {
+		component, v, ok := mapper.Component(tt.image)
+		if ok != tt.wantOk || component != tt.wantComponent || v != tt.wantVersion {
+			t.Errorf("Component(%s) = (%s, %s, %v), want (%s, %s, %v)",
+				tt.image, component, v, ok, tt.wantComponent, tt.wantVersion, tt.wantOk)
+		}
+	}
+}
+
+func TestLoadMapper(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "images.yaml")
+	data := `mirrors:
+  harbor.example.com/k8s/: registry.k8s.io
+components:
+  registry.example.com/platform/dns: coredns
+`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mapper, err := LoadMapper(path)
+	if err != nil {
+		t.Fatalf("LoadMapper() error = %v", err)
+	}
+	for image, want := range map[string]string{
+		"harbor.example.com/k8s/coredns/coredns:v1.11.3": "coredns",
+		"registry.example.com/platform/dns:1.11.3":       "coredns",
+		"harbor.example.com/k8s/kube-proxy:v1.30.1":      "kube-proxy",
+	} {
+		if got, _, _ := mapper.Component(image); got != want {
+			t.Errorf("Component(%s) = %q, want %q", image, got, want)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte("components:\n  etcd: \"\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadMapper(path); err == nil {
+		t.Error("LoadMapper() expected error for an empty component")
+	}
+}
+
+func TestPodComponents(t *testing.T) {
+	list := `apiVersion: v1
+kind: List
+items:
+  - kind: Pod
+    spec:
+      initContainers:
+        - {name: init, image: "busybox:1.36"}
+      containers:
+        - {name: etcd, image: "registry.k8s.io/etcd:3.5.12-0"}
+  - kind: Pod
+    spec:
+      containers:
+        - {name: coredns, image: "registry.k8s.io/coredns/coredns:v1.11.3"}
+  - kind: Pod
+    spec:
+      containers:
+        - {name: coredns, image: "registry.k8s.io/coredns/coredns:v1.11.1"}
+`
+	pods, err := ParsePods([]byte(list))
+	if err != nil {
+		t.Fatalf("ParsePods() error = %v", err)
+	}
+	got := Default().PodComponents(pods)
+	want := map[string]string{"etcd": "3.5.12", "coredns": "1.11.1"}
+	if len(got) != len(want) || got["etcd"] != want["etcd"] || got["coredns"] != want["coredns"] {
+		t.Errorf("PodComponents() = %v, want %v", got, want)
+	}
+
+	pod := `{"kind": "Pod", "spec": {"containers": [{"image": "registry.k8s.io/kube-scheduler:v1.30.2"}]}}`
+	pods, err = ParsePods([]byte(pod))
+	if err != nil {
+		t.Fatalf("ParsePods() error = %v", err)
+	}
+	if got := Default().PodComponents(pods); got["kube-scheduler"] != "1.30.2" {
+		t.Errorf("PodComponents() of a single pod = %v", got)
+	}
+
+	if _, err := ParsePods([]byte("kind: Deployment\n")); err == nil {
+		t.Error("ParsePods() expected error for a Deployment")
+	}
+}
@@ -0,0 +1,96 @@
+package images
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pmady/kube-dependency-checker/pkg/version"
+	"gopkg.in/yaml.v3"
+)
+
+// PodList is the part of a Kubernetes pod list that holds images, as
+// returned by the API or saved with "kubectl get pods -o yaml"
+type PodList struct {
+	Kind  string `json:"kind" yaml:"kind"`
+	Items []Pod  `json:"items" yaml:"items"`
+}
+
+// Pod is the part of a pod that holds images
+type Pod struct {
+	Kind string  `json:"kind" yaml:"kind"`
+	Spec PodSpec `json:"spec" yaml:"spec"`
+}
+
+// PodSpec lists the containers of a pod
+type PodSpec struct {
+	InitContainers []Container `json:"initContainers" yaml:"initContainers"`
+	Containers     []Container `json:"containers" yaml:"containers"`
+}
+
+// Container is a container of a pod
+type Container struct {
+	Name    string   `json:"name" yaml:"name"`
+	Image   string   `json:"image" yaml:"image"`
+	Command []string `json:"command,omitempty" yaml:"command,omitempty"`
+	Args    []string `json:"args,omitempty" yaml:"args,omitempty"`
+}
+
+// ParsePods parses a saved pod list, or a single pod, in JSON or YAML
+func ParsePods(data []byte) (*PodList, error) {
+	var list PodList
+	if err := yaml.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("invalid pod list: %w", err)
+	}
+	switch list.Kind {
+	case "List", "PodList":
+		return &list, nil
+	case "Pod":
+		var pod Pod
+		if err := yaml.Unmarshal(data, &pod); err != nil {
+			return nil, fmt.Errorf("invalid pod: %w", err)
+		}
+		return &PodList{Kind: "PodList", Items: []Pod{pod}}, nil
+	default:
+		return nil, fmt.Errorf("expected a Pod, PodList or List, got kind %q", list.Kind)
+	}
+}
+
+// LoadPodComponents reads a saved pod list and returns the version of every
+// component its pods run
+func LoadPodComponents(path string, m *Mapper) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := ParsePods(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m.PodComponents(pods), nil
+}
+
+// PodComponents returns the version of every component run by the pods.
+// When a component runs at several versions, e.g. during a rolling
+// upgrade, the oldest is returned.
+func (m *Mapper) PodComponents(pods *PodList) map[string]string {
+	components := make(map[string]string)
+	for _, pod := range pods.Items {
+		containers := append(append([]Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+		for _, c := range containers {
+			component, v, ok := m.Component(c.Image)
+			if !ok {
+				continue
+			}
+			if current, seen := components[component]; !seen || older(v, current) {
+				components[component] = v
+			}
+		}
+	}
+	return components
+}
+
+func older(a, b string) bool {
+	va, errA := version.Parse(a)
+	vb, errB := version.Parse(b)
+	return errA == nil && errB == nil && va.IsOlderThan(vb)
+}
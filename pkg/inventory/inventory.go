@@ -0,0 +1,138 @@
+// Package inventory loads observed cluster versions from inventory files.
+// An inventory describes, per cluster, the running Kubernetes version and
+// the versions of its components and node pools.
+package inventory
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pmady/kube-dependency-checker/pkg/images"
+	"gopkg.in/yaml.v3"
+)
+
+// Inventory is a set of clusters and their observed versions
+type Inventory struct {
+	Clusters []Cluster `json:"clusters" yaml:"clusters"`
+}
+
+// Cluster holds the observed versions of a single cluster
+type Cluster struct {
+	Name       string            `json:"name" yaml:"name"`
+	K8sVersion string            `json:"k8sVersion" yaml:"k8sVersion"`
+	Labels     map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Components map[string]string `json:"components,omitempty" yaml:"components,omitempty"`
+	NodePools  []NodePool        `json:"nodePools,omitempty" yaml:"nodePools,omitempty"`
+	// Pods is a saved kube-system pod list, relative to the inventory file,
+	// whose images give the versions of components not listed above
+	Pods string `json:"pods,omitempty" yaml:"pods,omitempty"`
+
+	// Source is the inventory file the cluster was loaded from, if any
+	Source string `json:"-" yaml:"-"`
+}
+
+// NodePool holds the kubelet version of a group of nodes
+type NodePool struct {
+	Name           string `json:"name" yaml:"name"`
+	KubeletVersion string `json:"kubeletVersion" yaml:"kubeletVersion"`
+}
+
+// Load reads an inventory from a file, or from every .yaml, .yml and .json
+// file in a directory
+func Load(path string) (*Inventory, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return loadFile(path)
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(e.Name())) {
+		case ".yaml", ".yml", ".json":
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+
+	inv := &Inventory{}
+	for _, name := range names {
+		fileInv, err := loadFile(filepath.Join(path, name))
+		if err != nil {
+			return nil, err
+		}
+		inv.Clusters = append(inv.Clusters, fileInv.Clusters...)
+	}
+	return inv, nil
+}
+
+func loadFile(path string) (*Inventory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	inv, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for i := range inv.Clusters {
+		inv.Clusters[i].Source = path
+	}
+	return inv, nil
+}
+
+// Parse parses an inventory document
+func Parse(data []byte) (*Inventory, error) {
+	var inv Inventory
+	if err := yaml.Unmarshal(data, &inv); err != nil {
+		return nil, fmt.Errorf("invalid inventory: %w", err)
+	}
+	for i, c := range inv.Clusters {
+		if c.Name == "" {
+			return nil, fmt.Errorf("cluster %d has no name", i)
+		}
+		if c.K8sVersion == "" {
+			return nil, fmt.Errorf("cluster %s has no k8sVersion", c.Name)
+		}
+	}
+	return &inv, nil
+}
+
+// ReadPods fills in the components of the cluster from its pod list
+// snapshot, if it has one. Components listed in the inventory win.
+func (c *Cluster) ReadPods(mapper *images.Mapper) error {
+	if c.Pods == "" {
+		return nil
+	}
+	path := c.Pods
+	if !filepath.IsAbs(path) && c.Source != "" {
+		path = filepath.Join(filepath.Dir(c.Source), path)
+	}
+	found, err := images.LoadPodComponents(path, mapper)
+	if err != nil {
+		return fmt.Errorf("cluster %s: %w", c.Name, err)
+	}
+
+	components := make(map[string]string)
+	for name, v := range found {
+		components[name] = v
+	}
+	for name, v := range c.Components {
+		components[name] = v
+	}
+	c.Components = components
+	return nil
+}
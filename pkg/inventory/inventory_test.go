@@ -0,0 +1,68 @@
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testInventory = `
+clusters:
+  - name: prod
+    k8sVersion: 1.30.4
+    components:
+      etcd: 3.5.12
+    nodePools:
+      - name: default
+        kubeletVersion: 1.29.6
+`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"valid inventory", testInventory, false},
+		{"missing name", "clusters:\n  - k8sVersion: 1.30\n", true},
+		{"missing version", "clusters:\n  - name: prod\n", true},
+		{"invalid yaml", "clusters: [", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadDirectory(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.yaml":    testInventory,
+		"b.yml":     "clusters:\n  - name: staging\n    k8sVersion: 1.31\n",
+		"notes.txt": "ignored",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	inv, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(inv.Clusters) != 2 {
+		t.Fatalf("Load() returned %d clusters, want 2", len(inv.Clusters))
+	}
+	if inv.Clusters[0].Name != "prod" || inv.Clusters[1].Name != "staging" {
+		t.Errorf("Load() clusters = %s, %s; want prod, staging", inv.Clusters[0].Name, inv.Clusters[1].Name)
+	}
+	if got := inv.Clusters[0].NodePools[0].KubeletVersion; got != "1.29.6" {
+		t.Errorf("kubeletVersion = %s, want 1.29.6", got)
+	}
+}
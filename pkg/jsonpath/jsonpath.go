@@ -0,0 +1,680 @@
+// Package jsonpath implements the kubectl flavour of JSONPath templates.
+//
+// A template mixes literal text with expressions in braces:
+//
+//	{.k8sVersion}
+//	{.components[*].name}
+//	{.components[?(@.name=="etcd")].recommended}
+//	{range .components[*]}{.name}{"\t"}{.status}{"\n"}{end}
+//
+// Expressions support fields, bracket notation (['name'] or a union such as
+// ['name','version']), wildcards, array indexes and slices, recursive
+// descent (..name) and filters with ==, !=, <, <=, >, >= or a bare
+// existence test. Like kubectl, an index outside the array is an error;
+// slice bounds are clamped to the array. Templates are evaluated against
+// JSON-like data: maps, slices, strings, numbers, booleans and nil.
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// JSONPath is a parsed template
+type JSONPath struct {
+	nodes []node
+}
+
+type nodeKind int
+
+const (
+	textNode nodeKind = iota
+	exprNode
+	rangeNode
+	endNode
+)
+
+type node struct {
+	kind  nodeKind
+	text  string
+	steps []step
+	body  []node // rangeNode only
+}
+
+type stepKind int
+
+const (
+	fieldStep stepKind = iota
+	wildcardStep
+	recursiveStep
+	indexStep
+	sliceStep
+	filterStep
+)
+
+type step struct {
+	kind  stepKind
+	name  string   // recursive step
+	names []string // field step, more than one for a union
+	index []int    // index step
+	start *int     // slice step
+	end   *int
+	cond  *condition // filter step
+}
+
+type condition struct {
+	left  []step
+	op    string // empty for an existence test
+	right interface{}
+}
+
+// Parse parses a JSONPath template
+func Parse(template string) (*JSONPath, error) {
+	flat, err := lex(template)
+	if err != nil {
+		return nil, err
+	}
+	nodes, rest, err := nest(flat)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("unexpected {end}")
+	}
+	return &JSONPath{nodes: nodes}, nil
+}
+
+// Execute evaluates the template against data and writes the result
+func (j *JSONPath) Execute(w io.Writer, data interface{}) error {
+	return execute(w, j.nodes, data, data)
+}
+
+// lex splits the template into text, expression, range and end nodes
+func lex(template string) ([]node, error) {
+	nodes := make([]node, 0)
+	for len(template) > 0 {
+		open := strings.Index(template, "{")
+		if open < 0 {
+			nodes = append(nodes, node{kind: textNode, text: template})
+			break
+		}
+		if open > 0 {
+			nodes = append(nodes, node{kind: textNode, text: template[:open]})
+		}
+
+		closeIdx := matchingBrace(template, open)
+		if closeIdx < 0 {
+			return nil, fmt.Errorf("unclosed expression in %q", template[open:])
+		}
+		inner := strings.TrimSpace(template[open+1 : closeIdx])
+		template = template[closeIdx+1:]
+
+		switch {
+		case inner == "end":
+			nodes = append(nodes, node{kind: endNode})
+		case strings.HasPrefix(inner, "range "):
+			steps, err := parseExpr(strings.TrimSpace(strings.TrimPrefix(inner, "range ")))
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, node{kind: rangeNode, steps: steps})
+		case strings.HasPrefix(inner, `"`):
+			text, err := strconv.Unquote(inner)
+			if err != nil {
+				return nil, fmt.Errorf("invalid string literal %s: %w", inner, err)
+			}
+			nodes = append(nodes, node{kind: textNode, text: text})
+		default:
+			steps, err := parseExpr(inner)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, node{kind: exprNode, steps: steps})
+		}
+	}
+	return nodes, nil
+}
+
+// matchingBrace finds the brace closing the one at open, skipping quoted text
+func matchingBrace(s string, open int) int {
+	depth := 0
+	var quote byte
+	for i := open; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// nest attaches the nodes between {range} and {end} to the range node
+func nest(flat []node) ([]node, []node, error) {
+	nodes := make([]node, 0)
+	for len(flat) > 0 {
+		n := flat[0]
+		flat = flat[1:]
+		switch n.kind {
+		case endNode:
+			return nodes, append([]node{n}, flat...), nil
+		case rangeNode:
+			body, rest, err := nest(flat)
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(rest) == 0 {
+				return nil, nil, fmt.Errorf("{range} without {end}")
+			}
+			n.body = body
+			flat = rest[1:]
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes, nil, nil
+}
+
+// parseExpr parses an expression such as .items[0].name or $..name
+func parseExpr(expr string) ([]step, error) {
+	p := &exprParser{s: expr}
+	steps, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", expr, err)
+	}
+	return steps, nil
+}
+
+type exprParser struct {
+	s   string
+	pos int
+}
+
+func (p *exprParser) parse() ([]step, error) {
+	if strings.HasPrefix(p.s, "$") || strings.HasPrefix(p.s, "@") {
+		p.pos++
+	}
+
+	steps := make([]step, 0)
+	for p.pos < len(p.s) {
+		switch {
+		case strings.HasPrefix(p.s[p.pos:], ".."):
+			p.pos += 2
+			name := p.ident()
+			if name == "" {
+				return nil, fmt.Errorf("expected field name after '..'")
+			}
+			steps = append(steps, step{kind: recursiveStep, name: name})
+		case p.s[p.pos] == '.':
+			p.pos++
+			if p.pos < len(p.s) && p.s[p.pos] == '*' {
+				p.pos++
+				steps = append(steps, step{kind: wildcardStep})
+				continue
+			}
+			name := p.ident()
+			if name == "" {
+				if p.pos == len(p.s) && len(steps) == 0 {
+					// A lone "." selects the root
+					continue
+				}
+				return nil, fmt.Errorf("expected field name at offset %d", p.pos)
+			}
+			steps = append(steps, step{kind: fieldStep, names: []string{name}})
+		case p.s[p.pos] == '[':
+			s, err := p.bracket()
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, s)
+		default:
+			return nil, fmt.Errorf("unexpected %q at offset %d", p.s[p.pos], p.pos)
+		}
+	}
+	return steps, nil
+}
+
+func (p *exprParser) ident() string {
+	start := p.pos
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		if c == '.' || c == '[' || c == ' ' || c == '=' || c == '!' || c == '<' || c == '>' || c == ')' {
+			break
+		}
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+func (p *exprParser) bracket() (step, error) {
+	end := p.closing()
+	if end < 0 {
+		return step{}, fmt.Errorf("unclosed '['")
+	}
+	inner := strings.TrimSpace(p.s[p.pos+1 : end])
+	p.pos = end + 1
+
+	switch {
+	case inner == "*":
+		return step{kind: wildcardStep}, nil
+	case strings.HasPrefix(inner, "?(") && strings.HasSuffix(inner, ")"):
+		cond, err := parseCondition(strings.TrimSpace(inner[2 : len(inner)-1]))
+		if err != nil {
+			return step{}, err
+		}
+		return step{kind: filterStep, cond: cond}, nil
+	case strings.HasPrefix(inner, "'") || strings.HasPrefix(inner, `"`):
+		s := step{kind: fieldStep}
+		for _, part := range splitUnion(inner) {
+			name, err := unquote(strings.TrimSpace(part))
+			if err != nil {
+				return step{}, fmt.Errorf("invalid field name %s", strings.TrimSpace(part))
+			}
+			s.names = append(s.names, name)
+		}
+		return s, nil
+	case strings.Contains(inner, ":"):
+		parts := strings.SplitN(inner, ":", 2)
+		s := step{kind: sliceStep}
+		for i, part := range parts {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return step{}, fmt.Errorf("invalid slice bound %q", part)
+			}
+			if i == 0 {
+				s.start = &n
+			} else {
+				s.end = &n
+			}
+		}
+		return s, nil
+	default:
+		s := step{kind: indexStep}
+		for _, part := range strings.Split(inner, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				return step{}, fmt.Errorf("invalid index %q", part)
+			}
+			s.index = append(s.index, n)
+		}
+		return s, nil
+	}
+}
+
+// closing finds the ']' matching the '[' at p.pos, skipping nested brackets
+// and quoted text
+func (p *exprParser) closing() int {
+	depth := 0
+	var quote byte
+	for i := p.pos; i < len(p.s); i++ {
+		c := p.s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitUnion splits a bracket union on the commas outside quoted text
+func splitUnion(s string) []string {
+	parts := make([]string, 0)
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func parseCondition(s string) (*condition, error) {
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		i := strings.Index(s, op)
+		if i < 0 {
+			continue
+		}
+		left, err := parseExpr(strings.TrimSpace(s[:i]))
+		if err != nil {
+			return nil, err
+		}
+		right, err := parseLiteral(strings.TrimSpace(s[i+len(op):]))
+		if err != nil {
+			return nil, err
+		}
+		return &condition{left: left, op: op, right: right}, nil
+	}
+
+	left, err := parseExpr(s)
+	if err != nil {
+		return nil, err
+	}
+	return &condition{left: left}, nil
+}
+
+func parseLiteral(s string) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(s, "'") || strings.HasPrefix(s, `"`):
+		return unquote(s)
+	case s == "true":
+		return true, nil
+	case s == "false":
+		return false, nil
+	case s == "null":
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid literal %q", s)
+	}
+	return f, nil
+}
+
+func unquote(s string) (string, error) {
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return s[1 : len(s)-1], nil
+	}
+	return strconv.Unquote(s)
+}
+
+func execute(w io.Writer, nodes []node, root, current interface{}) error {
+	for _, n := range nodes {
+		switch n.kind {
+		case textNode:
+			if _, err := io.WriteString(w, n.text); err != nil {
+				return err
+			}
+		case exprNode:
+			values, err := eval(n.steps, root, current)
+			if err != nil {
+				return err
+			}
+			parts := make([]string, 0, len(values))
+			for _, v := range values {
+				s, err := format(v)
+				if err != nil {
+					return err
+				}
+				parts = append(parts, s)
+			}
+			if _, err := io.WriteString(w, strings.Join(parts, " ")); err != nil {
+				return err
+			}
+		case rangeNode:
+			values, err := eval(n.steps, root, current)
+			if err != nil {
+				return err
+			}
+			for _, v := range values {
+				items := []interface{}{v}
+				// Ranging over a single array iterates its elements
+				if arr, ok := v.([]interface{}); ok && len(n.steps) > 0 && n.steps[len(n.steps)-1].kind == fieldStep {
+					items = arr
+				}
+				for _, item := range items {
+					if err := execute(w, n.body, root, item); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// eval applies the steps to the current value and returns all matches
+func eval(steps []step, root, current interface{}) ([]interface{}, error) {
+	values := []interface{}{current}
+	for _, s := range steps {
+		next := make([]interface{}, 0)
+		for _, v := range values {
+			matches, err := apply(s, root, v)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, matches...)
+		}
+		values = next
+	}
+	return values, nil
+}
+
+func apply(s step, root, v interface{}) ([]interface{}, error) {
+	switch s.kind {
+	case fieldStep:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+		out := make([]interface{}, 0, len(s.names))
+		for _, name := range s.names {
+			if child, ok := m[name]; ok {
+				out = append(out, child)
+			}
+		}
+		return out, nil
+	case wildcardStep:
+		return children(v), nil
+	case recursiveStep:
+		return recurse(s.name, v), nil
+	case indexStep:
+		arr, ok := v.([]interface{})
+		if !ok {
+			return nil, nil
+		}
+		out := make([]interface{}, 0, len(s.index))
+		for _, i := range s.index {
+			at := i
+			if at < 0 {
+				at += len(arr)
+			}
+			if at < 0 || at >= len(arr) {
+				return nil, fmt.Errorf("array index out of bounds: index %d, length %d", i, len(arr))
+			}
+			out = append(out, arr[at])
+		}
+		return out, nil
+	case sliceStep:
+		arr, ok := v.([]interface{})
+		if !ok {
+			return nil, nil
+		}
+		start, end := 0, len(arr)
+		if s.start != nil {
+			start = clampIndex(*s.start, len(arr))
+		}
+		if s.end != nil {
+			end = clampIndex(*s.end, len(arr))
+		}
+		if start >= end {
+			return nil, nil
+		}
+		return append([]interface{}(nil), arr[start:end]...), nil
+	case filterStep:
+		out := make([]interface{}, 0)
+		for _, item := range children(v) {
+			ok, err := s.cond.match(root, item)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				out = append(out, item)
+			}
+		}
+		return out, nil
+	}
+	return nil, nil
+}
+
+func clampIndex(i, n int) int {
+	if i < 0 {
+		i += n
+	}
+	if i < 0 {
+		return 0
+	}
+	if i > n {
+		return n
+	}
+	return i
+}
+
+// children returns the elements of an array or the values of a map in key order
+func children(v interface{}) []interface{} {
+	switch t := v.(type) {
+	case []interface{}:
+		return t
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		out := make([]interface{}, 0, len(keys))
+		for _, k := range keys {
+			out = append(out, t[k])
+		}
+		return out
+	}
+	return nil
+}
+
+func recurse(name string, v interface{}) []interface{} {
+	out := make([]interface{}, 0)
+	if m, ok := v.(map[string]interface{}); ok {
+		if child, ok := m[name]; ok {
+			out = append(out, child)
+		}
+	}
+	for _, child := range children(v) {
+		out = append(out, recurse(name, child)...)
+	}
+	return out
+}
+
+func (c *condition) match(root, item interface{}) (bool, error) {
+	values, err := eval(c.left, root, item)
+	if err != nil {
+		return false, err
+	}
+	if c.op == "" {
+		return len(values) > 0 && values[0] != nil && values[0] != false, nil
+	}
+	if len(values) == 0 {
+		return c.op == "!=", nil
+	}
+	return c.compare(values[0]), nil
+}
+
+// compare compares a value with the right-hand side of the condition
+func (c *condition) compare(left interface{}) bool {
+	if ls, ok := left.(string); ok {
+		rs, ok := c.right.(string)
+		if !ok {
+			return c.op == "!="
+		}
+		return compare(strings.Compare(ls, rs), c.op)
+	}
+	if lf, ok := left.(float64); ok {
+		rf, ok := c.right.(float64)
+		if !ok {
+			return c.op == "!="
+		}
+		switch {
+		case lf < rf:
+			return compare(-1, c.op)
+		case lf > rf:
+			return compare(1, c.op)
+		}
+		return compare(0, c.op)
+	}
+	switch c.op {
+	case "==":
+		return left == c.right
+	case "!=":
+		return left != c.right
+	}
+	return false
+}
+
+func compare(cmp int, op string) bool {
+	switch op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+// format prints scalars as plain text and maps or arrays as JSON
+func format(v interface{}) (string, error) {
+	switch t := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return t, nil
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(t), nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
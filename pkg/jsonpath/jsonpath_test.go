@@ -0,0 +1,122 @@
+package jsonpath
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+const testDoc = `{
+  "k8sVersion": "1.30",
+  "components": [
+    {"name": "etcd", "status": "compatible", "minor": 5},
+    {"name": "coredns", "status": "incompatible", "minor": 11},
+    {"name": "kubelet", "status": "unknown"}
+  ],
+  "summary": {"totalComponents": 3}
+}`
+
+func TestExecute(t *testing.T) {
+	var data interface{}
+	if err := json.Unmarshal([]byte(testDoc), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"{.k8sVersion}", "1.30"},
+		{"{$.summary.totalComponents}", "3"},
+		{"{.summary}", `{"totalComponents":3}`},
+		{"{.components[*].name}", "etcd coredns kubelet"},
+		{"{.components[0].name}", "etcd"},
+		{"{.components[-1].name}", "kubelet"},
+		{"{.components[-3].name}", "etcd"},
+		{"{.components[2,0].name}", "kubelet etcd"},
+		{"{.components[0:2].name}", "etcd coredns"},
+		{"{.components[1:].name}", "coredns kubelet"},
+		{"{.components[-2:].name}", "coredns kubelet"},
+		{"{.components[:10].name}", "etcd coredns kubelet"},
+		{"{.components[2:1].name}", ""},
+		{"{['k8sVersion']}", "1.30"},
+		{`{.components[0]['name', "status"]}`, "etcd compatible"},
+		{"{.components[*]['name','minor']}", "etcd 5 coredns 11 kubelet"},
+		{"{['k8sVersion','missing']}", "1.30"},
+		{"{..totalComponents}", "3"},
+		{"{..name}", "etcd coredns kubelet"},
+		{"{$..components[1].name}", "coredns"},
+		{`{.components[?(@.name=="coredns")].status}`, "incompatible"},
+		{`{.components[?(@.status!="compatible")].name}`, "coredns kubelet"},
+		{`{.components[?(@.minor>5)].name}`, "coredns"},
+		{`{.components[?(@.minor)].name}`, "etcd coredns"},
+		{`{.components[?(@.minor<=5)].name}`, "etcd"},
+		{`{.components[?(@.name>"d")].name}`, "etcd kubelet"},
+		{`{.components[?(@.name=='kubelet')].minor}`, ""},
+		{`{.components[?(@.missing!="x")].name}`, "etcd coredns kubelet"},
+		{`{..components[?(@.status=="unknown")].name}`, "kubelet"},
+		{`version {.k8sVersion}{"\n"}`, "version 1.30\n"},
+		{`{range .components[*]}{.name}={.status}{"\n"}{end}`, "etcd=compatible\ncoredns=incompatible\nkubelet=unknown\n"},
+		{`{range .components}[{.name}]{end}`, "[etcd][coredns][kubelet]"},
+		{"{.missing}", ""},
+	}
+
+	for _, tt := range tests {
+		jp, err := Parse(tt.template)
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", tt.template, err)
+			continue
+		}
+		var buf bytes.Buffer
+		if err := jp.Execute(&buf, data); err != nil {
+			t.Errorf("Execute(%q) error = %v", tt.template, err)
+			continue
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("Execute(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, template := range []string{
+		"{.name",
+		"{range .items[*]}{.name}",
+		"{.name}{end}",
+		"{.items[abc]}",
+		"{.items['a',b]}",
+		`{"unterminated}`,
+	} {
+		if _, err := Parse(template); err == nil {
+			t.Errorf("Parse(%q) expected error", template)
+		}
+	}
+}
+
+func TestExecuteErrors(t *testing.T) {
+	var data interface{}
+	if err := json.Unmarshal([]byte(testDoc), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"{.components[3].name}", "array index out of bounds: index 3, length 3"},
+		{"{.components[-4].name}", "array index out of bounds: index -4, length 3"},
+		{"{.components[0,5].name}", "array index out of bounds: index 5, length 3"},
+		{"{range .components[*]}{.name}{end}{.components[9]}", "array index out of bounds: index 9, length 3"},
+	}
+	for _, tt := range tests {
+		jp, err := Parse(tt.template)
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", tt.template, err)
+			continue
+		}
+		err = jp.Execute(&bytes.Buffer{}, data)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("Execute(%q) error = %v, want %q", tt.template, err, tt.want)
+		}
+	}
+}
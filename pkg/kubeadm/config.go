@@ -0,0 +1,192 @@
+// Package kubeadm reads kubeadm configuration files and lists the images
+// kubeadm deploys for a Kubernetes release, using the versions in the
+// compatibility data.
+package kubeadm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pmady/kube-dependency-checker/pkg/images"
+	"github.com/pmady/kube-dependency-checker/pkg/inventory"
+	"github.com/pmady/kube-dependency-checker/pkg/version"
+	"gopkg.in/yaml.v3"
+)
+
+// APIGroup is the API group of kubeadm configuration kinds
+const APIGroup = "kubeadm.k8s.io"
+
+// ClusterConfiguration holds the fields of a kubeadm ClusterConfiguration
+// that decide which component versions are deployed
+type ClusterConfiguration struct {
+	KubernetesVersion string    `json:"kubernetesVersion" yaml:"kubernetesVersion"`
+	ImageRepository   string    `json:"imageRepository,omitempty" yaml:"imageRepository,omitempty"`
+	Etcd              Etcd      `json:"etcd,omitempty" yaml:"etcd,omitempty"`
+	DNS               ImageMeta `json:"dns,omitempty" yaml:"dns,omitempty"`
+}
+
+// Etcd selects a local etcd deployed by kubeadm or an external cluster
+type Etcd struct {
+	Local    *ImageMeta    `json:"local,omitempty" yaml:"local,omitempty"`
+	External *ExternalEtcd `json:"external,omitempty" yaml:"external,omitempty"`
+}
+
+// ExternalEtcd is an etcd cluster that kubeadm does not deploy
+type ExternalEtcd struct {
+	Endpoints []string `json:"endpoints,omitempty" yaml:"endpoints,omitempty"`
+}
+
+// ImageMeta overrides the repository and tag of an image
+type ImageMeta struct {
+	ImageRepository string `json:"imageRepository,omitempty" yaml:"imageRepository,omitempty"`
+	ImageTag        string `json:"imageTag,omitempty" yaml:"imageTag,omitempty"`
+}
+
+// Config is a kubeadm configuration file. Documents of other kinds, such
+// as InitConfiguration or KubeletConfiguration, are kept for other checks.
+// A file without a ClusterConfiguration gets kubeadm's defaults, which
+// leave kubernetesVersion unset.
+type Config struct {
+	Cluster   *ClusterConfiguration
+	Documents []Document
+}
+
+// Document is one document of a multi-document configuration file
+type Document struct {
+	APIVersion string
+	Kind       string
+	Node       *yaml.Node
+}
+
+// LoadConfig reads a kubeadm configuration file
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := ParseConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ParseConfig parses a kubeadm configuration of one or more YAML documents
+func ParseConfig(data []byte) (*Config, error) {
+	docs, err := ParseDocuments(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kubeadm configuration: %w", err)
+	}
+	cfg := &Config{Documents: docs}
+	found := false
+	for _, doc := range docs {
+		if !strings.HasPrefix(doc.APIVersion, APIGroup+"/") {
+			continue
+		}
+		found = true
+		if doc.Kind == "ClusterConfiguration" {
+			var cc ClusterConfiguration
+			if err := doc.Node.Decode(&cc); err != nil {
+				return nil, fmt.Errorf("invalid ClusterConfiguration: %w", err)
+			}
+			cfg.Cluster = &cc
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no %s configuration found", APIGroup)
+	}
+	if cfg.Cluster == nil {
+		cfg.Cluster = &ClusterConfiguration{}
+	}
+	return cfg, nil
+}
+
+// ParseDocuments splits a multi-document YAML file into its documents that
+// have a kind
+func ParseDocuments(data []byte) ([]Document, error) {
+	docs := make([]Document, 0)
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var node yaml.Node
+		err := decoder.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var meta struct {
+			APIVersion string `yaml:"apiVersion"`
+			Kind       string `yaml:"kind"`
+		}
+		if err := node.Decode(&meta); err != nil {
+			return nil, err
+		}
+		if meta.Kind == "" {
+			continue
+		}
+		docs = append(docs, Document{APIVersion: meta.APIVersion, Kind: meta.Kind, Node: &node})
+	}
+	return docs, nil
+}
+
+// SetVersion sets kubernetesVersion for a configuration that leaves it
+// out, such as a file with only an InitConfiguration. A version set in the
+// configuration is not overridden.
+func (c *ClusterConfiguration) SetVersion(v string) error {
+	if c.KubernetesVersion != "" {
+		return fmt.Errorf("kubernetesVersion is already set to %s", c.KubernetesVersion)
+	}
+	c.KubernetesVersion = v
+	return nil
+}
+
+// Version returns the parsed kubernetesVersion. Release labels such as
+// stable-1.31 are resolved by kubeadm over the network and are refused.
+func (c *ClusterConfiguration) Version() (*version.Version, error) {
+	if c.KubernetesVersion == "" {
+		return nil, fmt.Errorf("kubernetesVersion is not set")
+	}
+	v, err := version.Parse(c.KubernetesVersion)
+	if err != nil {
+		return nil, fmt.Errorf("kubernetesVersion %q is not an exact version", c.KubernetesVersion)
+	}
+	return v, nil
+}
+
+// Cluster returns the component versions the configuration deploys, read
+// from the images kubeadm would use. The kubelet and container runtime are
+// not part of the configuration and are left out.
+func (c *ClusterConfiguration) Cluster(name string) (*inventory.Cluster, error) {
+	v, err := c.Version()
+	if err != nil {
+		return nil, err
+	}
+	list, err := Images(c)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster := &inventory.Cluster{
+		Name:       name,
+		K8sVersion: v.Raw,
+		Components: make(map[string]string),
+	}
+	for _, image := range list {
+		if image.Component == ComponentAPIServer || image.Component == ComponentPause {
+			continue
+		}
+		ref, err := images.Parse(image.Image)
+		if err != nil {
+			return nil, err
+		}
+		if tag, ok := ref.Version(); ok {
+			cluster.Components[image.Component] = tag
+		}
+	}
+	return cluster, nil
+}
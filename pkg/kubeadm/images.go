@@ -0,0 +1,95 @@
+package kubeadm
+
+import (
+	"fmt"
+
+	"github.com/pmady/kube-dependency-checker/pkg/compatibility"
+)
+
+// DefaultImageRepository is the registry kubeadm pulls from by default
+const DefaultImageRepository = "registry.k8s.io"
+
+// Components of the kubeadm image set
+const (
+	ComponentAPIServer         = "kube-apiserver"
+	ComponentControllerManager = "kube-controller-manager"
+	ComponentScheduler         = "kube-scheduler"
+	ComponentProxy             = "kube-proxy"
+	ComponentCoreDNS           = "coredns"
+	ComponentPause             = "pause"
+	ComponentEtcd              = "etcd"
+)
+
+// Image is an image kubeadm deploys
+type Image struct {
+	Component string `json:"component" yaml:"component"`
+	Image     string `json:"image" yaml:"image"`
+}
+
+// Images returns the images kubeadm pulls for a configuration, in the order
+// of "kubeadm config images list". Control plane images are tagged with
+// kubernetesVersion; CoreDNS, pause and etcd use the recommended versions of
+// the compatibility data unless the configuration overrides their tags.
+// etcd is left out when the configuration uses an external etcd.
+func Images(c *ClusterConfiguration) ([]Image, error) {
+	v, err := c.Version()
+	if err != nil {
+		return nil, err
+	}
+	matrix, ok := compatibility.GetMatrix(v.String())
+	if !ok {
+		return nil, fmt.Errorf("unsupported Kubernetes version: %s", v.ShortString())
+	}
+
+	repo := c.ImageRepository
+	if repo == "" {
+		repo = DefaultImageRepository
+	}
+	list := make([]Image, 0, 7)
+	for _, component := range []string{ComponentAPIServer, ComponentControllerManager, ComponentScheduler, ComponentProxy} {
+		list = append(list, Image{Component: component, Image: fmt.Sprintf("%s/%s:v%s", repo, component, v)})
+	}
+
+	// kubeadm keeps CoreDNS in its own repository only on the default
+	// registry; mirrors hold it at the top level
+	dnsRepo := repo
+	if repo == DefaultImageRepository {
+		dnsRepo = repo + "/coredns"
+	}
+	if tag, ok := imageTag(matrix, ComponentCoreDNS, c.DNS.ImageTag, "v", ""); ok {
+		list = append(list, Image{Component: ComponentCoreDNS, Image: fmt.Sprintf("%s/coredns:%s", override(c.DNS.ImageRepository, dnsRepo), tag)})
+	}
+	if tag, ok := imageTag(matrix, ComponentPause, "", "", ""); ok {
+		list = append(list, Image{Component: ComponentPause, Image: fmt.Sprintf("%s/pause:%s", repo, tag)})
+	}
+	if c.Etcd.External == nil {
+		local := c.Etcd.Local
+		if local == nil {
+			local = &ImageMeta{}
+		}
+		if tag, ok := imageTag(matrix, ComponentEtcd, local.ImageTag, "", "-0"); ok {
+			list = append(list, Image{Component: ComponentEtcd, Image: fmt.Sprintf("%s/etcd:%s", override(local.ImageRepository, repo), tag)})
+		}
+	}
+	return list, nil
+}
+
+// imageTag returns the configured tag, or one built from the component's
+// recommended version in the matrix
+func imageTag(matrix *compatibility.K8sVersionMatrix, component, configured, prefix, suffix string) (string, bool) {
+	if configured != "" {
+		return configured, true
+	}
+	info, ok := matrix.Components[component]
+	if !ok || info.Recommended == "" {
+		return "", false
+	}
+	return prefix + info.Recommended + suffix, true
+}
+
+func override(value, fallback string) string {
+	if value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,161 @@
+package kubeadm
+
+import (
+	"strings"
+	"testing"
+)
+
+const kubeadmConfig = `apiVersion: kubeadm.k8s.io/v1beta4
+kind: InitConfiguration
+nodeRegistration:
+  name: cp-1
+---
+apiVersion: kubeadm.k8s.io/v1beta4
+kind: ClusterConfiguration
+kubernetesVersion: v1.31.4
+imageRepository: harbor.example.com/k8s
+etcd:
+  local:
+    imageTag: 3.5.9-0
+dns:
+  imageTag: v1.11.1
+---
+apiVersion: kubelet.config.k8s.io/v1beta1
+kind: KubeletConfiguration
+cgroupDriver: systemd
+`
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig([]byte(kubeadmConfig))
+	if err != nil {
+		t.Fatalf("ParseConfig() error = %v", err)
+	}
+	if len(cfg.Documents) != 3 || cfg.Documents[2].Kind != "KubeletConfiguration" {
+		t.Errorf("Documents = %+v, want the three documents", cfg.Documents)
+	}
+	if cfg.Cluster.KubernetesVersion != "v1.31.4" || cfg.Cluster.Etcd.Local.ImageTag != "3.5.9-0" || cfg.Cluster.DNS.ImageTag != "v1.11.1" {
+		t.Errorf("Cluster = %+v", cfg.Cluster)
+	}
+
+	// Without a ClusterConfiguration, kubeadm's defaults are used
+	cfg, err = ParseConfig([]byte("apiVersion: kubeadm.k8s.io/v1beta4\nkind: InitConfiguration\n"))
+	if err != nil {
+		t.Fatalf("ParseConfig() of an InitConfiguration error = %v", err)
+	}
+	if cfg.Cluster.KubernetesVersion != "" || cfg.Cluster.ImageRepository != "" {
+		t.Errorf("Cluster = %+v, want the defaults", cfg.Cluster)
+	}
+	if _, err := cfg.Cluster.Version(); err == nil {
+		t.Error("Version() of the defaults expected error")
+	}
+	if err := cfg.Cluster.SetVersion("v1.30.2"); err != nil {
+		t.Errorf("SetVersion() error = %v", err)
+	}
+	if err := cfg.Cluster.SetVersion("v1.31.0"); err == nil || cfg.Cluster.KubernetesVersion != "v1.30.2" {
+		t.Errorf("SetVersion() overrode kubernetesVersion %s", cfg.Cluster.KubernetesVersion)
+	}
+
+	for _, data := range []string{
+		"apiVersion: kubelet.config.k8s.io/v1beta1\nkind: KubeletConfiguration\n",
+		"apiVersion: example.com/v1\nkind: ClusterConfiguration\nkubernetesVersion: v1.31.4\n",
+		"kind: [",
+	} {
+		if _, err := ParseConfig([]byte(data)); err == nil {
+			t.Errorf("ParseConfig(%q) expected error", data)
+		}
+	}
+}
+
+func TestCluster(t *testing.T) {
+	cfg, err := ParseConfig([]byte(kubeadmConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cluster, err := cfg.Cluster.Cluster("kubeadm.yaml")
+	if err != nil {
+		t.Fatalf("Cluster() error = %v", err)
+	}
+	if cluster.K8sVersion != "1.31.4" {
+		t.Errorf("K8sVersion = %s, want 1.31.4", cluster.K8sVersion)
+	}
+	want := map[string]string{
+		ComponentEtcd:              "3.5.9",
+		ComponentCoreDNS:           "1.11.1",
+		ComponentProxy:             "1.31.4",
+		ComponentControllerManager: "1.31.4",
+		ComponentScheduler:         "1.31.4",
+	}
+	if len(cluster.Components) != len(want) {
+		t.Errorf("Components = %v, want %v", cluster.Components, want)
+	}
+	for component, v := range want {
+		if got := cluster.Components[component]; got != v {
+			t.Errorf("%s = %q, want %q", component, got, v)
+		}
+	}
+
+	for _, v := range []string{"", "stable-1.31"} {
+		c := &ClusterConfiguration{KubernetesVersion: v}
+		if _, err := c.Cluster("kubeadm.yaml"); err == nil {
+			t.Errorf("Cluster() with kubernetesVersion %q expected error", v)
+		}
+	}
+}
+
+func TestImages(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  ClusterConfiguration
+		want []string
+	}{
+		{
+			name: "default repository",
+			cfg:  ClusterConfiguration{KubernetesVersion: "1.31.4"},
+			want: []string{
+				"registry.k8s.io/kube-apiserver:v1.31.4",
+				"registry.k8s.io/kube-controller-manager:v1.31.4",
+				"registry.k8s.io/kube-scheduler:v1.31.4",
+				"registry.k8s.io/kube-proxy:v1.31.4",
+				"registry.k8s.io/coredns/coredns:v1.11.3",
+				"registry.k8s.io/pause:3.10",
+				"registry.k8s.io/etcd:3.5.16-0",
+			},
+		},
+		{
+			name: "mirror with overrides and external etcd",
+			cfg: ClusterConfiguration{
+				KubernetesVersion: "v1.30.2",
+				ImageRepository:   "harbor.example.com/k8s",
+				DNS:               ImageMeta{ImageRepository: "harbor.example.com/dns", ImageTag: "v1.11.3"},
+				Etcd:              Etcd{External: &ExternalEtcd{Endpoints: []string{"https://etcd-0:2379"}}},
+			},
+			want: []string{
+				"harbor.example.com/k8s/kube-apiserver:v1.30.2",
+				"harbor.example.com/k8s/kube-controller-manager:v1.30.2",
+				"harbor.example.com/k8s/kube-scheduler:v1.30.2",
+				"harbor.example.com/k8s/kube-proxy:v1.30.2",
+				"harbor.example.com/dns/coredns:v1.11.3",
+				"harbor.example.com/k8s/pause:3.9",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := Images(&tt.cfg)
+			if err != nil {
+				t.Fatalf("Images() error = %v", err)
+			}
+			got := make([]string, len(list))
+			for i, image := range list {
+				got[i] = image.Image
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Images() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+
+	if _, err := Images(&ClusterConfiguration{KubernetesVersion: "1.10.0"}); err == nil {
+		t.Error("Images() expected error for a release without data")
+	}
+}
@@ -0,0 +1,201 @@
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Client performs authenticated GET requests against a Kubernetes API server
+type Client struct {
+	server   string
+	http     *http.Client
+	token    string
+	username string
+	password string
+}
+
+// execCredential is the subset of client.authentication.k8s.io ExecCredential
+// used by the client
+type execCredential struct {
+	Status struct {
+		Token                 string `json:"token"`
+		ClientCertificateData string `json:"clientCertificateData"`
+		ClientKeyData         string `json:"clientKeyData"`
+	} `json:"status"`
+}
+
+// NewClient creates a client for a kubeconfig context. An empty context name
+// selects the current context. ctx bounds any credential plugin invocation.
+func NewClient(ctx context.Context, cfg *Config, contextName string) (*Client, error) {
+	if contextName == "" {
+		contextName = cfg.CurrentContext
+	}
+	if contextName == "" {
+		return nil, fmt.Errorf("no current context set in kubeconfig")
+	}
+
+	kctx, ok := cfg.Contexts[contextName]
+	if !ok {
+		return nil, fmt.Errorf("context %q not found in kubeconfig", contextName)
+	}
+	cluster, ok := cfg.Clusters[kctx.Cluster]
+	if !ok {
+		return nil, fmt.Errorf("cluster %q of context %q not found in kubeconfig", kctx.Cluster, contextName)
+	}
+	if cluster.Server == "" {
+		return nil, fmt.Errorf("cluster %q has no server", kctx.Cluster)
+	}
+	user := cfg.Users[kctx.User]
+
+	tlsConfig := &tls.Config{
+		ServerName:         cluster.TLSServerName,
+		InsecureSkipVerify: cluster.InsecureSkipTLSVerify,
+	}
+
+	caData, err := dataOrFile(cluster.CertificateAuthorityData, cluster.CertificateAuthority)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate authority: %w", err)
+	}
+	if len(caData) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caData) {
+			return nil, fmt.Errorf("no valid certificates in certificate authority of cluster %q", kctx.Cluster)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	c := &Client{
+		server:   strings.TrimSuffix(cluster.Server, "/"),
+		token:    user.Token,
+		username: user.Username,
+		password: user.Password,
+	}
+
+	if c.token == "" && user.TokenFile != "" {
+		data, err := os.ReadFile(user.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read token file: %w", err)
+		}
+		c.token = strings.TrimSpace(string(data))
+	}
+
+	certData, err := dataOrFile(user.ClientCertificateData, user.ClientCertificate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client certificate: %w", err)
+	}
+	keyData, err := dataOrFile(user.ClientKeyData, user.ClientKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client key: %w", err)
+	}
+
+	if user.Exec != nil {
+		cred, err := runExecPlugin(ctx, user.Exec)
+		if err != nil {
+			return nil, fmt.Errorf("credential plugin for user %q: %w", kctx.User, err)
+		}
+		if cred.Status.Token != "" {
+			c.token = cred.Status.Token
+		}
+		if cred.Status.ClientCertificateData != "" {
+			certData = []byte(cred.Status.ClientCertificateData)
+			keyData = []byte(cred.Status.ClientKeyData)
+		}
+	} else if user.AuthProvider != nil {
+		return nil, fmt.Errorf("user %q uses an auth-provider, which is not supported; use an exec credential plugin", kctx.User)
+	}
+
+	if len(certData) > 0 {
+		cert, err := tls.X509KeyPair(certData, keyData)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	c.http = &http.Client{Transport: transport}
+
+	return c, nil
+}
+
+// Get fetches an API path and decodes the JSON response into v
+func (c *Client) Get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.server+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	} else if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("GET %s: %s: %s", path, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func runExecPlugin(ctx context.Context, cfg *ExecConfig) (*execCredential, error) {
+	apiVersion := cfg.APIVersion
+	if apiVersion == "" {
+		apiVersion = "client.authentication.k8s.io/v1"
+	}
+	execInfo, err := json.Marshal(map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       "ExecCredential",
+		"spec":       map[string]interface{}{"interactive": false},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.CommandContext(ctx, cfg.Command, cfg.Args...)
+	cmd.Env = append(os.Environ(), "KUBERNETES_EXEC_INFO="+string(execInfo))
+	for _, e := range cfg.Env {
+		cmd.Env = append(cmd.Env, e.Name+"="+e.Value)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var cred execCredential
+	if err := json.Unmarshal(out, &cred); err != nil {
+		return nil, fmt.Errorf("invalid ExecCredential: %w", err)
+	}
+	return &cred, nil
+}
+
+// dataOrFile returns base64-decoded inline data, or the contents of file
+func dataOrFile(data, file string) ([]byte, error) {
+	if data != "" {
+		return base64.StdEncoding.DecodeString(data)
+	}
+	if file != "" {
+		return os.ReadFile(file)
+	}
+	return nil, nil
+}
@@ -0,0 +1,135 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pmady/kube-dependency-checker/pkg/images"
+	"github.com/pmady/kube-dependency-checker/pkg/inventory"
+	"github.com/pmady/kube-dependency-checker/pkg/version"
+)
+
+// NodePoolLabels are the node labels used to group nodes into pools, in
+// order of preference. Nodes without any of them form the "default" pool.
+var NodePoolLabels = []string{
+	"cloud.google.com/gke-nodepool",
+	"eks.amazonaws.com/nodegroup",
+	"kubernetes.azure.com/agentpool",
+	"karpenter.sh/nodepool",
+	"node.kubernetes.io/pool",
+}
+
+type versionInfo struct {
+	GitVersion string `json:"gitVersion"`
+}
+
+type nodeList struct {
+	Items []struct {
+		Metadata struct {
+			Name   string            `json:"name"`
+			Labels map[string]string `json:"labels"`
+		} `json:"metadata"`
+		Status struct {
+			NodeInfo struct {
+				KubeletVersion          string `json:"kubeletVersion"`
+				ContainerRuntimeVersion string `json:"containerRuntimeVersion"`
+			} `json:"nodeInfo"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// InspectOptions configure Inspect
+type InspectOptions struct {
+	// Images maps container images to components; nil uses images.Default()
+	Images *images.Mapper
+}
+
+// Inspect reads the API server, node and kube-system pod versions of a
+// cluster. Components that cannot be observed, such as a managed control
+// plane's etcd, are left out.
+func Inspect(ctx context.Context, c *Client, name string, opts InspectOptions) (*inventory.Cluster, error) {
+	var info versionInfo
+	if err := c.Get(ctx, "/version", &info); err != nil {
+		return nil, fmt.Errorf("failed to get server version: %w", err)
+	}
+	serverVersion, ok := version.Clean(info.GitVersion)
+	if !ok {
+		return nil, fmt.Errorf("unrecognised server version %q", info.GitVersion)
+	}
+
+	cluster := &inventory.Cluster{
+		Name:       name,
+		K8sVersion: serverVersion,
+		Components: make(map[string]string),
+	}
+
+	var nodes nodeList
+	if err := c.Get(ctx, "/api/v1/nodes", &nodes); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	pools := make(map[string]string)
+	for _, n := range nodes.Items {
+		pool := nodePool(n.Metadata.Labels)
+		if kubelet, ok := version.Clean(n.Status.NodeInfo.KubeletVersion); ok {
+			pools[pool] = oldest(pools[pool], kubelet)
+		}
+
+		runtime, runtimeVersion, found := strings.Cut(n.Status.NodeInfo.ContainerRuntimeVersion, "://")
+		if found && runtime == "containerd" {
+			if v, ok := version.Clean(runtimeVersion); ok {
+				cluster.Components["containerd"] = oldest(cluster.Components["containerd"], v)
+			}
+		}
+	}
+
+	poolNames := make([]string, 0, len(pools))
+	for pool := range pools {
+		poolNames = append(poolNames, pool)
+	}
+	sort.Strings(poolNames)
+	for _, pool := range poolNames {
+		cluster.NodePools = append(cluster.NodePools, inventory.NodePool{Name: pool, KubeletVersion: pools[pool]})
+	}
+
+	mapper := opts.Images
+	if mapper == nil {
+		mapper = images.Default()
+	}
+	var pods images.PodList
+	if err := c.Get(ctx, "/api/v1/namespaces/kube-system/pods", &pods); err != nil {
+		return nil, fmt.Errorf("failed to list kube-system pods: %w", err)
+	}
+	for component, v := range mapper.PodComponents(&pods) {
+		cluster.Components[component] = oldest(cluster.Components[component], v)
+	}
+
+	return cluster, nil
+}
+
+func nodePool(labels map[string]string) string {
+	for _, l := range NodePoolLabels {
+		if pool := labels[l]; pool != "" {
+			return pool
+		}
+	}
+	return "default"
+}
+
+// oldest returns the older of two versions, ignoring an empty current value
+func oldest(current, candidate string) string {
+	if current == "" {
+		return candidate
+	}
+	a, errA := version.Parse(current)
+	b, errB := version.Parse(candidate)
+	if errA != nil || errB != nil {
+		return current
+	}
+	if b.IsOlderThan(a) {
+		return candidate
+	}
+	return current
+}
@@ -0,0 +1,159 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testNodes = `{"items": [
+  {"metadata": {"name": "n1", "labels": {"eks.amazonaws.com/nodegroup": "workers"}},
+   "status": {"nodeInfo": {"kubeletVersion": "v1.30.4-eks-a737599", "containerRuntimeVersion": "containerd://1.7.16"}}},
+  {"metadata": {"name": "n2", "labels": {"eks.amazonaws.com/nodegroup": "workers"}},
+   "status": {"nodeInfo": {"kubeletVersion": "v1.29.6-eks-a737599", "containerRuntimeVersion": "containerd://1.7.11"}}},
+  {"metadata": {"name": "n3", "labels": {}},
+   "status": {"nodeInfo": {"kubeletVersion": "v1.30.1", "containerRuntimeVersion": "docker://24.0.7"}}}
+]}`
+
+const testPods = `{"items": [
+  {"spec": {"containers": [{"image": "registry.k8s.io/etcd:3.5.12-0"}]}},
+  {"spec": {"containers": [{"image": "registry.k8s.io/coredns/coredns:v1.11.1"}]}},
+  {"spec": {"containers": [{"image": "registry.k8s.io/coredns/coredns:v1.11.3"}]}},
+  {"spec": {"containers": [{"image": "registry.k8s.io/kube-proxy:v1.30.4@sha256:0123"}]}},
+  {"spec": {"containers": [{"image": "docker.io/library/nginx:1.25"}]}}
+]}`
+
+func newTestAPIServer(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+	responses := map[string]string{
+		"/version":                            `{"gitVersion": "v1.30.4-eks-a737599"}`,
+		"/api/v1/nodes":                       testNodes,
+		"/api/v1/namespaces/kube-system/pods": testPods,
+	}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func writeKubeconfig(t *testing.T, srv *httptest.Server, token string) string {
+	t.Helper()
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	kubeconfig := fmt.Sprintf(`apiVersion: v1
+kind: Config
+current-context: test
+contexts:
+  - name: test
+    context: {cluster: test, user: test}
+  - name: other
+    context: {cluster: missing, user: test}
+clusters:
+  - name: test
+    cluster:
+      server: %s
+      certificate-authority-data: %s
+users:
+  - name: test
+    user:
+      token: %s
+`, srv.URL, base64.StdEncoding.EncodeToString(ca), token)
+
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(kubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestInspect(t *testing.T) {
+	srv := newTestAPIServer(t, "secret")
+	cfg, err := LoadConfig(writeKubeconfig(t, srv, "secret"))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	client, err := NewClient(context.Background(), cfg, "")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	cluster, err := Inspect(context.Background(), client, "test", InspectOptions{})
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+
+	if cluster.K8sVersion != "1.30.4" {
+		t.Errorf("K8sVersion = %s, want 1.30.4", cluster.K8sVersion)
+	}
+	wantComponents := map[string]string{
+		"etcd":       "3.5.12",
+		"coredns":    "1.11.1",
+		"kube-proxy": "1.30.4",
+		"containerd": "1.7.11",
+	}
+	for name, want := range wantComponents {
+		if got := cluster.Components[name]; got != want {
+			t.Errorf("component %s = %q, want %q", name, got, want)
+		}
+	}
+	if len(cluster.Components) != len(wantComponents) {
+		t.Errorf("Components = %v, want %v", cluster.Components, wantComponents)
+	}
+
+	if len(cluster.NodePools) != 2 {
+		t.Fatalf("NodePools = %+v, want 2 pools", cluster.NodePools)
+	}
+	if p := cluster.NodePools[0]; p.Name != "default" || p.KubeletVersion != "1.30.1" {
+		t.Errorf("NodePools[0] = %+v, want default 1.30.1", p)
+	}
+	if p := cluster.NodePools[1]; p.Name != "workers" || p.KubeletVersion != "1.29.6" {
+		t.Errorf("NodePools[1] = %+v, want workers 1.29.6", p)
+	}
+}
+
+func TestInspectUnauthorized(t *testing.T) {
+	srv := newTestAPIServer(t, "secret")
+	cfg, err := LoadConfig(writeKubeconfig(t, srv, "wrong"))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	client, err := NewClient(context.Background(), cfg, "test")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if _, err := Inspect(context.Background(), client, "test", InspectOptions{}); err == nil {
+		t.Error("Inspect() expected error for rejected credentials")
+	}
+}
+
+func TestNewClientErrors(t *testing.T) {
+	srv := newTestAPIServer(t, "secret")
+	cfg, err := LoadConfig(writeKubeconfig(t, srv, "secret"))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	for _, name := range []string{"missing", "other"} {
+		if _, err := NewClient(context.Background(), cfg, name); err == nil {
+			t.Errorf("NewClient(%s) expected error", name)
+		}
+	}
+	if got := cfg.ContextNames(); len(got) != 2 || got[0] != "other" || got[1] != "test" {
+		t.Errorf("ContextNames() = %v, want [other test]", got)
+	}
+}
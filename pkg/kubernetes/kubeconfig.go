@@ -0,0 +1,179 @@
+// Package kubernetes provides a minimal Kubernetes API client for inspecting
+// the component versions running in a cluster. It reads kubeconfig files and
+// supports token, basic, client certificate and exec plugin authentication.
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is a merged kubeconfig
+type Config struct {
+	CurrentContext string
+	Contexts       map[string]Context
+	Clusters       map[string]Cluster
+	Users          map[string]User
+}
+
+// Context references the cluster and user of a kubeconfig context
+type Context struct {
+	Cluster string `yaml:"cluster"`
+	User    string `yaml:"user"`
+}
+
+// Cluster holds the connection details of a kubeconfig cluster
+type Cluster struct {
+	Server                   string `yaml:"server"`
+	CertificateAuthority     string `yaml:"certificate-authority"`
+	CertificateAuthorityData string `yaml:"certificate-authority-data"`
+	InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+	TLSServerName            string `yaml:"tls-server-name"`
+}
+
+// User holds the credentials of a kubeconfig user
+type User struct {
+	Token                 string      `yaml:"token"`
+	TokenFile             string      `yaml:"tokenFile"`
+	Username              string      `yaml:"username"`
+	Password              string      `yaml:"password"`
+	ClientCertificate     string      `yaml:"client-certificate"`
+	ClientCertificateData string      `yaml:"client-certificate-data"`
+	ClientKey             string      `yaml:"client-key"`
+	ClientKeyData         string      `yaml:"client-key-data"`
+	Exec                  *ExecConfig `yaml:"exec"`
+	AuthProvider          interface{} `yaml:"auth-provider"`
+}
+
+// ExecConfig describes a client-go credential plugin
+type ExecConfig struct {
+	APIVersion string    `yaml:"apiVersion"`
+	Command    string    `yaml:"command"`
+	Args       []string  `yaml:"args"`
+	Env        []ExecEnv `yaml:"env"`
+}
+
+// ExecEnv is an environment variable passed to a credential plugin
+type ExecEnv struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
+// kubeconfigFile is the on-disk kubeconfig layout
+type kubeconfigFile struct {
+	CurrentContext string `yaml:"current-context"`
+	Contexts       []struct {
+		Name    string  `yaml:"name"`
+		Context Context `yaml:"context"`
+	} `yaml:"contexts"`
+	Clusters []struct {
+		Name    string  `yaml:"name"`
+		Cluster Cluster `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User User   `yaml:"user"`
+	} `yaml:"users"`
+}
+
+// DefaultKubeconfigPaths returns the kubeconfig files to load when none is
+// given explicitly: the entries of $KUBECONFIG, or ~/.kube/config
+func DefaultKubeconfigPaths() []string {
+	if env := os.Getenv("KUBECONFIG"); env != "" {
+		paths := make([]string, 0)
+		for _, p := range filepath.SplitList(env) {
+			if p != "" {
+				paths = append(paths, p)
+			}
+		}
+		return paths
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	return []string{filepath.Join(home, ".kube", "config")}
+}
+
+// LoadConfig reads and merges kubeconfig files. As with kubectl, the first
+// file to define a context, cluster or user wins, and relative file
+// references are resolved against the file that contains them.
+func LoadConfig(paths ...string) (*Config, error) {
+	if len(paths) == 0 {
+		paths = DefaultKubeconfigPaths()
+	}
+
+	cfg := &Config{
+		Contexts: make(map[string]Context),
+		Clusters: make(map[string]Cluster),
+		Users:    make(map[string]User),
+	}
+
+	loaded := 0
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) && len(paths) > 1 {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read kubeconfig: %w", err)
+		}
+		loaded++
+
+		var file kubeconfigFile
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("invalid kubeconfig %s: %w", path, err)
+		}
+
+		dir := filepath.Dir(path)
+		if cfg.CurrentContext == "" {
+			cfg.CurrentContext = file.CurrentContext
+		}
+		for _, c := range file.Contexts {
+			if _, ok := cfg.Contexts[c.Name]; !ok {
+				cfg.Contexts[c.Name] = c.Context
+			}
+		}
+		for _, c := range file.Clusters {
+			if _, ok := cfg.Clusters[c.Name]; !ok {
+				c.Cluster.CertificateAuthority = resolvePath(dir, c.Cluster.CertificateAuthority)
+				cfg.Clusters[c.Name] = c.Cluster
+			}
+		}
+		for _, u := range file.Users {
+			if _, ok := cfg.Users[u.Name]; !ok {
+				u.User.TokenFile = resolvePath(dir, u.User.TokenFile)
+				u.User.ClientCertificate = resolvePath(dir, u.User.ClientCertificate)
+				u.User.ClientKey = resolvePath(dir, u.User.ClientKey)
+				cfg.Users[u.Name] = u.User
+			}
+		}
+	}
+
+	if loaded == 0 {
+		return nil, fmt.Errorf("no kubeconfig found in %s", strings.Join(paths, ", "))
+	}
+	return cfg, nil
+}
+
+// ContextNames returns all context names in sorted order
+func (c *Config) ContextNames() []string {
+	names := make([]string, 0, len(c.Contexts))
+	for name := range c.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func resolvePath(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
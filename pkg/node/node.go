@@ -0,0 +1,279 @@
+// Package node gathers the versions of the node components, and facts about
+// the host, from files on a Kubernetes node. Binary versions are read from
+// captured output, e.g. "kubelet --version > kubelet.txt", so that images can
+// be checked without running the binaries.
+package node
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pmady/kube-dependency-checker/pkg/version"
+	"gopkg.in/yaml.v3"
+)
+
+// Default locations on the host
+const (
+	DefaultContainerdConfig = "/etc/containerd/config.toml"
+	DefaultCgroupRoot       = "/sys/fs/cgroup"
+	DefaultKernelRelease    = "/proc/sys/kernel/osrelease"
+	DefaultOSRelease        = "/etc/os-release"
+	DefaultSwaps            = "/proc/swaps"
+)
+
+// Sources locate the inputs of a node check. Paths are host paths, read
+// through FS.
+type Sources struct {
+	// KubeletVersion, ContainerdVersion and RuncVersion are files holding the
+	// output of "kubelet --version", "containerd --version" and
+	// "runc --version"
+	KubeletVersion    string
+	ContainerdVersion string
+	RuncVersion       string
+	ContainerdConfig  string
+	CgroupRoot        string
+	KernelRelease     string
+	OSRelease         string
+	Swaps             string
+
+	// FS is the host filesystem; nil reads the real one
+	FS fs.FS
+}
+
+// Facts are the versions and settings found on a node
+type Facts struct {
+	Hostname   string `json:"hostname,omitempty" yaml:"hostname,omitempty"`
+	Kubelet    string `json:"kubelet,omitempty" yaml:"kubelet,omitempty"`
+	Containerd string `json:"containerd,omitempty" yaml:"containerd,omitempty"`
+	Runc       string `json:"runc,omitempty" yaml:"runc,omitempty"`
+	// CgroupVersion is 1 or 2, or 0 when it could not be determined
+	CgroupVersion int `json:"cgroupVersion,omitempty" yaml:"cgroupVersion,omitempty"`
+	// Kernel is the Linux kernel version, e.g. 5.15.0
+	Kernel string `json:"kernel,omitempty" yaml:"kernel,omitempty"`
+	OS     string `json:"os,omitempty" yaml:"os,omitempty"`
+	// Swap reports whether swap is on, nil when unknown
+	Swap             *bool             `json:"swap,omitempty" yaml:"swap,omitempty"`
+	ContainerdConfig *ContainerdConfig `json:"containerdConfig,omitempty" yaml:"containerdConfig,omitempty"`
+}
+
+// ContainerdConfig holds the settings of containerd's config.toml that
+// matter to the kubelet
+type ContainerdConfig struct {
+	Version       int    `json:"version,omitempty" yaml:"version,omitempty"`
+	SystemdCgroup bool   `json:"systemdCgroup" yaml:"systemdCgroup"`
+	SandboxImage  string `json:"sandboxImage,omitempty" yaml:"sandboxImage,omitempty"`
+}
+
+// Gather reads the sources. Version files that are given must exist; facts
+// whose host files are missing are left unset.
+func Gather(src Sources) (*Facts, error) {
+	fsys := src.FS
+	if fsys == nil {
+		fsys = os.DirFS("/")
+	}
+	facts := &Facts{}
+
+	versions := []struct {
+		path, binary string
+		target       *string
+	}{
+		{src.KubeletVersion, "kubelet", &facts.Kubelet},
+		{src.ContainerdVersion, "containerd", &facts.Containerd},
+		{src.RuncVersion, "runc", &facts.Runc},
+	}
+	for _, v := range versions {
+		if v.path == "" {
+			continue
+		}
+		data, err := readFile(fsys, v.path)
+		if err != nil {
+			return nil, err
+		}
+		found, ok := ParseVersionOutput(data)
+		if !ok {
+			return nil, fmt.Errorf("%s: no %s version found", v.path, v.binary)
+		}
+		*v.target = found
+	}
+
+	if src.ContainerdConfig != "" {
+		data, err := readFile(fsys, src.ContainerdConfig)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case err != nil:
+			return nil, err
+		default:
+			facts.ContainerdConfig = ParseContainerdConfig(data)
+		}
+	}
+
+	if src.CgroupRoot != "" {
+		root := hostPath(src.CgroupRoot)
+		if _, err := fs.Stat(fsys, root+"/cgroup.controllers"); err == nil {
+			facts.CgroupVersion = 2
+		} else if _, err := fs.Stat(fsys, root); err == nil {
+			facts.CgroupVersion = 1
+		}
+	}
+
+	if src.KernelRelease != "" {
+		if data, err := readFile(fsys, src.KernelRelease); err == nil {
+			facts.Kernel, _ = version.Clean(string(data))
+		}
+	}
+	if src.OSRelease != "" {
+		if data, err := readFile(fsys, src.OSRelease); err == nil {
+			facts.OS = ParseOSRelease(data)
+		}
+	}
+	if src.Swaps != "" {
+		if data, err := readFile(fsys, src.Swaps); err == nil {
+			// /proc/swaps has a header line and a line per swap device
+			swap := len(strings.Split(strings.TrimSpace(string(data)), "\n")) > 1
+			facts.Swap = &swap
+		}
+	}
+	return facts, nil
+}
+
+// LoadFacts reads node facts from a YAML or JSON file. Unknown keys are an
+// error, so that a misspelt fact is not taken as left out.
+func LoadFacts(path string) (*Facts, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var facts Facts
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&facts); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: invalid node facts: %w", path, err)
+	}
+	// A distro kernel such as 4.18.0-553.el8_10.x86_64 is kept as 4.18.0,
+	// as Gather keeps it
+	if facts.Kernel != "" {
+		kernel, ok := version.Clean(facts.Kernel)
+		if !ok {
+			return nil, fmt.Errorf("%s: invalid kernel version %q", path, facts.Kernel)
+		}
+		facts.Kernel = kernel
+	}
+	return &facts, nil
+}
+
+// Merge sets the facts that are set in other
+func (f *Facts) Merge(other *Facts) {
+	set := func(target *string, value string) {
+		if value != "" {
+			*target = value
+		}
+	}
+	set(&f.Hostname, other.Hostname)
+	set(&f.Kubelet, other.Kubelet)
+	set(&f.Containerd, other.Containerd)
+	set(&f.Runc, other.Runc)
+	set(&f.Kernel, other.Kernel)
+	set(&f.OS, other.OS)
+	if other.CgroupVersion != 0 {
+		f.CgroupVersion = other.CgroupVersion
+	}
+	if other.Swap != nil {
+		f.Swap = other.Swap
+	}
+	if other.ContainerdConfig != nil {
+		f.ContainerdConfig = other.ContainerdConfig
+	}
+}
+
+// ParseOSRelease returns the PRETTY_NAME of an os-release file
+func ParseOSRelease(data []byte) string {
+	for _, line := range strings.Split(string(data), "\n") {
+		if value, ok := strings.CutPrefix(line, "PRETTY_NAME="); ok {
+			return strings.Trim(strings.TrimSpace(value), `"'`)
+		}
+	}
+	return ""
+}
+
+func readFile(fsys fs.FS, path string) ([]byte, error) {
+	data, err := fs.ReadFile(fsys, hostPath(path))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return data, nil
+}
+
+// hostPath converts a host path to a path of the root filesystem
+func hostPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	path = filepath.ToSlash(path)
+	if vol := filepath.VolumeName(path); vol != "" {
+		path = path[len(vol):]
+	}
+	return strings.TrimPrefix(path, "/")
+}
+
+var versionTokenRe = regexp.MustCompile(`^v?\d+\.\d+\.\d+`)
+
+// ParseVersionOutput returns the first version in the output of a
+// "--version" flag: "Kubernetes v1.31.4", "containerd
+// github.com/containerd/containerd v1.7.20 8fc6bcf" and "runc version 1.1.13"
+// give 1.31.4, 1.7.20 and 1.1.13
+func ParseVersionOutput(data []byte) (string, bool) {
+	for _, field := range strings.Fields(string(data)) {
+		if versionTokenRe.MatchString(field) {
+			return version.Clean(field)
+		}
+	}
+	return "", false
+}
+
+var tomlKeyRe = regexp.MustCompile(`^\s*([A-Za-z_]+)\s*=\s*(.+?)\s*$`)
+
+// ParseContainerdConfig reads the settings of a containerd config.toml.
+// Only the keys used are looked at, wherever they appear: "version",
+// "SystemdCgroup" and the sandbox image, "sandbox_image" in version 2
+// configurations and "sandbox" in version 3.
+func ParseContainerdConfig(data []byte) *ContainerdConfig {
+	cfg := &ContainerdConfig{}
+	section := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			section = trimmed
+			continue
+		}
+		m := tomlKeyRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		value := strings.Trim(m[2], `"'`)
+		switch m[1] {
+		case "version":
+			if section == "" {
+				cfg.Version, _ = strconv.Atoi(value)
+			}
+		case "SystemdCgroup":
+			cfg.SystemdCgroup = value == "true"
+		case "sandbox_image", "sandbox":
+			cfg.SandboxImage = value
+		}
+	}
+	return cfg
+}
@@ -0,0 +1,150 @@
+package node
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+const containerdConfigV2 = `version = 2
+
+[plugins."io.containerd.grpc.v1.cri"]
+  sandbox_image = "registry.k8s.io/pause:3.10" # pinned by the image build
+  [plugins."io.containerd.grpc.v1.cri".containerd.runtimes.runc.options]
+    SystemdCgroup = true
+`
+
+const containerdConfigV3 = `version = 3
+
+[plugins.'io.containerd.cri.v1.images'.pinned_images]
+  sandbox = 'registry.k8s.io/pause:3.10'
+
+[plugins.'io.containerd.cri.v1.runtime'.containerd.runtimes.runc.options]
+  # SystemdCgroup = true
+  SystemdCgroup = false
+`
+
+func TestGather(t *testing.T) {
+	fsys := fstest.MapFS{
+		"tmp/kubelet.txt":                  {Data: []byte("Kubernetes v1.31.4\n")},
+		"tmp/containerd.txt":               {Data: []byte("containerd github.com/containerd/containerd v1.7.20 8fc6bcff51318944179630522a095cc9dbf9f353\n")},
+		"tmp/runc.txt":                     {Data: []byte("runc version 1.1.13\ncommit: v1.1.13-0-g58aa920\nspec: 1.0.2-dev\ngo: go1.22.5\n")},
+		"etc/containerd/config.toml":       {Data: []byte(containerdConfigV2)},
+		"sys/fs/cgroup/cgroup.controllers": {Data: []byte("cpuset cpu io memory pids\n")},
+		"proc/sys/kernel/osrelease":        {Data: []byte("5.15.0-1051-aws\n")},
+		"etc/os-release":                   {Data: []byte("NAME=\"Ubuntu\"\nPRETTY_NAME=\"Ubuntu 22.04.4 LTS\"\n")},
+		"proc/swaps":                       {Data: []byte("Filename\tType\tSize\tUsed\tPriority\n")},
+	}
+	facts, err := Gather(Sources{
+		KubeletVersion:    "/tmp/kubelet.txt",
+		ContainerdVersion: "/tmp/containerd.txt",
+		RuncVersion:       "/tmp/runc.txt",
+		ContainerdConfig:  DefaultContainerdConfig,
+		CgroupRoot:        DefaultCgroupRoot,
+		KernelRelease:     DefaultKernelRelease,
+		OSRelease:         DefaultOSRelease,
+		Swaps:             DefaultSwaps,
+		FS:                fsys,
+	})
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	if facts.Kubelet != "1.31.4" || facts.Containerd != "1.7.20" || facts.Runc != "1.1.13" {
+		t.Errorf("versions = %s, %s, %s", facts.Kubelet, facts.Containerd, facts.Runc)
+	}
+	if facts.CgroupVersion != 2 {
+		t.Errorf("CgroupVersion = %d, want 2", facts.CgroupVersion)
+	}
+	if facts.Kernel != "5.15.0" || facts.OS != "Ubuntu 22.04.4 LTS" || facts.Swap == nil || *facts.Swap {
+		t.Errorf("host facts = %s, %s, swap %v", facts.Kernel, facts.OS, facts.Swap)
+	}
+	want := ContainerdConfig{Version: 2, SystemdCgroup: true, SandboxImage: "registry.k8s.io/pause:3.10"}
+	if facts.ContainerdConfig == nil || *facts.ContainerdConfig != want {
+		t.Errorf("ContainerdConfig = %+v, want %+v", facts.ContainerdConfig, want)
+	}
+
+	// A cgroup v1 host without a containerd configuration
+	fsys = fstest.MapFS{"sys/fs/cgroup/memory/memory.limit_in_bytes": {Data: []byte("0\n")}}
+	facts, err = Gather(Sources{ContainerdConfig: DefaultContainerdConfig, CgroupRoot: DefaultCgroupRoot, FS: fsys})
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	if facts.CgroupVersion != 1 || facts.ContainerdConfig != nil || facts.Swap != nil {
+		t.Errorf("facts = %+v, want cgroup v1 and no containerd config", facts)
+	}
+
+	// Version files that are given must exist and hold a version
+	fsys = fstest.MapFS{"tmp/kubelet.txt": {Data: []byte("command not found\n")}}
+	for _, path := range []string{"/tmp/kubelet.txt", "/tmp/missing.txt"} {
+		if _, err := Gather(Sources{KubeletVersion: path, FS: fsys}); err == nil {
+			t.Errorf("Gather() with kubelet version file %s expected error", path)
+		}
+	}
+}
+
+func TestParseContainerdConfig(t *testing.T) {
+	got := ParseContainerdConfig([]byte(containerdConfigV3))
+	want := ContainerdConfig{Version: 3, SystemdCgroup: false, SandboxImage: "registry.k8s.io/pause:3.10"}
+	if *got != want {
+		t.Errorf("ParseContainerdConfig() = %+v, want %+v", *got, want)
+	}
+}
+
+func TestLoadFacts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "facts.yaml")
+	data := "kernel: 4.14.0\nswap: true\ncontainerdConfig:\n  systemdCgroup: false\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	supplied, err := LoadFacts(path)
+	if err != nil {
+		t.Fatalf("LoadFacts() error = %v", err)
+	}
+
+	facts := &Facts{Kubelet: "1.31.4", Kernel: "6.1.0", CgroupVersion: 2}
+	facts.Merge(supplied)
+	if facts.Kubelet != "1.31.4" || facts.Kernel != "4.14.0" || facts.CgroupVersion != 2 {
+		t.Errorf("merged facts = %+v", facts)
+	}
+	if facts.Swap == nil || !*facts.Swap || facts.ContainerdConfig == nil || facts.ContainerdConfig.SystemdCgroup {
+		t.Errorf("merged swap and containerd config = %v, %+v", facts.Swap, facts.ContainerdConfig)
+	}
+}
+
+func TestLoadFactsKernel(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		kernel  string
+		want    string
+		wantErr bool
+	}{
+		{kernel: "4.18.0-553.el8_10.x86_64", want: "4.18.0"},
+		{kernel: "6.8.0-1015-aws", want: "6.8.0"},
+		{kernel: "el8", wantErr: true},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, "facts.yaml")
+		if err := os.WriteFile(path, []byte("kernel: "+tt.kernel+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		facts, err := LoadFacts(path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("LoadFacts(kernel %s) error = %v, wantErr %v", tt.kernel, err, tt.wantErr)
+			continue
+		}
+		if err == nil && facts.Kernel != tt.want {
+			t.Errorf("LoadFacts(kernel %s) kernel = %s, want %s", tt.kernel, facts.Kernel, tt.want)
+		}
+	}
+}
+
+func TestLoadFactsUnknownKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "facts.yaml")
+	if err := os.WriteFile(path, []byte("kubelet: 1.31.4\ncgroup: 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFacts(path); err == nil {
+		t.Error("LoadFacts() expected error for the misspelt cgroupVersion")
+	}
+}
@@ -0,0 +1,65 @@
+package output
+
+import (
+	_ "embed"
+	"html/template"
+	"io"
+)
+
+//go:embed report.html.tmpl
+var htmlTemplateText string
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"status":      formatStatus,
+	"orDash":      orDash,
+	"hasCurrent":  hasCurrent,
+	"summaryText": summaryText,
+	"reminders":   func() []string { return SkewPolicyReminders },
+}).Parse(htmlTemplateText))
+
+// HTMLFormatter outputs results as a self-contained HTML report
+type HTMLFormatter struct {
+	Writer io.Writer
+}
+
+// htmlReport is the data passed to the report template; exactly one of the
+// result fields is set
+type htmlReport struct {
+	Title    string
+	Check    *CheckResult
+	Fleet    *FleetResult
+	Upgrade  *UpgradeResult
+	Versions *VersionsResult
+}
+
+// Format outputs the result as HTML
+func (f *HTMLFormatter) Format(result *CheckResult) error {
+	title := "Kubernetes " + result.K8sVersion + " compatibility"
+	if result.Cluster != "" {
+		title = result.Cluster + ": " + title
+	}
+	return htmlTemplate.Execute(f.Writer, htmlReport{Title: title, Check: result})
+}
+
+// FormatFleet outputs the fleet result as HTML
+func (f *HTMLFormatter) FormatFleet(result *FleetResult) error {
+	return htmlTemplate.Execute(f.Writer, htmlReport{Title: "Fleet compatibility", Fleet: result})
+}
+
+// FormatUpgrade outputs the upgrade plan as HTML
+func (f *HTMLFormatter) FormatUpgrade(result *UpgradeResult) error {
+	title := "Upgrade path: " + result.From + " → " + result.To
+	return htmlTemplate.Execute(f.Writer, htmlReport{Title: title, Upgrade: result})
+}
+
+// FormatVersions outputs the versions query result as HTML
+func (f *HTMLFormatter) FormatVersions(result *VersionsResult) error {
+	title := "Supported Kubernetes versions"
+	switch {
+	case result.Component != "" && result.K8sVersion != "":
+		title = result.Component + " compatibility for Kubernetes " + result.K8sVersion
+	case result.Component != "":
+		title = result.Component + " versions across Kubernetes releases"
+	}
+	return htmlTemplate.Execute(f.Writer, htmlReport{Title: title, Versions: result})
+}
@@ -0,0 +1,181 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// JUnitFormatter outputs check results as JUnit XML for CI test reports.
+// Each component is a test case: incompatible components fail and
+// components with unknown versions or waivers are skipped.
+type JUnitFormatter struct {
+	Writer io.Writer
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Body    string `xml:",chardata"`
+}
+
+// Format outputs the check result as JUnit XML
+func (f *JUnitFormatter) Format(result *CheckResult) error {
+	return f.write([]junitTestSuite{checkTestSuite(result)})
+}
+
+// FormatFleet outputs one test suite per cluster, plus a suite of errors
+// for clusters that could not be checked
+func (f *JUnitFormatter) FormatFleet(result *FleetResult) error {
+	suites := make([]junitTestSuite, 0, len(result.Clusters)+1)
+	for i := range result.Clusters {
+		suites = append(suites, checkTestSuite(&result.Clusters[i]))
+	}
+
+	if len(result.Failures) > 0 {
+		suite := junitTestSuite{Name: "unreachable clusters"}
+		for _, failure := range result.Failures {
+			suite.TestCases = append(suite.TestCases, junitTestCase{
+				Name:      failure.Cluster,
+				ClassName: failure.Cluster,
+				Error: &junitMessage{
+					Message: "cluster could not be checked",
+					Type:    RuleClusterUnreachable,
+					Body:    failure.Error,
+				},
+			})
+			suite.Tests++
+			suite.Errors++
+		}
+		suites = append(suites, suite)
+	}
+
+	return f.write(suites)
+}
+
+// FormatUpgrade is not supported for JUnit output
+func (f *JUnitFormatter) FormatUpgrade(result *UpgradeResult) error {
+	return fmt.Errorf("junit output is only supported for check results")
+}
+
+// FormatVersions is not supported for JUnit output
+func (f *JUnitFormatter) FormatVersions(result *VersionsResult) error {
+	return fmt.Errorf("junit output is only supported for check results")
+}
+
+func (f *JUnitFormatter) write(suites []junitTestSuite) error {
+	doc := junitTestSuites{Name: "kube-dependency-checker", Suites: suites}
+	for _, s := range suites {
+		doc.Tests += s.Tests
+		doc.Failures += s.Failures
+		doc.Errors += s.Errors
+		doc.Skipped += s.Skipped
+	}
+
+	if _, err := io.WriteString(f.Writer, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(f.Writer)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(f.Writer, "\n")
+	return err
+}
+
+func checkTestSuite(result *CheckResult) junitTestSuite {
+	name := "Kubernetes " + result.K8sVersion
+	className := "kubernetes-" + result.K8sVersion
+	if result.Cluster != "" {
+		name = fmt.Sprintf("%s (Kubernetes %s)", result.Cluster, result.K8sVersion)
+		className = result.Cluster
+	}
+
+	suite := junitTestSuite{Name: name, Tests: len(result.Components)}
+	for _, c := range result.Components {
+		tc := junitTestCase{Name: c.Name, ClassName: className}
+		switch c.Status {
+		case "compatible":
+		case "incompatible":
+			tc.Failure = &junitMessage{
+				Message: findingMessage(result, c),
+				Type:    RuleIncompatibleComponent,
+			}
+			suite.Failures++
+		case "waived":
+			tc.Skipped = &junitMessage{Message: fmt.Sprintf("%s. %s", findingMessage(result, c), c.Notes)}
+			suite.Skipped++
+		default:
+			tc.Skipped = &junitMessage{Message: findingMessage(result, c)}
+			suite.Skipped++
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	// Findings fail the suite when they are errors; warnings and
+	// informational findings are reported as output of a passing case
+	for _, finding := range result.Findings {
+		tc := junitTestCase{Name: junitFindingName(finding), ClassName: className}
+		if finding.Severity == "error" {
+			tc.Failure = &junitMessage{Message: finding.Message, Type: findingRuleID(finding)}
+			suite.Failures++
+		} else {
+			tc.SystemOut = fmt.Sprintf("%s: %s", finding.Severity, finding.Message)
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+		suite.Tests++
+	}
+	return suite
+}
+
+// junitFindingName names the test case of a finding after its rule, subject,
+// component and source, so that findings of one rule stay apart
+func junitFindingName(finding Finding) string {
+	name := finding.Rule
+	if _, ok := findingRules[finding.Rule]; !ok {
+		name = "policy " + name
+	}
+	if finding.Subject != "" {
+		name += " " + finding.Subject
+	}
+	var where []string
+	for _, s := range []string{finding.Component, finding.Source} {
+		if s != "" {
+			where = append(where, s)
+		}
+	}
+	if len(where) > 0 {
+		name = fmt.Sprintf("%s (%s)", name, strings.Join(where, ", "))
+	}
+	return name
+}
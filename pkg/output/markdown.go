@@ -0,0 +1,243 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MarkdownFormatter outputs results as GitHub-flavoured Markdown, suitable
+// for pull request comments
+type MarkdownFormatter struct {
+	Writer io.Writer
+}
+
+// Format outputs the result as Markdown
+func (f *MarkdownFormatter) Format(result *CheckResult) error {
+	f.writeCheck(result, "##")
+	return nil
+}
+
+func (f *MarkdownFormatter) writeCheck(result *CheckResult, heading string) {
+	title := fmt.Sprintf("Kubernetes %s compatibility", result.K8sVersion)
+	if result.Cluster != "" {
+		title = fmt.Sprintf("%s: Kubernetes %s compatibility", result.Cluster, result.K8sVersion)
+	}
+	_, _ = fmt.Fprintf(f.Writer, "%s %s\n\n", heading, mdEscape(title))
+	f.warnings(result.Warnings)
+
+	showCurrent := hasCurrent(result)
+	if showCurrent {
+		_, _ = fmt.Fprintln(f.Writer, "| Component | Current | Required | Recommended | Status |")
+		_, _ = fmt.Fprintln(f.Writer, "|-----------|---------|----------|-------------|--------|")
+	} else {
+		_, _ = fmt.Fprintln(f.Writer, "| Component | Required | Recommended | Status |")
+		_, _ = fmt.Fprintln(f.Writer, "|-----------|----------|-------------|--------|")
+	}
+
+	for _, c := range result.Components {
+		required := c.Required
+		if required == "" {
+			required = c.SkewPolicy
+		}
+		cells := []string{c.Name}
+		if showCurrent {
+			cells = append(cells, orDash(c.Current))
+		}
+		cells = append(cells, orDash(required), orDash(c.Recommended), formatStatus(c.Status))
+		f.row(cells...)
+	}
+
+	if len(result.Findings) > 0 {
+		_, _ = fmt.Fprintln(f.Writer)
+		_, _ = fmt.Fprintln(f.Writer, "| Severity | Rule | Component | Finding |")
+		_, _ = fmt.Fprintln(f.Writer, "|----------|------|-----------|---------|")
+		for _, finding := range result.Findings {
+			f.row(finding.Severity, finding.Rule, orDash(finding.Component), finding.Message)
+		}
+	}
+
+	_, _ = fmt.Fprintf(f.Writer, "\n**Summary:** %s\n\n", summaryText(result.Summary))
+}
+
+// FormatFleet outputs the fleet result as Markdown
+func (f *MarkdownFormatter) FormatFleet(result *FleetResult) error {
+	_, _ = fmt.Fprintln(f.Writer, "## Fleet compatibility")
+	_, _ = fmt.Fprintln(f.Writer)
+	_, _ = fmt.Fprintln(f.Writer, "| Clusters | Compatible | Incompatible | Could not be checked |")
+	_, _ = fmt.Fprintln(f.Writer, "|----------|------------|--------------|----------------------|")
+	f.row(
+		fmt.Sprint(result.Summary.TotalClusters),
+		fmt.Sprint(result.Summary.CompatibleClusters),
+		fmt.Sprint(result.Summary.IncompatibleClusters),
+		fmt.Sprint(result.Summary.UnreachableClusters),
+	)
+	_, _ = fmt.Fprintln(f.Writer)
+
+	if len(result.Failures) > 0 {
+		_, _ = fmt.Fprintln(f.Writer, "### Clusters that could not be checked")
+		_, _ = fmt.Fprintln(f.Writer)
+		for _, failure := range result.Failures {
+			_, _ = fmt.Fprintf(f.Writer, "- **%s**: %s\n", mdEscape(failure.Cluster), mdEscape(failure.Error))
+		}
+		_, _ = fmt.Fprintln(f.Writer)
+	}
+
+	for i := range result.Clusters {
+		f.writeCheck(&result.Clusters[i], "###")
+	}
+	return nil
+}
+
+// FormatUpgrade outputs the upgrade plan as Markdown
+func (f *MarkdownFormatter) FormatUpgrade(result *UpgradeResult) error {
+	_, _ = fmt.Fprintf(f.Writer, "## Upgrade path: %s → %s\n\n", mdEscape(result.From), mdEscape(result.To))
+	f.warnings(result.Warnings)
+
+	_, _ = fmt.Fprintln(f.Writer, "### Recommended upgrade steps")
+	_, _ = fmt.Fprintln(f.Writer)
+	for _, s := range result.Steps {
+		_, _ = fmt.Fprintf(f.Writer, "%d. %s → %s\n", s.Step, mdEscape(s.From), mdEscape(s.To))
+	}
+	_, _ = fmt.Fprintln(f.Writer)
+	_, _ = fmt.Fprintln(f.Writer, "> **Note:** Kubernetes supports upgrading one minor version at a time.")
+	_, _ = fmt.Fprintln(f.Writer)
+
+	_, _ = fmt.Fprintln(f.Writer, "### Component version changes")
+	_, _ = fmt.Fprintln(f.Writer)
+	f.row("Component", result.From, result.To, "Changed")
+	_, _ = fmt.Fprintln(f.Writer, "|-----------|------|----|---------|")
+	for _, c := range result.Components {
+		changed := ""
+		if c.Changed {
+			changed = "⬆️"
+		}
+		f.row(c.Name, c.From, c.To, changed)
+	}
+	_, _ = fmt.Fprintln(f.Writer)
+
+	_, _ = fmt.Fprintln(f.Writer, "### Version skew policy reminders")
+	_, _ = fmt.Fprintln(f.Writer)
+	for _, r := range SkewPolicyReminders {
+		_, _ = fmt.Fprintf(f.Writer, "- %s\n", r)
+	}
+	_, _ = fmt.Fprintln(f.Writer)
+
+	return nil
+}
+
+// FormatVersions outputs the versions query result as Markdown
+func (f *MarkdownFormatter) FormatVersions(result *VersionsResult) error {
+	if result.Component == "" {
+		_, _ = fmt.Fprintln(f.Writer, "## Supported Kubernetes versions")
+		_, _ = fmt.Fprintln(f.Writer)
+		f.warnings(result.Warnings)
+		for _, v := range result.K8sVersions {
+			_, _ = fmt.Fprintf(f.Writer, "- %s\n", mdEscape(v))
+		}
+		_, _ = fmt.Fprintln(f.Writer)
+		if len(result.ArchivedVersions) > 0 {
+			_, _ = fmt.Fprintln(f.Writer, "## Archived (out of support)")
+			_, _ = fmt.Fprintln(f.Writer)
+			for _, v := range result.ArchivedVersions {
+				_, _ = fmt.Fprintf(f.Writer, "- %s\n", mdEscape(v))
+			}
+			_, _ = fmt.Fprintln(f.Writer)
+		}
+		return nil
+	}
+
+	if result.K8sVersion != "" {
+		for _, e := range result.Entries {
+			_, _ = fmt.Fprintf(f.Writer, "## %s compatibility for Kubernetes %s\n\n", mdEscape(e.Name), mdEscape(e.K8sVersion))
+			f.warnings(result.Warnings)
+			_, _ = fmt.Fprintln(f.Writer, "| Field | Value |")
+			_, _ = fmt.Fprintln(f.Writer, "|-------|-------|")
+			for _, field := range [][2]string{
+				{"Version", e.Version},
+				{"Recommended", e.Recommended},
+				{"Min Version", e.MinVersion},
+				{"Max Version", e.MaxVersion},
+				{"Skew Policy", e.SkewPolicy},
+				{"Notes", e.Notes},
+			} {
+				if field[1] != "" {
+					f.row(field[0], field[1])
+				}
+			}
+			_, _ = fmt.Fprintln(f.Writer)
+		}
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(f.Writer, "## %s versions across Kubernetes releases\n\n", mdEscape(result.Component))
+	f.warnings(result.Warnings)
+	_, _ = fmt.Fprintln(f.Writer, "| Kubernetes | Version | Recommended |")
+	_, _ = fmt.Fprintln(f.Writer, "|------------|---------|-------------|")
+	for _, e := range result.Entries {
+		v := e.Version
+		if v == "" && e.SkewPolicy != "" {
+			v = "(skew policy)"
+		}
+		f.row(e.K8sVersion, orDash(v), orDash(e.Recommended))
+	}
+	_, _ = fmt.Fprintln(f.Writer)
+
+	return nil
+}
+
+// warnings writes each warning as a block quote
+func (f *MarkdownFormatter) warnings(warnings []string) {
+	for _, w := range warnings {
+		_, _ = fmt.Fprintf(f.Writer, "> **Warning:** %s\n\n", mdEscape(w))
+	}
+}
+
+func (f *MarkdownFormatter) row(cells ...string) {
+	escaped := make([]string, len(cells))
+	for i, c := range cells {
+		escaped[i] = mdEscape(c)
+	}
+	_, _ = fmt.Fprintf(f.Writer, "| %s |\n", strings.Join(escaped, " | "))
+}
+
+var mdEscaper = strings.NewReplacer("|", `\|`, "\n", " ", "<", "&lt;", ">", "&gt;")
+
+// mdEscape keeps cell text from breaking table or HTML structure
+func mdEscape(s string) string {
+	return mdEscaper.Replace(s)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func hasCurrent(result *CheckResult) bool {
+	for _, c := range result.Components {
+		if c.Current != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// summaryText describes a check summary in one line
+func summaryText(s Summary) string {
+	parts := []string{fmt.Sprintf("%d components checked", s.TotalComponents)}
+	if s.IncompatibleCount > 0 {
+		parts = append(parts, fmt.Sprintf("%d incompatible", s.IncompatibleCount))
+	}
+	if s.UnknownCount > 0 {
+		parts = append(parts, fmt.Sprintf("%d unknown", s.UnknownCount))
+	}
+	if s.WaivedCount > 0 {
+		parts = append(parts, fmt.Sprintf("%d waived", s.WaivedCount))
+	}
+	if s.CompatibleComponents == s.TotalComponents {
+		parts = append(parts, "all components compatible")
+	}
+	return strings.Join(parts, ", ")
+}
@@ -12,18 +12,36 @@ import (
 
 // CheckResult represents the result of a compatibility check
 type CheckResult struct {
+	Cluster    string            `json:"cluster,omitempty" yaml:"cluster,omitempty"`
+	Source     string            `json:"source,omitempty" yaml:"source,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
 	K8sVersion string            `json:"k8sVersion" yaml:"k8sVersion"`
 	Components []ComponentResult `json:"components" yaml:"components"`
+	Findings   []Finding         `json:"findings,omitempty" yaml:"findings,omitempty"`
+	Warnings   []string          `json:"warnings,omitempty" yaml:"warnings,omitempty"`
 	Summary    Summary           `json:"summary" yaml:"summary"`
 }
 
+// Finding is a violation of a policy rule, a node prerequisite or a
+// problem with a feature gate, flag or component configuration
+type Finding struct {
+	Rule      string `json:"rule" yaml:"rule"`
+	Severity  string `json:"severity" yaml:"severity"` // error, warning, info
+	Component string `json:"component,omitempty" yaml:"component,omitempty"`
+	// Subject is the feature gate, flag or field the finding is about
+	Subject string `json:"subject,omitempty" yaml:"subject,omitempty"`
+	// Source is the file the subject was read from
+	Source  string `json:"source,omitempty" yaml:"source,omitempty"`
+	Message string `json:"message" yaml:"message"`
+}
+
 // ComponentResult represents the check result for a single component
 type ComponentResult struct {
 	Name        string `json:"name" yaml:"name"`
 	Current     string `json:"current,omitempty" yaml:"current,omitempty"`
 	Required    string `json:"required" yaml:"required"`
 	Recommended string `json:"recommended,omitempty" yaml:"recommended,omitempty"`
-	Status      string `json:"status" yaml:"status"` // compatible, incompatible, unknown
+	Status      string `json:"status" yaml:"status"` // compatible, incompatible, unknown, waived
 	SkewPolicy  string `json:"skewPolicy,omitempty" yaml:"skewPolicy,omitempty"`
 	Notes       string `json:"notes,omitempty" yaml:"notes,omitempty"`
 }
@@ -34,16 +52,99 @@ type Summary struct {
 	CompatibleComponents int `json:"compatibleComponents" yaml:"compatibleComponents"`
 	IncompatibleCount    int `json:"incompatibleCount" yaml:"incompatibleCount"`
 	UnknownCount         int `json:"unknownCount" yaml:"unknownCount"`
+	WaivedCount          int `json:"waivedCount" yaml:"waivedCount"`
+}
+
+// FleetResult represents the check results of many clusters
+type FleetResult struct {
+	Clusters []CheckResult    `json:"clusters" yaml:"clusters"`
+	Failures []ClusterFailure `json:"failures,omitempty" yaml:"failures,omitempty"`
+	Summary  FleetSummary     `json:"summary" yaml:"summary"`
+}
+
+// ClusterFailure records a cluster that could not be checked
+type ClusterFailure struct {
+	Cluster string `json:"cluster" yaml:"cluster"`
+	Error   string `json:"error" yaml:"error"`
+}
+
+// FleetSummary provides an overview of a fleet check
+type FleetSummary struct {
+	TotalClusters        int `json:"totalClusters" yaml:"totalClusters"`
+	CompatibleClusters   int `json:"compatibleClusters" yaml:"compatibleClusters"`
+	IncompatibleClusters int `json:"incompatibleClusters" yaml:"incompatibleClusters"`
+	UnreachableClusters  int `json:"unreachableClusters" yaml:"unreachableClusters"`
+}
+
+// UpgradeResult represents an upgrade plan between two Kubernetes versions
+type UpgradeResult struct {
+	From       string            `json:"from" yaml:"from"`
+	To         string            `json:"to" yaml:"to"`
+	Steps      []UpgradeStep     `json:"steps" yaml:"steps"`
+	Components []ComponentChange `json:"components" yaml:"components"`
+	Warnings   []string          `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+}
+
+// UpgradeStep represents a single minor version upgrade
+type UpgradeStep struct {
+	Step int    `json:"step" yaml:"step"`
+	From string `json:"from" yaml:"from"`
+	To   string `json:"to" yaml:"to"`
+}
+
+// ComponentChange represents a component version change across an upgrade
+type ComponentChange struct {
+	Name    string `json:"name" yaml:"name"`
+	From    string `json:"from" yaml:"from"`
+	To      string `json:"to" yaml:"to"`
+	Changed bool   `json:"changed" yaml:"changed"`
+}
+
+// VersionsResult represents the output of a versions query. When Component
+// is empty it lists the supported Kubernetes versions, and the archived ones
+// apart from them; when K8sVersion is set it describes a single release,
+// otherwise it spans all releases.
+type VersionsResult struct {
+	Component        string         `json:"component,omitempty" yaml:"component,omitempty"`
+	K8sVersion       string         `json:"k8sVersion,omitempty" yaml:"k8sVersion,omitempty"`
+	K8sVersions      []string       `json:"k8sVersions,omitempty" yaml:"k8sVersions,omitempty"`
+	ArchivedVersions []string       `json:"archivedVersions,omitempty" yaml:"archivedVersions,omitempty"`
+	Entries          []VersionEntry `json:"entries,omitempty" yaml:"entries,omitempty"`
+	Warnings         []string       `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+}
+
+// VersionEntry holds a component's version data for one Kubernetes release
+type VersionEntry struct {
+	K8sVersion  string `json:"k8sVersion" yaml:"k8sVersion"`
+	Name        string `json:"name" yaml:"name"`
+	Version     string `json:"version,omitempty" yaml:"version,omitempty"`
+	Recommended string `json:"recommended,omitempty" yaml:"recommended,omitempty"`
+	MinVersion  string `json:"minVersion,omitempty" yaml:"minVersion,omitempty"`
+	MaxVersion  string `json:"maxVersion,omitempty" yaml:"maxVersion,omitempty"`
+	SkewPolicy  string `json:"skewPolicy,omitempty" yaml:"skewPolicy,omitempty"`
+	Notes       string `json:"notes,omitempty" yaml:"notes,omitempty"`
 }
 
 // Formatter interface for different output formats
 type Formatter interface {
 	Format(result *CheckResult) error
+	FormatFleet(result *FleetResult) error
+	FormatUpgrade(result *UpgradeResult) error
+	FormatVersions(result *VersionsResult) error
 }
 
 // TableFormatter outputs results as a table
 type TableFormatter struct {
 	Writer io.Writer
+
+	// Color enables ANSI colours for statuses
+	Color bool
+	// Width is the terminal width tables are wrapped to; 0 disables wrapping
+	Width int
+	// NoEmoji replaces emoji markers with plain text
+	NoEmoji bool
+	// Wide shows additional columns and never wraps cells
+	Wide bool
 }
 
 // JSONFormatter outputs results as JSON
@@ -56,81 +157,415 @@ type YAMLFormatter struct {
 	Writer io.Writer
 }
 
+// Formats lists the supported output formats
+var Formats = []string{"table", "json", "yaml", "markdown", "html", "sarif", "junit"}
+
+// TemplateFormats lists the formats that take a template argument
+var TemplateFormats = []string{"go-template=...", "go-template-file=...", "jsonpath=..."}
+
 // NewFormatter creates a new formatter based on the format string
-func NewFormatter(format string) Formatter {
+func NewFormatter(format string) (Formatter, error) {
+	if name, arg, ok := strings.Cut(format, "="); ok {
+		return newTemplatedFormatter(name, arg)
+	}
+
 	switch strings.ToLower(format) {
+	case "table", "":
+		return NewTableFormatter(os.Stdout), nil
 	case "json":
-		return &JSONFormatter{Writer: os.Stdout}
+		return &JSONFormatter{Writer: os.Stdout}, nil
 	case "yaml":
-		return &YAMLFormatter{Writer: os.Stdout}
+		return &YAMLFormatter{Writer: os.Stdout}, nil
+	case "markdown", "md":
+		return &MarkdownFormatter{Writer: os.Stdout}, nil
+	case "html":
+		return &HTMLFormatter{Writer: os.Stdout}, nil
+	case "sarif":
+		return &SARIFFormatter{Writer: os.Stdout}, nil
+	case "junit":
+		return &JUnitFormatter{Writer: os.Stdout}, nil
 	default:
-		return &TableFormatter{Writer: os.Stdout}
+		return nil, unknownFormatError(format)
 	}
 }
 
+func unknownFormatError(format string) error {
+	supported := append(append([]string{}, Formats...), TemplateFormats...)
+	return fmt.Errorf("unknown output format %q (supported: %s)", format, strings.Join(supported, ", "))
+}
+
+// SkewPolicyReminders are shown with every upgrade plan
+var SkewPolicyReminders = []string{
+	"Upgrade kube-apiserver first",
+	"Then upgrade kube-controller-manager, kube-scheduler",
+	"Finally upgrade kubelet on all nodes",
+	"kubelet can be up to 3 minor versions older than kube-apiserver",
+}
+
 // Format outputs the result as a table
 func (f *TableFormatter) Format(result *CheckResult) error {
 	// Header
 	_, _ = fmt.Fprintf(f.Writer, "\n")
+	if result.Cluster != "" {
+		_, _ = fmt.Fprintf(f.Writer, "Cluster: %s\n", result.Cluster)
+	}
 	_, _ = fmt.Fprintf(f.Writer, "Kubernetes Version: %s\n", result.K8sVersion)
 	_, _ = fmt.Fprintf(f.Writer, "%s\n\n", strings.Repeat("=", 60))
+	f.warnings(result.Warnings)
 
-	// Table header
-	_, _ = fmt.Fprintf(f.Writer, "%-28s %-15s %-15s %-12s\n", "COMPONENT", "REQUIRED", "RECOMMENDED", "STATUS")
-	_, _ = fmt.Fprintf(f.Writer, "%s\n", strings.Repeat("-", 70))
+	// Show observed versions only when the result has any
+	showCurrent := hasCurrent(result)
+
+	headers := []string{"COMPONENT"}
+	if showCurrent {
+		headers = append(headers, "CURRENT")
+	}
+	headers = append(headers, "REQUIRED", "RECOMMENDED", "STATUS")
+	if f.Wide {
+		headers = append(headers, "NOTES")
+	}
+	t := newTable(headers...)
+	t.rule = 70
+	if !f.Wide {
+		// The skew policy text is long; wrap it rather than overflow
+		t.wrap = len(headers) - 3
+	}
 
 	// Component rows
 	for _, c := range result.Components {
-		status := formatStatus(c.Status)
 		required := c.Required
 		if required == "" {
 			required = c.SkewPolicy
 		}
-		recommended := c.Recommended
-		if recommended == "" {
-			recommended = "-"
+		row := plain(c.Name)
+		if showCurrent {
+			row = append(row, tableCell{text: orDash(c.Current)})
+		}
+		row = append(row, plain(required, orDash(c.Recommended))...)
+		row = append(row, f.status(c.Status))
+		if f.Wide {
+			row = append(row, tableCell{text: c.Notes})
+		}
+		t.addRow(row...)
+	}
+	width := t.render(f.Writer, f.Color, f.Width)
+
+	if len(result.Findings) > 0 {
+		_, _ = fmt.Fprintf(f.Writer, "\nFindings:\n")
+		ft := newTable("SEVERITY", "RULE", "COMPONENT", "FINDING")
+		ft.rule = width
+		if !f.Wide {
+			ft.wrap = 3
+		}
+		for _, finding := range result.Findings {
+			ft.addRow(
+				tableCell{text: finding.Severity, color: severityColor(finding.Severity)},
+				tableCell{text: finding.Rule},
+				tableCell{text: orDash(finding.Component)},
+				tableCell{text: finding.Message},
+			)
 		}
-		_, _ = fmt.Fprintf(f.Writer, "%-28s %-15s %-15s %s\n", c.Name, required, recommended, status)
+		ft.render(f.Writer, f.Color, f.Width)
 	}
 
 	// Summary
-	_, _ = fmt.Fprintf(f.Writer, "\n%s\n", strings.Repeat("-", 70))
+	_, _ = fmt.Fprintf(f.Writer, "\n%s\n", strings.Repeat("-", width))
 	_, _ = fmt.Fprintf(f.Writer, "Summary: %d components checked\n", result.Summary.TotalComponents)
 	if result.Summary.IncompatibleCount > 0 {
-		_, _ = fmt.Fprintf(f.Writer, "  ⚠️  %d incompatible\n", result.Summary.IncompatibleCount)
+		f.summaryLine(colorRed, f.icon("⚠️ ", "!"), "%d incompatible", result.Summary.IncompatibleCount)
 	}
 	if result.Summary.UnknownCount > 0 {
-		_, _ = fmt.Fprintf(f.Writer, "  ❓ %d unknown\n", result.Summary.UnknownCount)
+		f.summaryLine(colorYellow, f.icon("❓", "?"), "%d unknown", result.Summary.UnknownCount)
+	}
+	if result.Summary.WaivedCount > 0 {
+		f.summaryLine(colorCyan, f.icon("⏳", "~"), "%d waived", result.Summary.WaivedCount)
+	}
+	if len(result.Findings) > 0 {
+		f.summaryLine(colorYellow, f.icon("🚩", "!"), "%d findings", len(result.Findings))
 	}
 	if result.Summary.CompatibleComponents == result.Summary.TotalComponents {
-		_, _ = fmt.Fprintf(f.Writer, "  ✅ All components compatible\n")
+		f.summaryLine(colorGreen, f.icon("✅", "+"), "All components compatible")
+	}
+	_, _ = fmt.Fprintf(f.Writer, "\n")
+
+	return nil
+}
+
+// FormatFleet outputs each cluster's result as a table followed by a fleet summary
+func (f *TableFormatter) FormatFleet(result *FleetResult) error {
+	for i := range result.Clusters {
+		if err := f.Format(&result.Clusters[i]); err != nil {
+			return err
+		}
+	}
+
+	_, _ = fmt.Fprintf(f.Writer, "%s\n", strings.Repeat("=", 70))
+	_, _ = fmt.Fprintf(f.Writer, "Fleet Summary: %d clusters checked\n", result.Summary.TotalClusters)
+	if result.Summary.CompatibleClusters > 0 {
+		f.summaryLine(colorGreen, f.icon("✅", "+"), "%d compatible", result.Summary.CompatibleClusters)
+	}
+	if result.Summary.IncompatibleClusters > 0 {
+		f.summaryLine(colorRed, f.icon("⚠️ ", "!"), "%d with incompatible components", result.Summary.IncompatibleClusters)
+	}
+	if result.Summary.UnreachableClusters > 0 {
+		f.summaryLine(colorRed, f.icon("❌", "x"), "%d could not be checked", result.Summary.UnreachableClusters)
+		for _, failure := range result.Failures {
+			_, _ = fmt.Fprintf(f.Writer, "     - %s: %s\n", failure.Cluster, failure.Error)
+		}
 	}
 	_, _ = fmt.Fprintf(f.Writer, "\n")
 
 	return nil
 }
 
+func severityColor(severity string) string {
+	switch severity {
+	case "error":
+		return colorRed
+	case "warning":
+		return colorYellow
+	default:
+		return colorCyan
+	}
+}
+
+// icon returns the emoji, or its plain replacement when emoji are disabled
+func (f *TableFormatter) icon(emoji, plain string) string {
+	if f.NoEmoji {
+		return plain
+	}
+	return emoji
+}
+
+// heading returns a section heading prefixed with an emoji when enabled
+func (f *TableFormatter) heading(emoji, text string) string {
+	if f.NoEmoji {
+		return text
+	}
+	return emoji + " " + text
+}
+
+// summaryLine writes an indented summary line with a coloured marker
+func (f *TableFormatter) summaryLine(color, icon, format string, args ...interface{}) {
+	if f.Color {
+		icon = paint(color, icon)
+	}
+	_, _ = fmt.Fprintf(f.Writer, "  %s %s\n", icon, fmt.Sprintf(format, args...))
+}
+
+// warnings writes out-of-support and similar warnings, followed by a blank
+// line when there are any
+func (f *TableFormatter) warnings(warnings []string) {
+	for _, w := range warnings {
+		f.summaryLine(colorYellow, f.icon("⚠️ ", "!"), "%s", w)
+	}
+	if len(warnings) > 0 {
+		_, _ = fmt.Fprintln(f.Writer)
+	}
+}
+
+// status returns the coloured status cell of a component
+func (f *TableFormatter) status(status string) tableCell {
+	text := formatStatus(status)
+	if f.NoEmoji {
+		_, text, _ = strings.Cut(text, " ")
+	}
+	switch status {
+	case "compatible":
+		return tableCell{text: text, color: colorGreen}
+	case "incompatible":
+		return tableCell{text: text, color: colorRed}
+	case "waived":
+		return tableCell{text: text, color: colorCyan}
+	default:
+		return tableCell{text: text, color: colorYellow}
+	}
+}
+
 func formatStatus(status string) string {
 	switch status {
 	case "compatible":
 		return "✅ Compatible"
 	case "incompatible":
 		return "❌ Incompatible"
+	case "waived":
+		return "⏳ Waived"
 	default:
 		return "❓ Unknown"
 	}
 }
 
+// FormatUpgrade outputs the upgrade plan as a table
+func (f *TableFormatter) FormatUpgrade(result *UpgradeResult) error {
+	_, _ = fmt.Fprintf(f.Writer, "\n")
+	_, _ = fmt.Fprintf(f.Writer, "Upgrade Path: %s → %s\n", result.From, result.To)
+	_, _ = fmt.Fprintf(f.Writer, "%s\n\n", strings.Repeat("=", 60))
+	f.warnings(result.Warnings)
+
+	// Step-by-step upgrade path
+	_, _ = fmt.Fprintln(f.Writer, f.heading("📋", "Recommended Upgrade Steps:"))
+	_, _ = fmt.Fprintln(f.Writer, strings.Repeat("-", 60))
+	for _, s := range result.Steps {
+		_, _ = fmt.Fprintf(f.Writer, "  Step %d: %s → %s\n", s.Step, s.From, s.To)
+	}
+	_, _ = fmt.Fprintln(f.Writer)
+	_, _ = fmt.Fprintln(f.Writer, f.heading("⚠️ ", "Note: Kubernetes supports upgrading one minor version at a time."))
+	_, _ = fmt.Fprintln(f.Writer)
+
+	// Component changes
+	_, _ = fmt.Fprintln(f.Writer, f.heading("📦", "Component Version Changes:"))
+	_, _ = fmt.Fprintln(f.Writer, strings.Repeat("-", 60))
+	t := newTable("COMPONENT", result.From, result.To, "")
+	t.rule = 60
+	for _, c := range result.Components {
+		change := tableCell{}
+		if c.Changed {
+			change = tableCell{text: f.icon("⬆️", "changed"), color: colorYellow}
+		}
+		t.addRow(tableCell{text: c.Name}, tableCell{text: c.From}, tableCell{text: c.To}, change)
+	}
+	t.render(f.Writer, f.Color, f.Width)
+	_, _ = fmt.Fprintln(f.Writer)
+
+	// Skew policy reminders
+	_, _ = fmt.Fprintln(f.Writer, f.heading("📌", "Version Skew Policy Reminders:"))
+	_, _ = fmt.Fprintln(f.Writer, strings.Repeat("-", 60))
+	for _, r := range SkewPolicyReminders {
+		_, _ = fmt.Fprintf(f.Writer, "  • %s\n", r)
+	}
+	_, _ = fmt.Fprintln(f.Writer)
+
+	return nil
+}
+
+// FormatVersions outputs the versions query result as a table
+func (f *TableFormatter) FormatVersions(result *VersionsResult) error {
+	// Supported Kubernetes versions
+	if result.Component == "" {
+		_, _ = fmt.Fprintln(f.Writer, "Supported Kubernetes versions:")
+		for _, v := range result.K8sVersions {
+			_, _ = fmt.Fprintf(f.Writer, "  - %s\n", v)
+		}
+		if len(result.ArchivedVersions) > 0 {
+			_, _ = fmt.Fprintln(f.Writer, "\nArchived (out of support):")
+			for _, v := range result.ArchivedVersions {
+				_, _ = fmt.Fprintf(f.Writer, "  - %s\n", v)
+			}
+		}
+		if len(result.Warnings) > 0 {
+			_, _ = fmt.Fprintln(f.Writer)
+			f.warnings(result.Warnings)
+		}
+		return nil
+	}
+
+	// Single Kubernetes release
+	if result.K8sVersion != "" {
+		if len(result.Warnings) > 0 {
+			_, _ = fmt.Fprintln(f.Writer)
+			f.warnings(result.Warnings)
+		}
+		for _, e := range result.Entries {
+			_, _ = fmt.Fprintf(f.Writer, "\n%s compatibility for Kubernetes %s:\n", e.Name, e.K8sVersion)
+			_, _ = fmt.Fprintln(f.Writer, strings.Repeat("-", 50))
+			if e.Version != "" {
+				_, _ = fmt.Fprintf(f.Writer, "  Version:     %s\n", e.Version)
+			}
+			if e.Recommended != "" {
+				_, _ = fmt.Fprintf(f.Writer, "  Recommended: %s\n", e.Recommended)
+			}
+			if e.MinVersion != "" {
+				_, _ = fmt.Fprintf(f.Writer, "  Min Version: %s\n", e.MinVersion)
+			}
+			if e.MaxVersion != "" {
+				_, _ = fmt.Fprintf(f.Writer, "  Max Version: %s\n", e.MaxVersion)
+			}
+			if e.SkewPolicy != "" {
+				_, _ = fmt.Fprintf(f.Writer, "  Skew Policy: %s\n", e.SkewPolicy)
+			}
+			if e.Notes != "" {
+				_, _ = fmt.Fprintf(f.Writer, "  Notes:       %s\n", e.Notes)
+			}
+			_, _ = fmt.Fprintln(f.Writer)
+		}
+		return nil
+	}
+
+	// All Kubernetes releases
+	_, _ = fmt.Fprintf(f.Writer, "\n%s versions across Kubernetes releases:\n", strings.ToUpper(result.Component[:1])+result.Component[1:])
+	_, _ = fmt.Fprintln(f.Writer, strings.Repeat("-", 60))
+	headers := []string{"K8S VERSION", "VERSION", "RECOMMENDED"}
+	if f.Wide {
+		headers = append(headers, "MIN VERSION", "MAX VERSION", "NOTES")
+	}
+	t := newTable(headers...)
+	t.rule = 60
+	for _, e := range result.Entries {
+		version := e.Version
+		if version == "" && e.SkewPolicy != "" {
+			version = "(skew policy)"
+		}
+		row := plain(e.K8sVersion, version, orDash(e.Recommended))
+		if f.Wide {
+			row = append(row, plain(orDash(e.MinVersion), orDash(e.MaxVersion), e.Notes)...)
+		}
+		t.addRow(row...)
+	}
+	t.render(f.Writer, f.Color, f.Width)
+	_, _ = fmt.Fprintln(f.Writer)
+	f.warnings(result.Warnings)
+
+	return nil
+}
+
 // Format outputs the result as JSON
 func (f *JSONFormatter) Format(result *CheckResult) error {
+	return f.encode(result)
+}
+
+// FormatFleet outputs the fleet result as JSON
+func (f *JSONFormatter) FormatFleet(result *FleetResult) error {
+	return f.encode(result)
+}
+
+// FormatUpgrade outputs the upgrade plan as JSON
+func (f *JSONFormatter) FormatUpgrade(result *UpgradeResult) error {
+	return f.encode(result)
+}
+
+// FormatVersions outputs the versions query result as JSON
+func (f *JSONFormatter) FormatVersions(result *VersionsResult) error {
+	return f.encode(result)
+}
+
+func (f *JSONFormatter) encode(v interface{}) error {
 	encoder := json.NewEncoder(f.Writer)
 	encoder.SetIndent("", "  ")
-	return encoder.Encode(result)
+	return encoder.Encode(v)
 }
 
 // Format outputs the result as YAML
 func (f *YAMLFormatter) Format(result *CheckResult) error {
+	return f.encode(result)
+}
+
+// FormatFleet outputs the fleet result as YAML
+func (f *YAMLFormatter) FormatFleet(result *FleetResult) error {
+	return f.encode(result)
+}
+
+// FormatUpgrade outputs the upgrade plan as YAML
+func (f *YAMLFormatter) FormatUpgrade(result *UpgradeResult) error {
+	return f.encode(result)
+}
+
+// FormatVersions outputs the versions query result as YAML
+func (f *YAMLFormatter) FormatVersions(result *VersionsResult) error {
+	return f.encode(result)
+}
+
+func (f *YAMLFormatter) encode(v interface{}) error {
 	encoder := yaml.NewEncoder(f.Writer)
 	encoder.SetIndent(2)
-	return encoder.Encode(result)
+	return encoder.Encode(v)
 }
@@ -0,0 +1,519 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func testCheckResult() *CheckResult {
+	return &CheckResult{
+		Cluster:    "prod",
+		K8sVersion: "1.30",
+		Components: []ComponentResult{
+			{Name: "etcd", Current: "3.5.12", Required: "3.5.x", Recommended: "3.5.12", Status: "compatible"},
+			{Name: "kubelet", Current: "1.26.0", SkewPolicy: "Up to 3 minor versions | older", Status: "incompatible"},
+		},
+		Summary: Summary{TotalComponents: 2, CompatibleComponents: 1, IncompatibleCount: 1},
+	}
+}
+
+func TestNewFormatter(t *testing.T) {
+	for _, format := range append(Formats, "JSON", "md", "") {
+		if _, err := NewFormatter(format); err != nil {
+			t.Errorf("NewFormatter(%q) error = %v", format, err)
+		}
+	}
+	if _, err := NewFormatter("xml"); err == nil {
+		t.Error("NewFormatter(xml) expected error for unknown format")
+	}
+	for _, format := range []string{"go-template={{.k8sVersion}}", "jsonpath={.k8sVersion}"} {
+		if _, err := NewFormatter(format); err != nil {
+			t.Errorf("NewFormatter(%q) error = %v", format, err)
+		}
+	}
+	for _, format := range []string{"jsonpath=", "go-template={{.k8sVersion", "go-template-file=missing.tmpl", "xml=foo"} {
+		if _, err := NewFormatter(format); err == nil {
+			t.Errorf("NewFormatter(%q) expected error", format)
+		}
+	}
+}
+
+func TestTemplateFormatters(t *testing.T) {
+	tests := []struct {
+		format string
+		run    func(Formatter) error
+		want   string
+	}{
+		{
+			format: `go-template={{range .components}}{{.name}}={{.status}} {{end}}`,
+			run:    func(f Formatter) error { return f.Format(testCheckResult()) },
+			want:   "etcd=compatible kubelet=incompatible ",
+		},
+		{
+			format: `jsonpath={.components[?(@.status=="incompatible")].name}`,
+			run:    func(f Formatter) error { return f.Format(testCheckResult()) },
+			want:   "kubelet",
+		},
+		{
+			format: `jsonpath={.clusters[*].cluster}`,
+			run: func(f Formatter) error {
+				return f.FormatFleet(&FleetResult{Clusters: []CheckResult{*testCheckResult()}})
+			},
+			want: "prod",
+		},
+		{
+			format: `go-template={{.from}}->{{.to}}`,
+			run:    func(f Formatter) error { return f.FormatUpgrade(&UpgradeResult{From: "1.29", To: "1.30"}) },
+			want:   "1.29->1.30",
+		},
+		{
+			format: `jsonpath={.k8sVersions[0]}`,
+			run: func(f Formatter) error {
+				return f.FormatVersions(&VersionsResult{K8sVersions: []string{"1.30", "1.29"}})
+			},
+			want: "1.30",
+		},
+	}
+
+	for _, tt := range tests {
+		formatter, err := NewFormatter(tt.format)
+		if err != nil {
+			t.Fatalf("NewFormatter(%q) error = %v", tt.format, err)
+		}
+		var buf bytes.Buffer
+		setWriter(formatter, &buf)
+		if err := tt.run(formatter); err != nil {
+			t.Errorf("%s error = %v", tt.format, err)
+			continue
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%s output = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestTemplateFormatterMissingKey(t *testing.T) {
+	formatter, err := NewFormatter("go-template={{.nosuchfield}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	setWriter(formatter, &buf)
+	if err := formatter.Format(testCheckResult()); err == nil {
+		t.Error("Format() expected error for a missing field")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Format() wrote partial output %q", buf.String())
+	}
+}
+
+func TestMarkdownFormatter(t *testing.T) {
+	var buf bytes.Buffer
+	f := &MarkdownFormatter{Writer: &buf}
+	if err := f.Format(testCheckResult()); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	got := buf.String()
+
+	for _, want := range []string{
+		"## prod: Kubernetes 1.30 compatibility",
+		"| Component | Current | Required | Recommended | Status |",
+		"| etcd | 3.5.12 | 3.5.x | 3.5.12 | ✅ Compatible |",
+		`| kubelet | 1.26.0 | Up to 3 minor versions \| older | - | ❌ Incompatible |`,
+		"**Summary:** 2 components checked, 1 incompatible",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("markdown output missing %q\ngot:\n%s", want, got)
+		}
+	}
+}
+
+func TestHTMLFormatterEscapes(t *testing.T) {
+	result := testCheckResult()
+	result.Cluster = "<script>alert(1)</script>"
+
+	var buf bytes.Buffer
+	f := &HTMLFormatter{Writer: &buf}
+	if err := f.Format(result); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	got := buf.String()
+
+	if strings.Contains(got, "<script>") {
+		t.Error("HTML output contains unescaped cluster name")
+	}
+	for _, want := range []string{"<!DOCTYPE html>", `class="status-incompatible"`, "<th>Current</th>"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("HTML output missing %q", want)
+		}
+	}
+}
+
+func TestFormattersHandleAllResultTypes(t *testing.T) {
+	fleet := &FleetResult{
+		Clusters: []CheckResult{*testCheckResult()},
+		Failures: []ClusterFailure{{Cluster: "down", Error: "timed out"}},
+		Summary:  FleetSummary{TotalClusters: 2, IncompatibleClusters: 1, UnreachableClusters: 1},
+	}
+	upgrade := &UpgradeResult{
+		From:       "1.29",
+		To:         "1.30",
+		Steps:      []UpgradeStep{{Step: 1, From: "1.29", To: "1.30"}},
+		Components: []ComponentChange{{Name: "etcd", From: "3.5.10", To: "3.5.12", Changed: true}},
+	}
+	versions := []*VersionsResult{
+		{K8sVersions: []string{"1.30", "1.29"}, ArchivedVersions: []string{"1.27"}},
+		{Component: "etcd", K8sVersion: "1.30", Entries: []VersionEntry{{K8sVersion: "1.30", Name: "etcd", Version: "3.5.x"}}},
+		{Component: "etcd", Entries: []VersionEntry{{K8sVersion: "1.30", Name: "etcd", Version: "3.5.x"}}},
+	}
+
+	for _, format := range []string{"table", "json", "yaml", "markdown", "html"} {
+		var buf bytes.Buffer
+		formatter, _ := NewFormatter(format)
+		setWriter(formatter, &buf)
+
+		if err := formatter.FormatFleet(fleet); err != nil {
+			t.Errorf("%s FormatFleet() error = %v", format, err)
+		}
+		if err := formatter.FormatUpgrade(upgrade); err != nil {
+			t.Errorf("%s FormatUpgrade() error = %v", format, err)
+		}
+		for _, v := range versions {
+			if err := formatter.FormatVersions(v); err != nil {
+				t.Errorf("%s FormatVersions() error = %v", format, err)
+			}
+		}
+		if !strings.Contains(buf.String(), "timed out") {
+			t.Errorf("%s output does not report the fleet failure", format)
+		}
+	}
+}
+
+func TestTableFormatterWrapsToWidth(t *testing.T) {
+	result := testCheckResult()
+	result.Components[1].SkewPolicy = "Up to 3 minor versions older than kube-apiserver"
+
+	var buf bytes.Buffer
+	f := &TableFormatter{Writer: &buf, Width: 80}
+	if err := f.Format(result); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if w := displayWidth(line); w > 80 {
+			t.Errorf("line is %d cells wide, want at most 80: %q", w, line)
+		}
+	}
+	if !strings.Contains(buf.String(), "kube-apiserver") {
+		t.Error("wrapped skew policy lost its text")
+	}
+
+	buf.Reset()
+	f.Wide = true
+	if err := f.Format(result); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "Up to 3 minor versions older than kube-apiserver") || !strings.Contains(buf.String(), "NOTES") {
+		t.Errorf("wide output should keep the skew policy on one line and show notes:\n%s", buf.String())
+	}
+}
+
+func TestTableFormatterColorAndEmoji(t *testing.T) {
+	var buf bytes.Buffer
+	f := &TableFormatter{Writer: &buf, NoEmoji: true}
+	if err := f.Format(testCheckResult()); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	got := buf.String()
+	if strings.ContainsAny(got, "✅❌❓⚠") {
+		t.Errorf("--no-emoji output contains emoji:\n%s", got)
+	}
+	if strings.Contains(got, "\x1b[") {
+		t.Error("output contains colour codes although colour is disabled")
+	}
+
+	buf.Reset()
+	f.Color = true
+	if err := f.Format(testCheckResult()); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), paint(colorRed, "Incompatible")) {
+		t.Errorf("incompatible status is not coloured red:\n%q", buf.String())
+	}
+}
+
+func TestColorEnabledHonoursNoColor(t *testing.T) {
+	t.Setenv("TERM", "xterm")
+	t.Setenv("NO_COLOR", "")
+	if !colorEnabled() {
+		t.Error("colorEnabled() = false without NO_COLOR")
+	}
+	t.Setenv("NO_COLOR", "1")
+	if colorEnabled() {
+		t.Error("colorEnabled() = true with NO_COLOR set")
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		want  []string
+	}{
+		{"short", 10, []string{"short"}},
+		{"Up to 3 minor versions older", 12, []string{"Up to 3", "minor", "versions", "older"}},
+		{"kube-controller-manager", 10, []string{"kube-contr", "oller-mana", "ger"}},
+	}
+	for _, tt := range tests {
+		got := wrapText(tt.text, tt.width)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("wrapText(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+		}
+	}
+	if w := displayWidth("✅ ok"); w != 5 {
+		t.Errorf("displayWidth(✅ ok) = %d, want 5", w)
+	}
+}
+
+func TestFindingsInReports(t *testing.T) {
+	result := testCheckResult()
+	result.Findings = []Finding{
+		{Rule: "etcd-recommended-patch", Severity: "warning", Component: "etcd", Message: "etcd is older than recommended"},
+	}
+
+	var buf bytes.Buffer
+	if err := (&MarkdownFormatter{Writer: &buf}).Format(result); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "| warning | etcd-recommended-patch | etcd | etcd is older than recommended |") {
+		t.Errorf("markdown output missing finding:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := (&SARIFFormatter{Writer: &buf}).Format(result); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("SARIF output is not valid JSON: %v", err)
+	}
+	run := log.Runs[0]
+	last := run.Results[len(run.Results)-1]
+	if last.RuleID != PolicyRulePrefix+"etcd-recommended-patch" || last.Level != "warning" {
+		t.Errorf("SARIF finding = %s/%s", last.RuleID, last.Level)
+	}
+	if rule := run.Tool.Driver.Rules[len(run.Tool.Driver.Rules)-1]; rule.ID != last.RuleID {
+		t.Errorf("SARIF rules do not describe the policy rule, last rule = %s", rule.ID)
+	}
+}
+
+func TestCheckFindingsInReports(t *testing.T) {
+	result := testCheckResult()
+	result.Source = "cluster.yaml"
+	result.Findings = []Finding{
+		{Rule: "feature-gate", Severity: "error", Subject: "InTreePluginAWSUnregister", Message: "feature gate InTreePluginAWSUnregister is removed"},
+		{Rule: "feature-gate", Severity: "error", Subject: "NodeSwap", Message: "feature gate NodeSwap is locked"},
+		{Rule: "flag", Severity: "warning", Component: "kubelet", Subject: "--pod-infra-container-image", Source: "kubelet.env", Message: "flag is deprecated"},
+	}
+
+	var buf bytes.Buffer
+	if err := (&SARIFFormatter{Writer: &buf}).Format(result); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("SARIF output is not valid JSON: %v", err)
+	}
+	run := log.Runs[0]
+	gate, flag := run.Results[len(run.Results)-2], run.Results[len(run.Results)-1]
+	if gate.RuleID != RuleFeatureGate || flag.RuleID != RuleComponentFlag {
+		t.Errorf("SARIF rule IDs = %s, %s; want %s, %s", gate.RuleID, flag.RuleID, RuleFeatureGate, RuleComponentFlag)
+	}
+	if loc := flag.Locations[0].PhysicalLocation; loc == nil || loc.ArtifactLocation.URI != "kubelet.env" {
+		t.Errorf("SARIF flag location = %+v, want kubelet.env", loc)
+	}
+	for _, rule := range run.Tool.Driver.Rules {
+		if strings.HasPrefix(rule.ShortDescription.Text, "Policy rule") {
+			t.Errorf("SARIF rule %s described as a policy rule", rule.ID)
+		}
+	}
+
+	buf.Reset()
+	if err := (&JUnitFormatter{Writer: &buf}).Format(result); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	var doc junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("JUnit output is not valid XML: %v", err)
+	}
+	names := make(map[string]bool)
+	for _, tc := range doc.Suites[0].TestCases {
+		names[tc.Name] = true
+	}
+	for _, want := range []string{
+		"feature-gate InTreePluginAWSUnregister",
+		"feature-gate NodeSwap",
+		"flag --pod-infra-container-image (kubelet, kubelet.env)",
+	} {
+		if !names[want] {
+			t.Errorf("JUnit test cases %v, want %q", names, want)
+		}
+	}
+}
+
+func TestSARIFFindingWithoutCluster(t *testing.T) {
+	result := &CheckResult{
+		K8sVersion: "1.32",
+		Findings:   []Finding{{Rule: "node-cgroup", Severity: "info", Message: "cgroup version is unknown"}},
+	}
+	var buf bytes.Buffer
+	if err := (&SARIFFormatter{Writer: &buf}).Format(result); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("SARIF output is not valid JSON: %v", err)
+	}
+	if locations := log.Runs[0].Results[0].Locations; len(locations) != 0 {
+		t.Errorf("SARIF locations = %+v, want none without a cluster, component or file", locations)
+	}
+}
+
+func TestWarningsInReports(t *testing.T) {
+	warning := "Kubernetes 1.25 is out of support: it reached end of life on 2023-10-28 and its data is archived"
+	result := &UpgradeResult{From: "1.25", To: "1.26", Warnings: []string{warning}}
+
+	var buf bytes.Buffer
+	if err := (&TableFormatter{Writer: &buf, NoEmoji: true}).FormatUpgrade(result); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "  ! "+warning+"\n") {
+		t.Errorf("table output missing warning:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := (&MarkdownFormatter{Writer: &buf}).FormatUpgrade(result); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "> **Warning:** "+warning) {
+		t.Errorf("markdown output missing warning:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := (&HTMLFormatter{Writer: &buf}).FormatUpgrade(result); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `<p class="warning">`+warning+"</p>") {
+		t.Errorf("HTML output missing warning:\n%s", buf.String())
+	}
+}
+
+func TestSARIFFormatter(t *testing.T) {
+	result := testCheckResult()
+	result.Source = "clusters/prod.yaml"
+
+	var buf bytes.Buffer
+	f := &SARIFFormatter{Writer: &buf}
+	if err := f.Format(result); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("SARIF output is not valid JSON: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("SARIF log = version %s with %d runs", log.Version, len(log.Runs))
+	}
+	results := log.Runs[0].Results
+	if len(results) != 1 {
+		t.Fatalf("SARIF results = %d, want 1 for the incompatible kubelet", len(results))
+	}
+	r := results[0]
+	if r.RuleID != RuleIncompatibleComponent || r.Level != "error" {
+		t.Errorf("SARIF result = %s/%s, want %s/error", r.RuleID, r.Level, RuleIncompatibleComponent)
+	}
+	if loc := r.Locations[0].PhysicalLocation; loc == nil || loc.ArtifactLocation.URI != "clusters/prod.yaml" {
+		t.Errorf("SARIF result location = %+v, want clusters/prod.yaml", loc)
+	}
+
+	buf.Reset()
+	result.Components[1].Status = "waived"
+	result.Components[1].Notes = "Waived until 2026-11-30"
+	if err := f.Format(result); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("SARIF output is not valid JSON: %v", err)
+	}
+	if s := log.Runs[0].Results[0].Suppressions; len(s) != 1 || s[0].Justification != "Waived until 2026-11-30" {
+		t.Errorf("waived SARIF result suppressions = %+v, want the waiver as justification", s)
+	}
+
+	if err := f.FormatUpgrade(&UpgradeResult{}); err == nil {
+		t.Error("FormatUpgrade() expected error for SARIF output")
+	}
+}
+
+func TestJUnitFormatter(t *testing.T) {
+	var buf bytes.Buffer
+	f := &JUnitFormatter{Writer: &buf}
+	if err := f.Format(testCheckResult()); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	var doc junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("JUnit output is not valid XML: %v", err)
+	}
+	if doc.Tests != 2 || doc.Failures != 1 {
+		t.Errorf("JUnit totals = %d tests, %d failures; want 2, 1", doc.Tests, doc.Failures)
+	}
+	cases := doc.Suites[0].TestCases
+	if cases[0].Failure != nil || cases[1].Failure == nil {
+		t.Errorf("JUnit test cases = %+v, want only kubelet to fail", cases)
+	}
+}
+
+func setWriter(f Formatter, w *bytes.Buffer) {
+	switch f := f.(type) {
+	case *TableFormatter:
+		f.Writer = w
+	case *JSONFormatter:
+		f.Writer = w
+	case *YAMLFormatter:
+		f.Writer = w
+	case *MarkdownFormatter:
+		f.Writer = w
+	case *HTMLFormatter:
+		f.Writer = w
+	case *SARIFFormatter:
+		f.Writer = w
+	case *JUnitFormatter:
+		f.Writer = w
+	case *TemplateFormatter:
+		f.Writer = w
+	case *JSONPathFormatter:
+		f.Writer = w
+	}
+}
+
+func TestFormatArchivedVersions(t *testing.T) {
+	result := &VersionsResult{K8sVersions: []string{"1.30", "1.29"}, ArchivedVersions: []string{"1.27"}}
+	for _, format := range []string{"table", "markdown", "html"} {
+		var buf bytes.Buffer
+		formatter, _ := NewFormatter(format)
+		setWriter(formatter, &buf)
+		if err := formatter.FormatVersions(result); err != nil {
+			t.Fatalf("%s FormatVersions() error = %v", format, err)
+		}
+		out := buf.String()
+		heading := strings.Index(out, "Archived (out of support)")
+		if heading < 0 || strings.Index(out, "1.29") > heading || strings.LastIndex(out, "1.27") < heading {
+			t.Errorf("%s output does not list 1.27 under an archived heading:\n%s", format, out)
+		}
+	}
+}
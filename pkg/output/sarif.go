@@ -0,0 +1,371 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// SARIF rule IDs reported by the SARIF and JUnit formatters
+const (
+	RuleIncompatibleComponent = "KDC001"
+	RuleUnknownVersion        = "KDC002"
+	RuleClusterUnreachable    = "KDC003"
+	RuleFeatureGate           = "KDC004"
+	RuleComponentFlag         = "KDC005"
+	RuleComponentConfig       = "KDC006"
+	RuleNodeCgroup            = "KDC007"
+	RuleNodeKernel            = "KDC008"
+	RuleNodeSwap              = "KDC009"
+	RuleNodeSystemdCgroup     = "KDC010"
+
+	// PolicyRulePrefix prefixes the SARIF rule IDs of policy findings
+	PolicyRulePrefix = "policy/"
+)
+
+// SARIFFormatter outputs check findings as SARIF 2.1.0 for code scanning
+type SARIFFormatter struct {
+	Writer io.Writer
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string         `json:"id"`
+	Name                 string         `json:"name"`
+	ShortDescription     sarifMessage   `json:"shortDescription"`
+	DefaultConfiguration sarifRuleLevel `json:"defaultConfiguration"`
+}
+
+type sarifRuleLevel struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID       string             `json:"ruleId"`
+	Level        string             `json:"level"`
+	Message      sarifMessage       `json:"message"`
+	Locations    []sarifLocation    `json:"locations,omitempty"`
+	Suppressions []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName,omitempty"`
+	Kind               string `json:"kind"`
+}
+
+var sarifRules = []sarifRule{
+	{
+		ID:                   RuleIncompatibleComponent,
+		Name:                 "IncompatibleComponent",
+		ShortDescription:     sarifMessage{Text: "Component version is not compatible with the Kubernetes version"},
+		DefaultConfiguration: sarifRuleLevel{Level: "error"},
+	},
+	{
+		ID:                   RuleUnknownVersion,
+		Name:                 "UnknownComponentVersion",
+		ShortDescription:     sarifMessage{Text: "Component version could not be determined or compared"},
+		DefaultConfiguration: sarifRuleLevel{Level: "note"},
+	},
+	{
+		ID:                   RuleClusterUnreachable,
+		Name:                 "ClusterUnreachable",
+		ShortDescription:     sarifMessage{Text: "Cluster could not be checked"},
+		DefaultConfiguration: sarifRuleLevel{Level: "warning"},
+	},
+}
+
+// findingRules describes the findings of the built-in checks by their rule
+// in Finding.Rule; findings of any other rule come from a policy
+var findingRules = map[string]sarifRule{
+	"feature-gate": {
+		ID:               RuleFeatureGate,
+		Name:             "FeatureGate",
+		ShortDescription: sarifMessage{Text: "Feature gate setting is removed, locked or changing in the Kubernetes version"},
+	},
+	"flag": {
+		ID:               RuleComponentFlag,
+		Name:             "ComponentFlag",
+		ShortDescription: sarifMessage{Text: "Component flag is deprecated or removed in the Kubernetes version"},
+	},
+	"component-config": {
+		ID:               RuleComponentConfig,
+		Name:             "ComponentConfig",
+		ShortDescription: sarifMessage{Text: "Component configuration uses an API version or field the Kubernetes version does not support"},
+	},
+	"node-cgroup": {
+		ID:               RuleNodeCgroup,
+		Name:             "NodeCgroup",
+		ShortDescription: sarifMessage{Text: "Node cgroup version is not supported by the Kubernetes version"},
+	},
+	"node-kernel": {
+		ID:               RuleNodeKernel,
+		Name:             "NodeKernel",
+		ShortDescription: sarifMessage{Text: "Node kernel is older than the Kubernetes version needs"},
+	},
+	"node-swap": {
+		ID:               RuleNodeSwap,
+		Name:             "NodeSwap",
+		ShortDescription: sarifMessage{Text: "Node swap is not supported by the Kubernetes version"},
+	},
+	"node-systemd-cgroup": {
+		ID:               RuleNodeSystemdCgroup,
+		Name:             "NodeSystemdCgroup",
+		ShortDescription: sarifMessage{Text: "Container runtime does not use the systemd cgroup driver"},
+	},
+}
+
+// findingRuleID returns the SARIF rule ID of a finding
+func findingRuleID(finding Finding) string {
+	if rule, ok := findingRules[finding.Rule]; ok {
+		return rule.ID
+	}
+	return PolicyRulePrefix + finding.Rule
+}
+
+// Format outputs the check findings as SARIF
+func (f *SARIFFormatter) Format(result *CheckResult) error {
+	return f.write(checkSARIFResults(result))
+}
+
+// FormatFleet outputs the findings of every cluster as SARIF
+func (f *SARIFFormatter) FormatFleet(result *FleetResult) error {
+	results := make([]sarifResult, 0)
+	for i := range result.Clusters {
+		results = append(results, checkSARIFResults(&result.Clusters[i])...)
+	}
+	for _, failure := range result.Failures {
+		results = append(results, sarifResult{
+			RuleID:  RuleClusterUnreachable,
+			Level:   "warning",
+			Message: sarifMessage{Text: fmt.Sprintf("Cluster %s could not be checked: %s", failure.Cluster, failure.Error)},
+			Locations: []sarifLocation{{
+				LogicalLocations: []sarifLogicalLocation{{Name: failure.Cluster, Kind: "namespace"}},
+			}},
+		})
+	}
+	return f.write(results)
+}
+
+// FormatUpgrade is not supported for SARIF output
+func (f *SARIFFormatter) FormatUpgrade(result *UpgradeResult) error {
+	return fmt.Errorf("sarif output is only supported for check results")
+}
+
+// FormatVersions is not supported for SARIF output
+func (f *SARIFFormatter) FormatVersions(result *VersionsResult) error {
+	return fmt.Errorf("sarif output is only supported for check results")
+}
+
+func (f *SARIFFormatter) write(results []sarifResult) error {
+	// Describe the check and policy rules that produced findings
+	rules := append([]sarifRule{}, sarifRules...)
+	builtin := make(map[string]sarifRule, len(findingRules))
+	for _, rule := range findingRules {
+		builtin[rule.ID] = rule
+	}
+	seen := make(map[string]bool)
+	for _, r := range results {
+		if seen[r.RuleID] {
+			continue
+		}
+		if rule, ok := builtin[r.RuleID]; ok {
+			seen[r.RuleID] = true
+			rule.DefaultConfiguration = sarifRuleLevel{Level: r.Level}
+			rules = append(rules, rule)
+			continue
+		}
+		if !strings.HasPrefix(r.RuleID, PolicyRulePrefix) {
+			continue
+		}
+		seen[r.RuleID] = true
+		name := strings.TrimPrefix(r.RuleID, PolicyRulePrefix)
+		rules = append(rules, sarifRule{
+			ID:                   r.RuleID,
+			Name:                 name,
+			ShortDescription:     sarifMessage{Text: "Policy rule " + name},
+			DefaultConfiguration: sarifRuleLevel{Level: r.Level},
+		})
+	}
+
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "kube-dependency-checker",
+				InformationURI: "https://github.com/pmady/kube-dependency-checker",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+
+	encoder := json.NewEncoder(f.Writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
+
+func checkSARIFResults(result *CheckResult) []sarifResult {
+	results := make([]sarifResult, 0)
+	for _, c := range result.Components {
+		var ruleID, level string
+		switch c.Status {
+		case "compatible":
+			continue
+		case "incompatible", "waived":
+			ruleID, level = RuleIncompatibleComponent, "error"
+		default:
+			ruleID, level = RuleUnknownVersion, "note"
+		}
+
+		location := sarifLocation{
+			LogicalLocations: []sarifLogicalLocation{{
+				Name:               c.Name,
+				FullyQualifiedName: qualifiedName(result.Cluster, c.Name),
+				Kind:               "module",
+			}},
+		}
+		if result.Source != "" {
+			location.PhysicalLocation = &sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(result.Source)},
+			}
+		}
+
+		finding := sarifResult{
+			RuleID:    ruleID,
+			Level:     level,
+			Message:   sarifMessage{Text: findingMessage(result, c)},
+			Locations: []sarifLocation{location},
+		}
+		// Waived findings are reported as suppressed so code scanning
+		// does not flag them until the waiver expires
+		if c.Status == "waived" {
+			finding.Suppressions = []sarifSuppression{{Kind: "external", Justification: c.Notes}}
+		}
+		results = append(results, finding)
+	}
+
+	for _, finding := range result.Findings {
+		// A finding without a component is located at the cluster, if the
+		// result has one; node facts without a hostname leave only the file
+		var location sarifLocation
+		switch {
+		case finding.Component != "":
+			location.LogicalLocations = []sarifLogicalLocation{{
+				Name:               finding.Component,
+				FullyQualifiedName: qualifiedName(result.Cluster, finding.Component),
+				Kind:               "module",
+			}}
+		case result.Cluster != "":
+			location.LogicalLocations = []sarifLogicalLocation{{Name: result.Cluster, Kind: "namespace"}}
+		}
+		source := finding.Source
+		if source == "" {
+			source = result.Source
+		}
+		if source != "" {
+			location.PhysicalLocation = &sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(source)},
+			}
+		}
+		r := sarifResult{
+			RuleID:  findingRuleID(finding),
+			Level:   sarifLevel(finding.Severity),
+			Message: sarifMessage{Text: finding.Message},
+		}
+		if location.PhysicalLocation != nil || len(location.LogicalLocations) > 0 {
+			r.Locations = []sarifLocation{location}
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// sarifLevel maps a finding severity to a SARIF level
+func sarifLevel(severity string) string {
+	switch severity {
+	case "error":
+		return "error"
+	case "warning":
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+func qualifiedName(cluster, component string) string {
+	if cluster == "" {
+		return component
+	}
+	return cluster + "/" + component
+}
+
+// findingMessage describes a non-compatible component result in one sentence
+func findingMessage(result *CheckResult, c ComponentResult) string {
+	subject := c.Name
+	if result.Cluster != "" {
+		subject = fmt.Sprintf("%s in cluster %s", c.Name, result.Cluster)
+	}
+
+	required := c.Required
+	if required == "" {
+		required = c.SkewPolicy
+	}
+
+	switch {
+	case c.Status == "incompatible" || c.Status == "waived":
+		return fmt.Sprintf("%s version %s is not compatible with Kubernetes %s (required: %s)",
+			subject, c.Current, result.K8sVersion, required)
+	case c.Current == "":
+		return fmt.Sprintf("%s version is unknown for Kubernetes %s (required: %s)",
+			subject, result.K8sVersion, required)
+	default:
+		return fmt.Sprintf("%s version %s could not be compared with Kubernetes %s requirements (required: %s)",
+			subject, c.Current, result.K8sVersion, required)
+	}
+}
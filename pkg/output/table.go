@@ -0,0 +1,261 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ANSI colours used for statuses in table output
+const (
+	colorGreen  = "32"
+	colorRed    = "31"
+	colorYellow = "33"
+	colorCyan   = "36"
+)
+
+// minWrapWidth is the narrowest a wrapped column is shrunk to
+const minWrapWidth = 12
+
+// columnGap separates table columns
+const columnGap = "  "
+
+// NewTableFormatter creates a table formatter writing to file. When file is
+// a terminal, statuses are coloured (unless NO_COLOR is set) and tables are
+// wrapped to the terminal width.
+func NewTableFormatter(file *os.File) *TableFormatter {
+	f := &TableFormatter{Writer: file}
+	if isTerminal(file) {
+		f.Color = colorEnabled()
+		f.Width = terminalWidth(file)
+	}
+	return f
+}
+
+// colorEnabled reports whether the environment allows coloured output,
+// following https://no-color.org
+func colorEnabled() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return os.Getenv("TERM") != "dumb"
+}
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// terminalWidth returns the width of the terminal, preferring $COLUMNS, or
+// 0 when it cannot be determined
+func terminalWidth(file *os.File) int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return ioctlWidth(file)
+}
+
+// tableCell is a table cell with an optional ANSI colour
+type tableCell struct {
+	text  string
+	color string
+}
+
+// table renders aligned columns sized to their content. If the table is
+// wider than the available width, the wrap column is narrowed and its
+// cells are word-wrapped onto several lines.
+type table struct {
+	headers []string
+	rows    [][]tableCell
+	wrap    int // index of the column that wraps, or -1
+	rule    int // minimum width of the separator under the header
+}
+
+func newTable(headers ...string) *table {
+	return &table{headers: headers, wrap: -1}
+}
+
+func (t *table) addRow(cells ...tableCell) {
+	t.rows = append(t.rows, cells)
+}
+
+// plain converts strings to uncoloured cells
+func plain(texts ...string) []tableCell {
+	cells := make([]tableCell, len(texts))
+	for i, text := range texts {
+		cells[i] = tableCell{text: text}
+	}
+	return cells
+}
+
+// widths measures the columns and fits them into maxWidth when it is set
+func (t *table) widths(maxWidth int) []int {
+	widths := make([]int, len(t.headers))
+	for i, h := range t.headers {
+		widths[i] = displayWidth(h)
+	}
+	for _, row := range t.rows {
+		for i, c := range row {
+			if w := displayWidth(c.text); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	if maxWidth > 0 && t.wrap >= 0 {
+		if excess := sumWidths(widths) - maxWidth; excess > 0 {
+			widths[t.wrap] -= excess
+			if widths[t.wrap] < minWrapWidth {
+				widths[t.wrap] = minWrapWidth
+			}
+		}
+	}
+	return widths
+}
+
+func sumWidths(widths []int) int {
+	total := len(columnGap) * (len(widths) - 1)
+	for _, w := range widths {
+		total += w
+	}
+	return total
+}
+
+// render writes the header, a separator and the rows, returning the
+// rendered width
+func (t *table) render(w io.Writer, color bool, maxWidth int) int {
+	widths := t.widths(maxWidth)
+	total := sumWidths(widths)
+	if total < t.rule {
+		total = t.rule
+	}
+
+	t.renderRow(w, widths, plain(t.headers...), false)
+	_, _ = fmt.Fprintln(w, strings.Repeat("-", total))
+	for _, row := range t.rows {
+		t.renderRow(w, widths, row, color)
+	}
+	return total
+}
+
+func (t *table) renderRow(w io.Writer, widths []int, row []tableCell, color bool) {
+	lines := make([][]string, len(row))
+	height := 1
+	for i, c := range row {
+		if i == t.wrap {
+			lines[i] = wrapText(c.text, widths[i])
+		} else {
+			lines[i] = []string{c.text}
+		}
+		if len(lines[i]) > height {
+			height = len(lines[i])
+		}
+	}
+
+	for l := 0; l < height; l++ {
+		var b strings.Builder
+		for i, c := range row {
+			text := ""
+			if l < len(lines[i]) {
+				text = lines[i][l]
+			}
+			if i > 0 {
+				b.WriteString(columnGap)
+			}
+			if color && c.color != "" && text != "" {
+				b.WriteString(paint(c.color, text))
+			} else {
+				b.WriteString(text)
+			}
+			if i < len(row)-1 {
+				b.WriteString(strings.Repeat(" ", widths[i]-displayWidth(text)))
+			}
+		}
+		_, _ = fmt.Fprintln(w, strings.TrimRight(b.String(), " "))
+	}
+}
+
+// paint wraps text in an ANSI colour sequence
+func paint(color, text string) string {
+	return "\x1b[" + color + "m" + text + "\x1b[0m"
+}
+
+// wrapText splits text into lines no wider than width, breaking at spaces
+// and splitting words that are longer than a line
+func wrapText(text string, width int) []string {
+	if displayWidth(text) <= width {
+		return []string{text}
+	}
+
+	lines := make([]string, 0)
+	line := ""
+	for _, word := range strings.Fields(text) {
+		for displayWidth(word) > width {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			head, tail := splitAtWidth(word, width)
+			lines = append(lines, head)
+			word = tail
+		}
+		switch {
+		case line == "":
+			line = word
+		case displayWidth(line)+1+displayWidth(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func splitAtWidth(s string, width int) (string, string) {
+	w := 0
+	for i, r := range s {
+		rw := runeWidth(r)
+		if w+rw > width && i > 0 {
+			return s[:i], s[i:]
+		}
+		w += rw
+	}
+	return s, ""
+}
+
+// displayWidth returns the number of terminal cells text occupies
+func displayWidth(text string) int {
+	if !strings.ContainsFunc(text, func(r rune) bool { return r >= utf8.RuneSelf }) {
+		return len(text)
+	}
+	w := 0
+	for _, r := range text {
+		w += runeWidth(r)
+	}
+	return w
+}
+
+// runeWidth approximates the terminal width of a rune: emoji are two cells
+// wide and variation selectors take no space
+func runeWidth(r rune) int {
+	switch {
+	case r == 0xFE0F || r == 0x200D:
+		return 0
+	case r >= 0x1F300 && r <= 0x1FAFF,
+		r >= 0x2600 && r <= 0x27BF,
+		r >= 0x231A && r <= 0x23FF,
+		r >= 0x2B00 && r <= 0x2BFF:
+		return 2
+	}
+	return 1
+}
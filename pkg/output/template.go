@@ -0,0 +1,153 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/pmady/kube-dependency-checker/pkg/jsonpath"
+)
+
+// TemplateFormatter renders results with a Go template. As with kubectl,
+// the template sees the JSON form of the result, so fields are referenced
+// by their JSON names, e.g. {{.k8sVersion}} or {{range .components}}.
+type TemplateFormatter struct {
+	Writer   io.Writer
+	Template *template.Template
+}
+
+// JSONPathFormatter renders results with a kubectl-style JSONPath template
+type JSONPathFormatter struct {
+	Writer   io.Writer
+	JSONPath *jsonpath.JSONPath
+}
+
+// NewTemplateFormatter parses a Go template
+func NewTemplateFormatter(text string) (*TemplateFormatter, error) {
+	if text == "" {
+		return nil, fmt.Errorf("go-template format requires a template, e.g. -o go-template='{{.k8sVersion}}'")
+	}
+	tmpl, err := template.New("output").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid go-template: %w", err)
+	}
+	return &TemplateFormatter{Writer: os.Stdout, Template: tmpl}, nil
+}
+
+// NewJSONPathFormatter parses a JSONPath template
+func NewJSONPathFormatter(text string) (*JSONPathFormatter, error) {
+	if text == "" {
+		return nil, fmt.Errorf("jsonpath format requires a template, e.g. -o jsonpath='{.k8sVersion}'")
+	}
+	jp, err := jsonpath.Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jsonpath: %w", err)
+	}
+	return &JSONPathFormatter{Writer: os.Stdout, JSONPath: jp}, nil
+}
+
+// Format outputs the result through the template
+func (f *TemplateFormatter) Format(result *CheckResult) error {
+	return f.execute(result)
+}
+
+// FormatFleet outputs the fleet result through the template
+func (f *TemplateFormatter) FormatFleet(result *FleetResult) error {
+	return f.execute(result)
+}
+
+// FormatUpgrade outputs the upgrade plan through the template
+func (f *TemplateFormatter) FormatUpgrade(result *UpgradeResult) error {
+	return f.execute(result)
+}
+
+// FormatVersions outputs the versions through the template
+func (f *TemplateFormatter) FormatVersions(result *VersionsResult) error {
+	return f.execute(result)
+}
+
+func (f *TemplateFormatter) execute(v interface{}) error {
+	data, err := genericData(v)
+	if err != nil {
+		return err
+	}
+	// Render to a buffer so a failing template does not leave partial output
+	var buf bytes.Buffer
+	if err := f.Template.Execute(&buf, data); err != nil {
+		return fmt.Errorf("error executing go-template: %w", err)
+	}
+	_, err = f.Writer.Write(buf.Bytes())
+	return err
+}
+
+// Format outputs the result through the JSONPath template
+func (f *JSONPathFormatter) Format(result *CheckResult) error {
+	return f.execute(result)
+}
+
+// FormatFleet outputs the fleet result through the JSONPath template
+func (f *JSONPathFormatter) FormatFleet(result *FleetResult) error {
+	return f.execute(result)
+}
+
+// FormatUpgrade outputs the upgrade plan through the JSONPath template
+func (f *JSONPathFormatter) FormatUpgrade(result *UpgradeResult) error {
+	return f.execute(result)
+}
+
+// FormatVersions outputs the versions through the JSONPath template
+func (f *JSONPathFormatter) FormatVersions(result *VersionsResult) error {
+	return f.execute(result)
+}
+
+func (f *JSONPathFormatter) execute(v interface{}) error {
+	data, err := genericData(v)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := f.JSONPath.Execute(&buf, data); err != nil {
+		return fmt.Errorf("error executing jsonpath: %w", err)
+	}
+	_, err = f.Writer.Write(buf.Bytes())
+	return err
+}
+
+// genericData converts a result to the maps and slices of its JSON form
+func genericData(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
+
+// newTemplatedFormatter handles the formats that take an argument:
+// go-template=, go-template-file= and jsonpath=
+func newTemplatedFormatter(name, arg string) (Formatter, error) {
+	switch strings.ToLower(name) {
+	case "go-template", "template":
+		return NewTemplateFormatter(arg)
+	case "go-template-file", "template-file":
+		if arg == "" {
+			return nil, fmt.Errorf("go-template-file format requires a file name")
+		}
+		text, err := os.ReadFile(arg)
+		if err != nil {
+			return nil, fmt.Errorf("reading go-template file: %w", err)
+		}
+		return NewTemplateFormatter(string(text))
+	case "jsonpath":
+		return NewJSONPathFormatter(arg)
+	default:
+		return nil, unknownFormatError(name)
+	}
+}
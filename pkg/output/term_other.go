@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package output
+
+import "os"
+
+// ioctlWidth is not supported on this platform; $COLUMNS is used instead
+func ioctlWidth(file *os.File) int {
+	return 0
+}
@@ -0,0 +1,397 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pmady/kube-dependency-checker/pkg/version"
+)
+
+// errUndefined is returned when an expression uses a value that is not
+// known for the component, such as the current version of an unobserved
+// component. Rules that hit it are skipped.
+var errUndefined = errors.New("undefined value")
+
+// expr is a parsed rule expression
+type expr interface {
+	eval(vars map[string]interface{}) (interface{}, error)
+}
+
+type literal struct{ value interface{} }
+
+type ident struct{ name string }
+
+type not struct{ operand expr }
+
+type logical struct {
+	op          string // && or ||
+	left, right expr
+}
+
+type comparison struct {
+	op          string
+	left, right expr
+}
+
+// compile parses an expression, rejecting identifiers that are not known
+func compile(source string, known func(name string) bool) (expr, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, known: known}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	return e, nil
+}
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokString
+	tokNumber
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")"}
+
+func tokenize(s string) ([]token, error) {
+	tokens := make([]token, 0)
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexRune(s[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			tokens = append(tokens, token{kind: tokString, text: s[i+1 : i+1+end]})
+			i += end + 2
+		case unicode.IsDigit(c):
+			j := i
+			for j < len(s) && (unicode.IsDigit(rune(s[j])) || s[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: s[i:j]})
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := i
+			for j < len(s) && (unicode.IsLetter(rune(s[j])) || unicode.IsDigit(rune(s[j])) || strings.ContainsRune("_.-/", rune(s[j]))) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: s[i:j]})
+			i = j
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(s[i:], op) {
+					tokens = append(tokens, token{kind: tokOp, text: op})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q at offset %d", c, i)
+			}
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	known  func(string) bool
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *parser) acceptOp(ops ...string) (string, bool) {
+	t, ok := p.peek()
+	if !ok {
+		return "", false
+	}
+	for _, op := range ops {
+		if (t.kind == tokOp || t.kind == tokIdent) && t.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) parseOr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.acceptOp("||"); !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logical{op: "||", left: left, right: right}
+	}
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.acceptOp("&&"); !ok {
+			return left, nil
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = logical{op: "&&", left: left, right: right}
+	}
+}
+
+func (p *parser) parseNot() (expr, error) {
+	if _, ok := p.acceptOp("!"); ok {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return not{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (expr, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.acceptOp("==", "!=", "<=", ">=", "<", ">", "matches")
+	if !ok {
+		return left, nil
+	}
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return comparison{op: op, left: left, right: right}, nil
+}
+
+func (p *parser) parseOperand() (expr, error) {
+	t, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	p.pos++
+
+	switch t.kind {
+	case tokString:
+		return literal{value: t.text}, nil
+	case tokNumber:
+		// Dotted numbers are versions, e.g. 1.30 or 3.5.12
+		if strings.Contains(t.text, ".") {
+			return literal{value: t.text}, nil
+		}
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", t.text)
+		}
+		return literal{value: f}, nil
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return literal{value: true}, nil
+		case "false":
+			return literal{value: false}, nil
+		}
+		if p.known != nil && !p.known(t.text) {
+			return nil, fmt.Errorf("unknown identifier %q", t.text)
+		}
+		return ident{name: t.text}, nil
+	}
+
+	if t.text == "(" {
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := p.acceptOp(")"); !ok {
+			return nil, fmt.Errorf("missing ')'")
+		}
+		return e, nil
+	}
+	return nil, fmt.Errorf("unexpected %q", t.text)
+}
+
+func (l literal) eval(map[string]interface{}) (interface{}, error) {
+	return l.value, nil
+}
+
+func (i ident) eval(vars map[string]interface{}) (interface{}, error) {
+	v, ok := vars[i.name]
+	if !ok || v == nil || v == "" {
+		return nil, errUndefined
+	}
+	return v, nil
+}
+
+func (n not) eval(vars map[string]interface{}) (interface{}, error) {
+	b, err := evalBool(n.operand, vars)
+	if err != nil {
+		return nil, err
+	}
+	return !b, nil
+}
+
+// eval uses three-valued logic: an undefined operand only makes the result
+// undefined when the other operand does not decide it
+func (l logical) eval(vars map[string]interface{}) (interface{}, error) {
+	decides := func(b bool) bool { return (l.op == "&&" && !b) || (l.op == "||" && b) }
+
+	left, leftErr := evalBool(l.left, vars)
+	if leftErr != nil && !errors.Is(leftErr, errUndefined) {
+		return nil, leftErr
+	}
+	if leftErr == nil && decides(left) {
+		return left, nil
+	}
+	right, err := evalBool(l.right, vars)
+	if err != nil {
+		return nil, err
+	}
+	if leftErr != nil && !decides(right) {
+		return nil, leftErr
+	}
+	return right, nil
+}
+
+func (c comparison) eval(vars map[string]interface{}) (interface{}, error) {
+	left, err := c.left.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	right, err := c.right.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.op == "matches" {
+		ls, lok := left.(string)
+		rs, rok := right.(string)
+		if !lok || !rok {
+			return nil, fmt.Errorf("matches needs strings, got %v and %v", left, right)
+		}
+		return path.Match(rs, ls)
+	}
+
+	cmp, err := compare(left, right, c.op)
+	if err != nil {
+		return nil, err
+	}
+	switch c.op {
+	case "==":
+		return cmp == 0, nil
+	case "!=":
+		return cmp != 0, nil
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	default:
+		return cmp >= 0, nil
+	}
+}
+
+// compare orders two values: numerically, as versions when both parse as
+// versions, or as strings for equality
+func compare(left, right interface{}, op string) (int, error) {
+	if lf, ok := toNumber(left); ok {
+		if rf, ok := toNumber(right); ok {
+			switch {
+			case lf < rf:
+				return -1, nil
+			case lf > rf:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+
+	ls, lok := left.(string)
+	rs, rok := right.(string)
+	if lok && rok {
+		lv, lerr := version.Parse(ls)
+		rv, rerr := version.Parse(rs)
+		if lerr == nil && rerr == nil {
+			return lv.Compare(rv), nil
+		}
+		if op == "==" || op == "!=" {
+			return strings.Compare(ls, rs), nil
+		}
+		return 0, fmt.Errorf("cannot order %q and %q", ls, rs)
+	}
+
+	lb, lok := left.(bool)
+	rb, rok := right.(bool)
+	if lok && rok && (op == "==" || op == "!=") {
+		if lb == rb {
+			return 0, nil
+		}
+		return 1, nil
+	}
+	return 0, fmt.Errorf("cannot compare %v and %v", left, right)
+}
+
+func toNumber(v interface{}) (float64, bool) {
+	switch t := v.(type) {
+	case float64:
+		return t, true
+	case int:
+		return float64(t), true
+	case string:
+		f, err := strconv.ParseFloat(t, 64)
+		return f, err == nil && !strings.Contains(t, ".")
+	}
+	return 0, false
+}
+
+func evalBool(e expr, vars map[string]interface{}) (bool, error) {
+	v, err := e.eval(vars)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expected a boolean, got %v", v)
+	}
+	return b, nil
+}
@@ -0,0 +1,294 @@
+// Package policy evaluates organisation-specific rules over check results.
+//
+// A policy file lists rules written in a small expression language:
+//
+//	rules:
+//	  - name: prod-kubelet-skew
+//	    description: kubelet must be within 1 minor of kube-apiserver in prod
+//	    severity: error
+//	    when: labels.env == "prod" && component == "kubelet"
+//	    require: skew <= 1
+//	  - name: etcd-recommended-patch
+//	    severity: warning
+//	    when: component == "etcd"
+//	    require: current >= recommended
+//
+// Component rules are evaluated for every component result and see the
+// variables component, current, required, recommended, status, notes and,
+// for components with a skew policy such as the kubelet, skew (minor
+// versions the component lags behind kube-apiserver), plus the cluster
+// variables. Cluster rules (scope: cluster) see cluster, k8sVersion,
+// source and labels.<key>.
+//
+// Expressions support ==, !=, <, <=, >, >=, matches (glob), &&, || and !.
+// Strings that parse as versions are compared as versions. A comparison
+// with a value that is not known, such as the current version of an
+// unobserved component, is undefined. && and || decide on their other
+// operand when it settles the result (false && x, true || x), and a rule
+// whose when or require is still undefined is skipped.
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pmady/kube-dependency-checker/pkg/checker"
+	"github.com/pmady/kube-dependency-checker/pkg/output"
+	"gopkg.in/yaml.v3"
+)
+
+// Severities of findings, from most to least severe
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// Rule scopes
+const (
+	ScopeComponent = "component"
+	ScopeCluster   = "cluster"
+)
+
+// Policy is a set of rules
+type Policy struct {
+	Rules []*Rule `json:"rules" yaml:"rules"`
+}
+
+// Rule produces a finding wherever When holds and Require does not
+type Rule struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Severity    string `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Scope       string `json:"scope,omitempty" yaml:"scope,omitempty"`
+	When        string `json:"when,omitempty" yaml:"when,omitempty"`
+	Require     string `json:"require" yaml:"require"`
+	// Message overrides the finding message; {name} placeholders are
+	// replaced with variable values, e.g. "{component} {current} is too old"
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+
+	when    expr
+	require expr
+}
+
+var componentVars = []string{"component", "current", "required", "recommended", "status", "notes", "skew"}
+
+var clusterVars = []string{"cluster", "k8sVersion", "source"}
+
+// Load reads a policy file
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Parse parses a policy and compiles its rules
+func Parse(data []byte) (*Policy, error) {
+	var p Policy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+	for i, r := range p.Rules {
+		if r.Name == "" {
+			return nil, fmt.Errorf("rule %d has no name", i)
+		}
+		if err := r.compile(); err != nil {
+			return nil, fmt.Errorf("rule %s: %w", r.Name, err)
+		}
+	}
+	return &p, nil
+}
+
+func (r *Rule) compile() error {
+	if r.Severity == "" {
+		r.Severity = SeverityError
+	}
+	if SeverityRank(r.Severity) < 0 {
+		return fmt.Errorf("unknown severity %q (want error, warning or info)", r.Severity)
+	}
+	if r.Scope == "" {
+		r.Scope = ScopeComponent
+	}
+	if r.Scope != ScopeComponent && r.Scope != ScopeCluster {
+		return fmt.Errorf("unknown scope %q (want component or cluster)", r.Scope)
+	}
+	if r.Require == "" {
+		return fmt.Errorf("require expression is empty")
+	}
+
+	known := func(name string) bool {
+		if strings.HasPrefix(name, "labels.") {
+			return true
+		}
+		if r.Scope == ScopeComponent && contains(componentVars, name) {
+			return true
+		}
+		return contains(clusterVars, name)
+	}
+
+	var err error
+	if r.When != "" {
+		if r.when, err = compile(r.When, known); err != nil {
+			return fmt.Errorf("when: %w", err)
+		}
+	}
+	if r.require, err = compile(r.Require, known); err != nil {
+		return fmt.Errorf("require: %w", err)
+	}
+	return nil
+}
+
+// SeverityRank orders severities: 2 for error, 1 for warning, 0 for info
+// and -1 for anything else
+func SeverityRank(severity string) int {
+	switch severity {
+	case SeverityError:
+		return 2
+	case SeverityWarning:
+		return 1
+	case SeverityInfo:
+		return 0
+	}
+	return -1
+}
+
+// Evaluate applies the rules to a check result and returns the findings.
+// Findings are also appended to result.Findings.
+func (p *Policy) Evaluate(result *output.CheckResult) ([]output.Finding, error) {
+	clusterScope := clusterVariables(result)
+	findings := make([]output.Finding, 0)
+
+	for _, r := range p.Rules {
+		if r.Scope == ScopeCluster {
+			f, err := r.evaluate(clusterScope, "")
+			if err != nil {
+				return nil, err
+			}
+			if f != nil {
+				findings = append(findings, *f)
+			}
+			continue
+		}
+
+		for _, c := range result.Components {
+			vars := componentVariables(clusterScope, result, c)
+			f, err := r.evaluate(vars, c.Name)
+			if err != nil {
+				return nil, err
+			}
+			if f != nil {
+				findings = append(findings, *f)
+			}
+		}
+	}
+
+	result.Findings = append(result.Findings, findings...)
+	return findings, nil
+}
+
+func (r *Rule) evaluate(vars map[string]interface{}, component string) (*output.Finding, error) {
+	if r.when != nil {
+		applies, err := evalBool(r.when, vars)
+		if errors.Is(err, errUndefined) || (err == nil && !applies) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, r.evalError(component, err)
+		}
+	}
+
+	ok, err := evalBool(r.require, vars)
+	if errors.Is(err, errUndefined) || (err == nil && ok) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, r.evalError(component, err)
+	}
+
+	return &output.Finding{
+		Rule:      r.Name,
+		Severity:  r.Severity,
+		Component: component,
+		Message:   r.message(vars),
+	}, nil
+}
+
+func (r *Rule) evalError(component string, err error) error {
+	if component != "" {
+		return fmt.Errorf("rule %s on %s: %w", r.Name, component, err)
+	}
+	return fmt.Errorf("rule %s: %w", r.Name, err)
+}
+
+var placeholder = regexp.MustCompile(`\{([A-Za-z0-9_./-]+)\}`)
+
+func (r *Rule) message(vars map[string]interface{}) string {
+	if r.Message != "" {
+		return placeholder.ReplaceAllStringFunc(r.Message, func(m string) string {
+			if v, ok := vars[m[1:len(m)-1]]; ok && v != nil {
+				return fmt.Sprint(v)
+			}
+			return m
+		})
+	}
+
+	text := r.Description
+	if text == "" {
+		text = fmt.Sprintf("%s is not satisfied", r.Require)
+	}
+	if current, ok := vars["current"].(string); ok && current != "" {
+		text = fmt.Sprintf("%s (current: %s)", text, current)
+	}
+	return text
+}
+
+func clusterVariables(result *output.CheckResult) map[string]interface{} {
+	vars := map[string]interface{}{
+		"cluster":    result.Cluster,
+		"k8sVersion": result.K8sVersion,
+		"source":     result.Source,
+	}
+	for k, v := range result.Labels {
+		vars["labels."+k] = v
+	}
+	return vars
+}
+
+func componentVariables(cluster map[string]interface{}, result *output.CheckResult, c output.ComponentResult) map[string]interface{} {
+	vars := make(map[string]interface{}, len(cluster)+len(componentVars))
+	for k, v := range cluster {
+		vars[k] = v
+	}
+	vars["component"] = strings.ToLower(c.Name)
+	vars["current"] = c.Current
+	vars["required"] = c.Required
+	vars["recommended"] = c.Recommended
+	vars["status"] = c.Status
+	vars["notes"] = c.Notes
+	// Skew is only meaningful for components versioned with Kubernetes,
+	// which are the ones with a skew policy
+	if c.Current != "" && c.SkewPolicy != "" {
+		if skew, err := checker.SkewMinors(result.K8sVersion, c.Current); err == nil {
+			vars["skew"] = skew
+		}
+	}
+	return vars
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
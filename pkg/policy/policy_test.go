@@ -0,0 +1,198 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pmady/kube-dependency-checker/pkg/checker"
+	"github.com/pmady/kube-dependency-checker/pkg/output"
+)
+
+const testPolicy = `
+rules:
+  - name: prod-kubelet-skew
+    description: kubelet must be within 1 minor of kube-apiserver in prod
+    when: labels.env == "prod" && component == "kubelet"
+    require: skew <= 1
+  - name: etcd-recommended-patch
+    severity: warning
+    when: component == "etcd"
+    require: current >= recommended
+    message: "etcd {current} is older than {recommended}"
+  - name: supported-release
+    scope: cluster
+    severity: info
+    require: k8sVersion >= 1.31
+`
+
+func testResult(env string) *output.CheckResult {
+	return &output.CheckResult{
+		Cluster:    "prod-east",
+		Labels:     map[string]string{"env": env},
+		K8sVersion: "1.30",
+		Components: []output.ComponentResult{
+			{Name: "etcd", Current: "3.5.10", Recommended: "3.5.12", Status: checker.StatusCompatible},
+			{Name: "CoreDNS", Recommended: "1.11.1", Status: checker.StatusUnknown},
+			{Name: "kubelet", Current: "1.28.3", Status: checker.StatusCompatible, SkewPolicy: "Up to 3 minor versions older than kube-apiserver"},
+		},
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	p, err := Parse([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	result := testResult("prod")
+	findings, err := p.Evaluate(result)
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+
+	want := []output.Finding{
+		{Rule: "prod-kubelet-skew", Severity: SeverityError, Component: "kubelet", Message: "kubelet must be within 1 minor of kube-apiserver in prod (current: 1.28.3)"},
+		{Rule: "etcd-recommended-patch", Severity: SeverityWarning, Component: "etcd", Message: "etcd 3.5.10 is older than 3.5.12"},
+		{Rule: "supported-release", Severity: SeverityInfo, Message: "k8sVersion >= 1.31 is not satisfied"},
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("Evaluate() = %+v, want %d findings", findings, len(want))
+	}
+	for i := range want {
+		if findings[i] != want[i] {
+			t.Errorf("finding %d = %+v, want %+v", i, findings[i], want[i])
+		}
+	}
+	if len(result.Findings) != len(want) {
+		t.Errorf("result.Findings has %d entries, want %d", len(result.Findings), len(want))
+	}
+
+	// The skew rule only applies to prod clusters
+	findings, err = p.Evaluate(testResult("staging"))
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	for _, f := range findings {
+		if f.Rule == "prod-kubelet-skew" {
+			t.Error("prod-kubelet-skew applied to a staging cluster")
+		}
+	}
+}
+
+func TestExpressions(t *testing.T) {
+	vars := map[string]interface{}{
+		"cluster":    "prod-east",
+		"component":  "etcd",
+		"current":    "3.5.10",
+		"k8sVersion": "1.30",
+		"skew":       2,
+		"labels.env": "prod",
+	}
+	tests := []struct {
+		expr      string
+		want      bool
+		undefined bool
+	}{
+		{`cluster matches "prod-*"`, true, false},
+		{`cluster matches "staging-*"`, false, false},
+		{`current < 3.5.12`, true, false},
+		{`current >= "3.5.9"`, true, false},
+		{`k8sVersion == 1.30`, true, false},
+		{`skew > 1 && skew <= 3`, true, false},
+		{`!(skew > 1) || component == "etcd"`, true, false},
+		{`component != 'etcd'`, false, false},
+		// An undefined operand only matters when the other one does not
+		// decide the result
+		{`labels.tier == "gold" || true`, true, false},
+		{`labels.tier == "gold" || component == "etcd"`, true, false},
+		{`component == "etcd" || labels.tier == "gold"`, true, false},
+		{`labels.tier == "gold" && false`, false, false},
+		{`!(labels.tier == "gold") || skew > 1`, true, false},
+		{`labels.tier == "gold" || component == "coredns"`, false, true},
+		{`labels.tier == "gold" && true`, false, true},
+		{`!(labels.tier == "gold")`, false, true},
+	}
+	for _, tt := range tests {
+		e, err := compile(tt.expr, nil)
+		if err != nil {
+			t.Errorf("compile(%q) error = %v", tt.expr, err)
+			continue
+		}
+		got, err := evalBool(e, vars)
+		if (err == errUndefined) != tt.undefined {
+			t.Errorf("eval(%q) error = %v, want undefined %t", tt.expr, err, tt.undefined)
+			continue
+		}
+		if err != nil && err != errUndefined {
+			t.Errorf("eval(%q) error = %v", tt.expr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("eval(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestRulesOnUndefinedValues(t *testing.T) {
+	p, err := Parse([]byte(`
+rules:
+  - name: prod-or-incompatible
+    when: labels.env == "prod" || status == "incompatible"
+    require: false
+  - name: skew
+    require: skew <= 1
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := &output.CheckResult{
+		Cluster:    "lab",
+		K8sVersion: "1.30",
+		Components: []output.ComponentResult{
+			{Name: "containerd", Current: "1.7.13", Status: checker.StatusIncompatible},
+			{Name: "CoreDNS", Current: "1.11.1", Status: checker.StatusCompatible},
+			{Name: "kubelet", Current: "1.29.2", Status: checker.StatusCompatible, SkewPolicy: "Up to 3 minor versions older than kube-apiserver"},
+		},
+	}
+	findings, err := p.Evaluate(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The cluster has no env label, but containerd is incompatible; skew is
+	// not defined for containerd and CoreDNS, whose versions are not
+	// Kubernetes versions
+	got := make([]string, len(findings))
+	for i, f := range findings {
+		got[i] = f.Rule + " " + f.Component
+	}
+	want := []string{"prod-or-incompatible containerd"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Evaluate() = %v, want %v", got, want)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, data := range []string{
+		"rules:\n  - require: skew <= 1\n",
+		"rules:\n  - name: r\n    require: skw <= 1\n",
+		"rules:\n  - name: r\n    require: skew <=\n",
+		"rules:\n  - name: r\n    severity: fatal\n    require: skew <= 1\n",
+		"rules:\n  - name: r\n    scope: cluster\n    require: current >= 1.0.0\n",
+		"rules:\n  - name: r\n    require: (skew <= 1\n",
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%q) expected error", data)
+		}
+	}
+}
+
+func TestEvaluateTypeError(t *testing.T) {
+	p, err := Parse([]byte("rules:\n  - name: r\n    require: component\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Evaluate(testResult("prod")); err == nil {
+		t.Error("Evaluate() expected error for a non-boolean require expression")
+	}
+}
@@ -0,0 +1,170 @@
+// Package server exposes the compatibility checks over a REST API.
+// Responses use the same JSON documents as the CLI's -o json output.
+package server
+
+import (
+	_ "embed"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/pmady/kube-dependency-checker/pkg/checker"
+)
+
+//go:embed openapi.json
+var openAPISpec []byte
+
+// Server serves the compatibility API
+type Server struct {
+	logger *log.Logger
+	mux    *http.ServeMux
+	ready  atomic.Bool
+}
+
+// errorResponse is the body returned for failed requests
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// New creates a new API server that logs requests to logger
+func New(logger *log.Logger) *Server {
+	s := &Server{
+		logger: logger,
+		mux:    http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("GET /healthz", s.handleHealthz)
+	s.mux.HandleFunc("GET /readyz", s.handleReadyz)
+	s.mux.HandleFunc("GET /openapi.json", s.handleOpenAPI)
+	s.mux.HandleFunc("GET /api/v1/check", s.handleCheck)
+	s.mux.HandleFunc("GET /api/v1/upgrade", s.handleUpgrade)
+	s.mux.HandleFunc("GET /api/v1/versions", s.handleVersions)
+
+	return s
+}
+
+// SetReady marks the server as ready or not ready to serve traffic
+func (s *Server) SetReady(ready bool) {
+	s.ready.Store(ready)
+}
+
+// Handler returns the HTTP handler with request logging
+func (s *Server) Handler() http.Handler {
+	return s.logRequests(s.mux)
+}
+
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeText(w, http.StatusOK, "ok")
+}
+
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if !s.ready.Load() {
+		writeText(w, http.StatusServiceUnavailable, "not ready")
+		return
+	}
+	writeText(w, http.StatusOK, "ok")
+}
+
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(openAPISpec)
+}
+
+func (s *Server) handleCheck(w http.ResponseWriter, r *http.Request) {
+	k8sVersion := r.URL.Query().Get("k8sVersion")
+	if k8sVersion == "" {
+		writeError(w, http.StatusBadRequest, "query parameter 'k8sVersion' is required")
+		return
+	}
+
+	result, err := checker.Check(k8sVersion)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleUpgrade(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	from, to := query.Get("from"), query.Get("to")
+	if from == "" || to == "" {
+		writeError(w, http.StatusBadRequest, "query parameters 'from' and 'to' are required")
+		return
+	}
+
+	result, err := checker.PlanUpgrade(from, to)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleVersions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	component, k8sVersion := query.Get("component"), query.Get("k8sVersion")
+	opts := checker.VersionsOptions{
+		Patches:  query.Get("patches") == "true",
+		Archived: query.Get("archived") == "true",
+	}
+
+	switch {
+	case component == "":
+		writeJSON(w, http.StatusOK, checker.ListK8sVersions(opts))
+	case k8sVersion == "":
+		result, err := checker.ComponentVersions(component, opts)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	default:
+		result, err := checker.ComponentVersion(component, k8sVersion)
+		if err != nil {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	}
+}
+
+// statusRecorder captures the response status for request logging
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (s *Server) logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		s.logger.Printf("%s %s %d %s", r.Method, r.URL.RequestURI(), rec.status, time.Since(start))
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
+
+func writeText(w http.ResponseWriter, status int, body string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(body + "\n"))
+}
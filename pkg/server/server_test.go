@@ -0,0 +1,93 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pmady/kube-dependency-checker/pkg/output"
+)
+
+func newTestServer() *Server {
+	s := New(log.New(io.Discard, "", 0))
+	s.SetReady(true)
+	return s
+}
+
+func TestEndpointsStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{"healthz", "/healthz", http.StatusOK},
+		{"readyz", "/readyz", http.StatusOK},
+		{"openapi", "/openapi.json", http.StatusOK},
+		{"check", "/api/v1/check?k8sVersion=1.30", http.StatusOK},
+		{"check with v prefix", "/api/v1/check?k8sVersion=v1.30", http.StatusOK},
+		{"check missing version", "/api/v1/check", http.StatusBadRequest},
+		{"check unsupported version", "/api/v1/check?k8sVersion=1.20", http.StatusBadRequest},
+		{"upgrade", "/api/v1/upgrade?from=1.28&to=1.30", http.StatusOK},
+		{"upgrade backwards", "/api/v1/upgrade?from=1.30&to=1.28", http.StatusBadRequest},
+		{"upgrade missing target", "/api/v1/upgrade?from=1.28", http.StatusBadRequest},
+		{"versions list", "/api/v1/versions", http.StatusOK},
+		{"versions component", "/api/v1/versions?component=etcd", http.StatusOK},
+		{"versions component for release", "/api/v1/versions?component=etcd&k8sVersion=1.30", http.StatusOK},
+		{"versions unknown component", "/api/v1/versions?component=invalid&k8sVersion=1.30", http.StatusNotFound},
+		{"unknown path", "/api/v1/unknown", http.StatusNotFound},
+	}
+
+	handler := newTestServer().Handler()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("GET %s status = %d, want %d", tt.path, rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestReadyzNotReady(t *testing.T) {
+	s := newTestServer()
+	s.SetReady(false)
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("GET /readyz status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestCheckResponse(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newTestServer().Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/check?k8sVersion=1.30", nil))
+
+	var result output.CheckResult
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if result.K8sVersion != "1.30" {
+		t.Errorf("k8sVersion = %s, want 1.30", result.K8sVersion)
+	}
+	if len(result.Components) == 0 {
+		t.Error("response has no components")
+	}
+	if result.Summary.TotalComponents != len(result.Components) {
+		t.Errorf("summary totalComponents = %d, want %d", result.Summary.TotalComponents, len(result.Components))
+	}
+}
+
+func TestOpenAPISpecIsValidJSON(t *testing.T) {
+	var spec map[string]interface{}
+	if err := json.Unmarshal(openAPISpec, &spec); err != nil {
+		t.Fatalf("openapi.json is not valid JSON: %v", err)
+	}
+	if _, ok := spec["paths"]; !ok {
+		t.Error("openapi.json has no paths")
+	}
+}
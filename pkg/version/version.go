@@ -3,6 +3,7 @@ package version
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -43,6 +44,19 @@ func Parse(s string) (*Version, error) {
 	}, nil
 }
 
+var versionPrefix = regexp.MustCompile(`^v?(\d+\.\d+(?:\.\d+)?)`)
+
+// Clean returns the leading version of s without the "v" prefix and any
+// pre-release or build suffix, e.g. v1.30.4-eks-a737599 becomes 1.30.4 and
+// the image tag 3.5.12-0 becomes 3.5.12
+func Clean(s string) (string, bool) {
+	m := versionPrefix.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
 // String returns the version as a string
 func (v *Version) String() string {
 	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
@@ -77,6 +91,13 @@ func (v *Version) Compare(other *Version) int {
 	return 0
 }
 
+// Sort sorts versions in ascending order
+func Sort(versions []*Version) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].Compare(versions[j]) < 0
+	})
+}
+
 // MinorDiff returns the difference in minor versions
 func (v *Version) MinorDiff(other *Version) int {
 	diff := v.Minor - other.Minor
@@ -1,6 +1,7 @@
 package version
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -139,3 +140,36 @@ func TestVersion_IsCompatibleWithAPIServer(t *testing.T) {
 		})
 	}
 }
+
+func TestSort(t *testing.T) {
+	var versions []*Version
+	for _, s := range []string{"2.0", "1.100", "1.9", "1.10.1", "1.10"} {
+		v, _ := Parse(s)
+		versions = append(versions, v)
+	}
+	Sort(versions)
+
+	var got []string
+	for _, v := range versions {
+		got = append(got, v.Raw)
+	}
+	if want := "1.9 1.10 1.10.1 1.100 2.0"; strings.Join(got, " ") != want {
+		t.Errorf("Sort() = %v, want %s", got, want)
+	}
+}
+
+func TestClean(t *testing.T) {
+	tests := map[string]string{
+		"v1.30.4-eks-a737599": "1.30.4",
+		"3.5.12-0":            "3.5.12",
+		"v1.11.1":             "1.11.1",
+		"1.30":                "1.30",
+		"latest":              "",
+	}
+	for in, want := range tests {
+		got, ok := Clean(in)
+		if got != want || ok != (want != "") {
+			t.Errorf("Clean(%s) = (%s, %v), want %s", in, got, ok, want)
+		}
+	}
+}
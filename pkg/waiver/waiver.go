@@ -0,0 +1,161 @@
+// Package waiver applies time-limited exceptions to check results. A waiver
+// accepts a specific incompatible component version on a cluster until its
+// expiry date, after which the component is reported as incompatible again.
+//
+//	waivers:
+//	  - cluster: prod-east
+//	    component: containerd
+//	    version: 1.6.x
+//	    expires: 2026-11-30
+//	    reason: containerd 1.7 rollout tracked in OPS-1234
+package waiver
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pmady/kube-dependency-checker/pkg/checker"
+	"github.com/pmady/kube-dependency-checker/pkg/output"
+	"gopkg.in/yaml.v3"
+)
+
+// DateLayout is the format of expiry dates
+const DateLayout = "2006-01-02"
+
+// File is a waiver file
+type File struct {
+	Waivers []Waiver `json:"waivers" yaml:"waivers"`
+}
+
+// Waiver accepts an incompatible component version until it expires
+type Waiver struct {
+	// Cluster is the cluster name, or "*" for every cluster
+	Cluster   string `json:"cluster" yaml:"cluster"`
+	Component string `json:"component" yaml:"component"`
+	// Version is the accepted version; "x" or "*" segments match anything,
+	// e.g. 1.6.x
+	Version string `json:"version" yaml:"version"`
+	// Expires is the last day (UTC) the waiver applies, as YYYY-MM-DD
+	Expires string `json:"expires" yaml:"expires"`
+	Reason  string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// Load reads a waiver file
+func Load(path string) ([]Waiver, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	waivers, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return waivers, nil
+}
+
+// Parse parses and validates a waiver file
+func Parse(data []byte) ([]Waiver, error) {
+	var f File
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid waiver file: %w", err)
+	}
+	for i, w := range f.Waivers {
+		switch {
+		case w.Cluster == "":
+			return nil, fmt.Errorf("waiver %d has no cluster", i)
+		case w.Component == "":
+			return nil, fmt.Errorf("waiver %d has no component", i)
+		case w.Version == "":
+			return nil, fmt.Errorf("waiver %d has no version", i)
+		}
+		if _, err := time.Parse(DateLayout, w.Expires); err != nil {
+			return nil, fmt.Errorf("waiver %d has an invalid expiry date %q (want YYYY-MM-DD)", i, w.Expires)
+		}
+	}
+	return f.Waivers, nil
+}
+
+// Matches reports whether the waiver covers a component version on a cluster
+func (w Waiver) Matches(cluster, component, current string) bool {
+	if w.Cluster != "*" && w.Cluster != cluster {
+		return false
+	}
+	if !strings.EqualFold(w.Component, component) {
+		return false
+	}
+	return versionMatches(w.Version, current)
+}
+
+// Expired reports whether the waiver's last day has passed at now
+func (w Waiver) Expired(now time.Time) bool {
+	expires, err := time.Parse(DateLayout, w.Expires)
+	if err != nil {
+		return true
+	}
+	return !now.UTC().Before(expires.AddDate(0, 0, 1))
+}
+
+// Apply marks incompatible components covered by a waiver as waived and
+// recounts the summary. Any unexpired matching waiver is used, so a renewed
+// waiver may be listed after the one it replaces. Components whose only
+// matching waivers have expired stay incompatible with a note saying so.
+func Apply(result *output.CheckResult, waivers []Waiver, now time.Time) {
+	for i := range result.Components {
+		c := &result.Components[i]
+		if c.Status != checker.StatusIncompatible {
+			continue
+		}
+		var match *Waiver
+		for j := range waivers {
+			w := &waivers[j]
+			if !w.Matches(result.Cluster, c.Name, c.Current) {
+				continue
+			}
+			if !w.Expired(now) {
+				match = w
+				break
+			}
+			if match == nil {
+				match = w
+			}
+		}
+		if match == nil {
+			continue
+		}
+
+		note := fmt.Sprintf("Waiver expired on %s", match.Expires)
+		if !match.Expired(now) {
+			c.Status = checker.StatusWaived
+			note = fmt.Sprintf("Waived until %s", match.Expires)
+		}
+		if match.Reason != "" {
+			note += ": " + match.Reason
+		}
+		c.Notes = joinNotes(note, c.Notes)
+	}
+	result.Summary = checker.Summarize(result.Components)
+}
+
+// versionMatches compares a version with a pattern segment by segment
+func versionMatches(pattern, current string) bool {
+	p := strings.Split(strings.TrimPrefix(pattern, "v"), ".")
+	v := strings.Split(strings.TrimPrefix(current, "v"), ".")
+	for i, seg := range p {
+		if seg == "x" || seg == "*" {
+			return true
+		}
+		if i >= len(v) || v[i] != seg {
+			return false
+		}
+	}
+	return len(p) == len(v)
+}
+
+func joinNotes(note, existing string) string {
+	if existing == "" {
+		return note
+	}
+	return note + ". " + existing
+}
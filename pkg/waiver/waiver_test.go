@@ -0,0 +1,150 @@
+package waiver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pmady/kube-dependency-checker/pkg/checker"
+	"github.com/pmady/kube-dependency-checker/pkg/output"
+)
+
+const testWaivers = `
+waivers:
+  - cluster: prod
+    component: containerd
+    version: 1.5.x
+    expires: 2026-11-30
+    reason: rollout in progress
+  - cluster: "*"
+    component: etcd
+    version: 3.4.27
+    expires: 2026-01-31
+`
+
+func testResult(cluster string) *output.CheckResult {
+	components := []output.ComponentResult{
+		{Name: "etcd", Current: "3.4.27", Status: checker.StatusIncompatible},
+		{Name: "containerd", Current: "1.5.18", Status: checker.StatusIncompatible, Notes: "containerd 1.6+ supported"},
+		{Name: "CoreDNS", Current: "1.11.1", Status: checker.StatusCompatible},
+	}
+	return &output.CheckResult{
+		Cluster:    cluster,
+		K8sVersion: "1.30",
+		Components: components,
+		Summary:    checker.Summarize(components),
+	}
+}
+
+func TestApply(t *testing.T) {
+	waivers, err := Parse([]byte(testWaivers))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	result := testResult("prod")
+	Apply(result, waivers, now)
+
+	if got := result.Components[1].Status; got != checker.StatusWaived {
+		t.Errorf("containerd status = %s, want waived", got)
+	}
+	if got := result.Components[1].Notes; got != "Waived until 2026-11-30: rollout in progress. containerd 1.6+ supported" {
+		t.Errorf("containerd notes = %q", got)
+	}
+	if got := result.Components[0].Status; got != checker.StatusIncompatible {
+		t.Errorf("etcd status = %s, want incompatible after its waiver expired", got)
+	}
+	if got := result.Components[0].Notes; got != "Waiver expired on 2026-01-31" {
+		t.Errorf("etcd notes = %q", got)
+	}
+	if result.Summary.WaivedCount != 1 || result.Summary.IncompatibleCount != 1 {
+		t.Errorf("summary = %+v, want 1 waived and 1 incompatible", result.Summary)
+	}
+
+	// The containerd waiver is scoped to the prod cluster
+	other := testResult("stage")
+	Apply(other, waivers, now)
+	if got := other.Components[1].Status; got != checker.StatusIncompatible {
+		t.Errorf("stage containerd status = %s, want incompatible", got)
+	}
+}
+
+func TestApplyPrefersUnexpiredWaiver(t *testing.T) {
+	waivers, err := Parse([]byte(`
+waivers:
+  - cluster: prod
+    component: etcd
+    version: 3.4.x
+    expires: 2026-01-31
+    reason: first extension
+  - cluster: prod
+    component: etcd
+    version: 3.4.27
+    expires: 2026-06-30
+    reason: renewed
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	result := testResult("prod")
+	Apply(result, waivers, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	if got := result.Components[0]; got.Status != checker.StatusWaived || got.Notes != "Waived until 2026-06-30: renewed" {
+		t.Errorf("etcd = %s, %q; want waived by the renewed waiver", got.Status, got.Notes)
+	}
+
+	// Once both have expired, the first expired waiver is reported
+	result = testResult("prod")
+	Apply(result, waivers, time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC))
+	if got := result.Components[0]; got.Status != checker.StatusIncompatible || got.Notes != "Waiver expired on 2026-01-31: first extension" {
+		t.Errorf("etcd = %s, %q; want incompatible with an expired note", got.Status, got.Notes)
+	}
+}
+
+func TestExpired(t *testing.T) {
+	w := Waiver{Expires: "2026-11-30"}
+	tests := []struct {
+		now  time.Time
+		want bool
+	}{
+		{time.Date(2026, 11, 30, 23, 59, 0, 0, time.UTC), false},
+		{time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC), true},
+	}
+	for _, tt := range tests {
+		if got := w.Expired(tt.now); got != tt.want {
+			t.Errorf("Expired(%s) = %v, want %v", tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestVersionMatches(t *testing.T) {
+	tests := []struct {
+		pattern, current string
+		want             bool
+	}{
+		{"1.5.x", "1.5.18", true},
+		{"1.5.*", "1.5.18", true},
+		{"1.x", "1.5.18", true},
+		{"1.5.18", "v1.5.18", true},
+		{"1.5.18", "1.5.17", false},
+		{"1.5", "1.5.18", false},
+		{"1.6.x", "1.5.18", false},
+	}
+	for _, tt := range tests {
+		if got := versionMatches(tt.pattern, tt.current); got != tt.want {
+			t.Errorf("versionMatches(%q, %q) = %v, want %v", tt.pattern, tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, data := range []string{
+		"waivers:\n  - component: etcd\n    version: 3.4.x\n    expires: 2026-01-01\n",
+		"waivers:\n  - cluster: prod\n    component: etcd\n    version: 3.4.x\n    expires: next week\n",
+		"waivers: [",
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%q) expected error", data)
+		}
+	}
+}